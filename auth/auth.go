@@ -0,0 +1,201 @@
+package auth
+
+import (
+	// Default package
+	"fmt"
+	"sort"
+	"errors"
+	"strings"
+	"log/slog"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/labstack/echo/middleware"
+)
+
+// 라우트 인증 방식: 라우트를 등록할 때 반드시 하나를 정한다
+type Mode int
+
+const (
+	Public        Mode = iota // 인증하지 않음
+	Optional                  // 토큰이 있으면 검사하고 회원 정보를 담는다: 없으면 방문자로 처리
+	Authenticated             // 로그인한 회원
+	Staff                     // 스태프 또는 관리자
+	Admin                     // 관리자
+)
+
+func (m Mode) String() string {
+	switch m {
+	case Public:
+		return "public"
+	case Optional:
+		return "optional"
+	case Authenticated:
+		return "authenticated"
+	case Staff:
+		return "staff"
+	case Admin:
+		return "admin"
+	}
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// 라우트 표의 한 줄
+type Route struct {
+	Method      string
+	Path        string
+	Mode        Mode
+	TokenLookup string // 토큰을 읽는 곳: 비어 있으면 Authorization 헤더
+}
+
+// 라우트를 인증 방식과 함께 등록한다
+// 전역 JWT 미들웨어의 Skipper 목록 대신 라우트마다 인증 방식을 정하므로 빠뜨릴 수 없다
+type Router struct {
+	e           *echo.Echo
+	key         []byte
+	principals  *Cache
+	after       []echo.MiddlewareFunc
+	tokenLookup string
+	routes      *[]Route
+}
+
+func New(e *echo.Echo, key []byte, principals *Cache, after ...echo.MiddlewareFunc) *Router {
+	// principals: 토큰의 회원 ID 로 현재 상태를 읽는 캐시
+	// after: 인증을 마친 뒤 실행할 미들웨어 (예: 로그에 회원 ID 붙이기)
+	return &Router{e: e, key: key, principals: principals, after: after, routes: &[]Route{}}
+}
+
+func (r *Router) TokenLookup(lookup string) *Router {
+	// 토큰을 다른 곳에서 읽는 라우터: EventSource 처럼 헤더를 보낼 수 없는 경우 "query:token"
+	copied := *r
+	copied.tokenLookup = lookup
+	return &copied
+}
+
+func (r *Router) GET(path string, h echo.HandlerFunc, mode Mode, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Add(echo.GET, path, h, mode, m...)
+}
+
+func (r *Router) POST(path string, h echo.HandlerFunc, mode Mode, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Add(echo.POST, path, h, mode, m...)
+}
+
+func (r *Router) PATCH(path string, h echo.HandlerFunc, mode Mode, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Add(echo.PATCH, path, h, mode, m...)
+}
+
+func (r *Router) DELETE(path string, h echo.HandlerFunc, mode Mode, m ...echo.MiddlewareFunc) *echo.Route {
+	return r.Add(echo.DELETE, path, h, mode, m...)
+}
+
+func (r *Router) Add(method string, path string, h echo.HandlerFunc, mode Mode, m ...echo.MiddlewareFunc) *echo.Route {
+	// 인증 미들웨어, after, 라우트 미들웨어 순서로 실행한다
+	chain := append(r.Middleware(mode), r.after...)
+	chain = append(chain, m...)
+	*r.routes = append(*r.routes, Route{Method: method, Path: path, Mode: mode, TokenLookup: r.tokenLookup})
+	return r.e.Add(method, path, h, chain...)
+}
+
+func (r *Router) Static(prefix string, root string, m ...echo.MiddlewareFunc) {
+	// 공개 정적 파일
+	r.e.Group(prefix, m...).Static("/", root)
+	*r.routes = append(*r.routes, Route{Method: echo.GET, Path: prefix + "/*", Mode: Public})
+}
+
+func (r *Router) Middleware(mode Mode) []echo.MiddlewareFunc {
+	if mode == Public {
+		return nil
+	}
+
+	config := middleware.JWTConfig{SigningKey: r.key, Claims: &Claims{}}
+	if r.tokenLookup != "" {
+		config.TokenLookup = r.tokenLookup
+	}
+	if mode == Optional {
+		// 토큰을 보내지 않았을 때만 건너뛴다: 보낸 토큰이 올바르지 않으면 다른 라우트와 같이 거부한다
+		lookup := config.TokenLookup
+		config.Skipper = func(c echo.Context) bool {
+			return !hasToken(c, lookup)
+		}
+	}
+	chain := []echo.MiddlewareFunc{middleware.JWTWithConfig(config), loadPrincipal(r.principals)}
+
+	switch mode {
+	case Staff:
+		chain = append(chain, requireRole(func(p *Principal) bool { return p.IsStaff || p.IsAdmin }))
+	case Admin:
+		chain = append(chain, requireRole(func(p *Principal) bool { return p.IsAdmin }))
+	}
+	return chain
+}
+
+func hasToken(c echo.Context, lookup string) bool {
+	// lookup: "header:Authorization" 형식, 비어 있으면 Authorization 헤더
+	parts := strings.SplitN(lookup, ":", 2)
+	if len(parts) != 2 {
+		return c.Request().Header.Get(echo.HeaderAuthorization) != ""
+	}
+	switch parts[0] {
+	case "query":
+		return c.QueryParam(parts[1]) != ""
+	case "cookie":
+		_, err := c.Cookie(parts[1])
+		return err == nil
+	}
+	return c.Request().Header.Get(parts[1]) != ""
+}
+
+func (r *Router) Routes() []Route {
+	// 경로, 메소드 순으로 정렬한 라우트 표
+	routes := append([]Route(nil), *r.routes...)
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+func (r *Router) Log() {
+	// 서버 시작 시 라우트마다 인증 방식을 남긴다
+	for _, route := range r.Routes() {
+		attrs := []interface{}{"method", route.Method, "path", route.Path, "auth", route.Mode.String()}
+		if route.TokenLookup != "" {
+			attrs = append(attrs, "token_lookup", route.TokenLookup)
+		}
+		slog.Info("라우트", attrs...)
+	}
+}
+
+func (r *Router) Verify(routes []*echo.Route) error {
+	// Echo 에 직접 등록해 인증 방식이 정해지지 않은 라우트가 있는지 확인한다
+	declared := map[string]bool{}
+	for _, route := range *r.routes {
+		declared[route.Method+" "+route.Path] = true
+	}
+
+	registered := map[string]bool{}
+	for _, route := range routes {
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	var missing []string
+	for _, route := range routes {
+		// 미들웨어가 있는 그룹에 Echo 가 등록하는 404 라우트
+		if strings.HasPrefix(route.Name, "github.com/labstack/echo.(*Group).Use") {
+			continue
+		}
+		// Static 이 같은 핸들러로 함께 등록하는 디렉터리 경로 (/assets/ 와 /assets/*)
+		if strings.HasSuffix(route.Path, "/") && registered[route.Method+" "+route.Path+"*"] {
+			continue
+		}
+		if !declared[route.Method+" "+route.Path] {
+			missing = append(missing, route.Method+" "+route.Path)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.New("인증 방식을 정하지 않은 라우트가 있습니다:\n  " + strings.Join(missing, "\n  "))
+	}
+	return nil
+}
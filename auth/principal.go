@@ -0,0 +1,189 @@
+package auth
+
+import (
+	// Default package
+	"sync"
+	"time"
+	"errors"
+	"context"
+	"net/http"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/globalsign/mgo/bson"
+)
+
+// 로그인 토큰에 담는 값: 이미 발급한 토큰과 호환되도록 이름을 그대로 둔다
+type Claims struct {
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	Nickname string `json:"nickname"`
+	IsActive bool   `json:"isActive"`
+	IsStaff  bool   `json:"isStaff"`
+	IsAdmin  bool   `json:"isAdmin"`
+	jwt.StandardClaims
+}
+
+func (c *Claims) Valid() error {
+	// 만료 시각과 함께 회원 ID 가 있는지 확인한다: 실패하면 JWT 미들웨어가 401 을 돌려준다
+	if err := c.StandardClaims.Valid(); err != nil {
+		return err
+	}
+	if !bson.IsObjectIdHex(c.ID) {
+		return errors.New("토큰에 회원 ID 가 없습니다")
+	}
+	return nil
+}
+
+// 인증한 회원: 토큰이 아니라 데이터베이스의 현재 상태를 담는다
+type Principal struct {
+	ID       bson.ObjectId
+	Email    string
+	Nickname string
+	IsActive bool
+	IsBanned bool
+	IsStaff  bool
+	IsAdmin  bool
+}
+
+// 회원 ID 로 현재 상태를 읽는다: 회원이 없으면 ErrUnknownUser
+type Loader func(ctx context.Context, id bson.ObjectId) (*Principal, error)
+
+var ErrUnknownUser = errors.New("회원을 찾을 수 없습니다")
+
+// 회원 상태를 읽어 둔 뒤 다시 읽는 간격: 권한이나 정지 여부를 바꾼 서버는 바로 지우고, 다른 서버는 이 시간 안에 반영된다
+const CacheTTL = 30 * time.Second
+
+// 캐시에 담아 둘 최대 회원 수: 넘으면 만료된 항목부터 지운다
+const cacheSize = 10000
+
+type (
+	Cache struct {
+		load  Loader
+		ttl   time.Duration
+		mu    sync.Mutex
+		items map[bson.ObjectId]cacheItem
+	}
+
+	cacheItem struct {
+		principal *Principal
+		expires   time.Time
+	}
+)
+
+func NewCache(load Loader, ttl time.Duration) *Cache {
+	return &Cache{load: load, ttl: ttl, items: map[bson.ObjectId]cacheItem{}}
+}
+
+func (c *Cache) Get(ctx context.Context, id bson.ObjectId) (*Principal, error) {
+	now := time.Now()
+	c.mu.Lock()
+	item, ok := c.items[id]
+	c.mu.Unlock()
+	if ok && now.Before(item.expires) {
+		return item.principal, nil
+	}
+
+	p, err := c.load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	if len(c.items) >= cacheSize {
+		for key, item := range c.items {
+			if !now.Before(item.expires) {
+				delete(c.items, key)
+			}
+		}
+		if len(c.items) >= cacheSize {
+			c.items = map[bson.ObjectId]cacheItem{}
+		}
+	}
+	c.items[id] = cacheItem{principal: p, expires: now.Add(c.ttl)}
+	c.mu.Unlock()
+	return p, nil
+}
+
+func (c *Cache) Invalidate(id bson.ObjectId) {
+	// 회원 상태를 바꾼 뒤 호출한다: 다음 요청에서 다시 읽는다 (캐시가 없으면 아무것도 하지 않는다)
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	delete(c.items, id)
+	c.mu.Unlock()
+}
+
+// echo.Context 에 인증한 회원을 담는 키
+const principalKey = "principal"
+
+func CurrentUser(c echo.Context) *Principal {
+	// 인증한 회원: 공개 라우트나 토큰 없이 호출한 선택 인증 라우트에서는 nil
+	p, _ := c.Get(principalKey).(*Principal)
+	return p
+}
+
+func UserID(c echo.Context) string {
+	// 인증하지 않은 요청이면 빈 문자열: 로그에 회원 ID 를 남길 때 사용
+	if p := CurrentUser(c); p != nil {
+		return p.ID.Hex()
+	}
+	return ""
+}
+
+func loadPrincipal(cache *Cache) echo.MiddlewareFunc {
+	// JWT 미들웨어가 검사한 토큰으로 회원의 현재 상태를 읽어 담는다
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok {
+				// 선택 인증 라우트에 토큰 없이 온 방문자
+				return next(c)
+			}
+			claims, ok := token.Claims.(*Claims)
+			if !ok {
+				return echo.ErrUnauthorized
+			}
+
+			p, err := cache.Get(c.Request().Context(), bson.ObjectIdHex(claims.ID))
+			if err == ErrUnknownUser {
+				return &echo.HTTPError{
+					Code:    http.StatusUnauthorized,
+					Message: "탈퇴했거나 없는 회원입니다",
+				}
+			}
+			if err != nil {
+				return err
+			}
+			if p.IsBanned {
+				return &echo.HTTPError{
+					Code:    http.StatusForbidden,
+					Message: "이용이 정지된 회원입니다",
+				}
+			}
+			if !p.IsActive {
+				return &echo.HTTPError{
+					Code:    http.StatusForbidden,
+					Message: "이메일 인증을 마치지 않은 회원입니다",
+				}
+			}
+
+			c.Set(principalKey, p)
+			return next(c)
+		}
+	}
+}
+
+func requireRole(allowed func(p *Principal) bool) echo.MiddlewareFunc {
+	// 토큰이 아니라 현재 권한으로 확인하므로 권한을 바꾸면 바로 적용된다
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			p := CurrentUser(c)
+			if p == nil || !allowed(p) {
+				return echo.ErrUnauthorized
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	// Default package
+	"os"
+	"fmt"
+	"flag"
+	"bufio"
+	"context"
+	"strings"
+	// Third Party package
+	"github.com/labstack/gommon/log"
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/config"
+	"github.com/backend/model"
+	"github.com/backend/handler"
+	"github.com/backend/repository"
+)
+
+// 사용법:
+// go run ./cmd/admin -email admin@example.com -nickname 관리자
+// 관리자 회원 가입(POST /admin/)은 관리자만 할 수 있으므로, 새로 배포한 서버의 첫 관리자는 이 명령으로 만든다
+// 이미 가입한 회원이면 활성화하고 관리자, 필진 권한을 준다: 패스워드는 바꾸지 않는다
+// 새로 만드는 경우 패스워드는 STMORE_ADMIN_PASSWORD 환경 변수나 표준 입력의 첫 줄에서 읽는다
+// 데이터베이스는 서버와 같은 설정 파일(-config, 기본값 ./config.json)을 사용한다
+func main() {
+	email := flag.String("email", "", "관리자로 만들 회원의 이메일")
+	nickname := flag.String("nickname", "", "새로 만드는 경우의 닉네임")
+	configPath := flag.String("config", os.Getenv(config.EnvPrefix+"CONFIG"), "서버 설정 파일 경로 (기본값: ./config.json)")
+	flag.Parse()
+
+	if *email == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *configPath == "" {
+		*configPath = "./config.json"
+	}
+
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	handler.DBName = cfg.Mongo.Database
+
+	db := dial(cfg)
+	defer db.Close()
+
+	ctx := context.Background()
+	h := &handler.Handler{Repositories: repository.NewMongo(db, handler.DBName)}
+
+	// 이미 가입한 회원은 권한만 준다
+	u, err := h.Users.GetByEmail(ctx, *email)
+	switch err {
+	case nil:
+		u.IsActive, u.IsStaff, u.IsAdmin = true, true, true
+		if err = h.Users.Update(ctx, u, "is_active", "is_staff", "is_admin"); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s 회원에게 관리자 권한을 주었습니다\n", u.Email)
+		return
+	case repository.ErrNotFound:
+	default:
+		log.Fatal(err)
+	}
+
+	// 새 관리자 만들기
+	if *nickname == "" {
+		log.Fatal("새 관리자를 만들려면 -nickname 이 필요합니다")
+	}
+	password, err := readPassword()
+	if err != nil {
+		log.Fatal(err)
+	}
+	u = &model.User{
+		ID:       bson.NewObjectId(),
+		Email:    *email,
+		Nickname: *nickname,
+		Password: password,
+		IsActive: true,
+		IsStaff:  true,
+		IsAdmin:  true,
+	}
+	if err = h.CreateUser(ctx, u); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("관리자 %s 를 만들었습니다\n", u.Email)
+}
+
+func readPassword() (string, error) {
+	// 명령행 인자는 다른 사용자가 볼 수 있으므로 패스워드를 받지 않는다
+	if password := os.Getenv(config.EnvPrefix + "ADMIN_PASSWORD"); password != "" {
+		return password, nil
+	}
+	fmt.Fprint(os.Stderr, "패스워드: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("패스워드를 읽지 못했습니다: %v", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func dial(cfg *config.Config) *mgo.Session {
+	// Database connection
+	info, err := cfg.Mongo.DialInfo()
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := mgo.DialWithInfo(info)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return db
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	// Default package
+	"os"
+	"flag"
+	"context"
+	"encoding/json"
+	// Third Party package
+	"github.com/labstack/gommon/log"
+	"github.com/globalsign/mgo"
+	// User package
+	"github.com/backend/config"
+	"github.com/backend/handler"
+	"github.com/backend/importer"
+	"github.com/backend/storage"
+	"github.com/backend/repository"
+)
+
+// 사용법:
+// go run ./cmd/importer -file blog.zip -author writer@example.com
+// 데이터베이스와 이미지 저장소는 서버와 같은 설정 파일(-config, 기본값 ./config.json)을 사용한다
+// -commit 옵션을 주지 않으면 저장하지 않고 리포트만 출력한다
+func main() {
+	filePath := flag.String("file", "", "가져올 파일 경로 (.zip 또는 .xml)")
+	format := flag.String("format", "", "파일 형식: markdown 또는 wordpress (기본값: 확장자로 추측)")
+	authorEmail := flag.String("author", "", "글을 가져올 필진의 이메일")
+	commit := flag.Bool("commit", false, "리포트 확인 후 실제로 저장")
+	configPath := flag.String("config", os.Getenv(config.EnvPrefix+"CONFIG"), "서버 설정 파일 경로 (기본값: ./config.json)")
+	flag.Parse()
+
+	if *filePath == "" || *authorEmail == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *configPath == "" {
+		*configPath = "./config.json"
+	}
+	if *format == "" {
+		*format = importer.DetectFormat(*filePath)
+	}
+
+	// Read import file
+	f, err := os.Open(*filePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	stat, err := f.Stat()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	entries, err := importer.Parse(*format, f, stat.Size())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	report := &importer.Report{
+		Format:  *format,
+		DryRun:  !*commit,
+		Total:   len(entries),
+		Entries: entries,
+	}
+
+	if *commit {
+		cfg, err := config.Load(*configPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		handler.DBName = cfg.Mongo.Database
+
+		db := dial(cfg)
+		defer db.Close()
+
+		// Find author
+		repositories := repository.NewMongo(db, handler.DBName)
+		u, err := repositories.Users.GetByEmail(context.Background(), *authorEmail)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		// 업로드한 파일 저장소
+		store, err := storage.New(cfg.Storage)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		h := &handler.Handler{Repositories: repositories, Storage: store}
+		if err = h.ImportEntries(context.Background(), u.ID, report); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// 리포트 출력
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+}
+
+func dial(cfg *config.Config) *mgo.Session {
+	// Database connection
+	info, err := cfg.Mongo.DialInfo()
+	if err != nil {
+		log.Fatal(err)
+	}
+	db, err := mgo.DialWithInfo(info)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return db
+}
@@ -0,0 +1,300 @@
+package config
+
+import (
+	// Default package
+	"os"
+	"time"
+	"strconv"
+	"strings"
+	"io/ioutil"
+	"encoding/json"
+	// Third Party package
+	"github.com/globalsign/mgo"
+	// User package
+	"github.com/backend/logging"
+	"github.com/backend/storage"
+	"github.com/backend/tracing"
+	"github.com/backend/utility"
+)
+
+// 실행 환경
+const (
+	Development = "development"
+	Staging     = "staging"
+	Production  = "production"
+)
+
+// 환경 변수 이름의 접두어: STMORE_MONGO_URI 처럼 쓴다
+const EnvPrefix = "STMORE_"
+
+type (
+	// 서버 설정: 기본값 → 설정 파일 → 환경 변수 순서로 덮어쓴다
+	Config struct {
+		Env     string          `json:"env"`     // development(기본값), staging, production
+		HTTP    HTTP            `json:"http"`    // API 서버
+		Log     Log             `json:"log"`     // 로그
+		Metrics Metrics         `json:"metrics"` // Prometheus 지표
+		Tracing tracing.Config  `json:"tracing"` // OpenTelemetry 추적
+		Mongo   Mongo           `json:"mongo"`   // 데이터베이스
+		CORS    CORS            `json:"cors"`    // 요청을 허용할 프론트엔드 주소
+		JWT     JWT             `json:"jwt"`     // 로그인 토큰
+		Mail    utility.Account `json:"mail"`    // 메일 발송
+		Storage storage.Config  `json:"storage"` // 업로드한 파일 저장소
+	}
+
+	HTTP struct {
+		Addr            string   `json:"addr"`             // 서버 주소 (예: :1323)
+		SiteURL         string   `json:"site_url"`         // 프론트엔드 주소: 메일 링크와 리다이렉트에 사용
+		ShutdownTimeout Duration `json:"shutdown_timeout"` // 종료 신호를 받은 뒤 요청과 백그라운드 작업을 기다리는 시간
+	}
+
+	Log struct {
+		Level  string `json:"level"`  // debug, info(기본값), warn, error
+		Format string `json:"format"` // json(기본값), text
+	}
+
+	Metrics struct {
+		Enabled bool   `json:"enabled"` // /metrics 제공 여부 (기본값: true)
+		Addr    string `json:"addr"`    // 관리용 포트 (예: 127.0.0.1:9090): 비우면 API 서버에서 제공한다
+		Token   string `json:"token"`   // API 서버에서 제공할 때 요구하는 Bearer 토큰
+	}
+
+	Mongo struct {
+		URI        string   `json:"uri"`         // 접속 주소 (예: mongodb://db1,db2/st_more?replicaSet=rs0)
+		Database   string   `json:"database"`    // 데이터베이스 이름
+		ReplicaSet string   `json:"replica_set"` // 레플리카 셋 이름: URI 에 있으면 비워 둔다
+		Username   string   `json:"username"`    // 계정: URI 에 있으면 비워 둔다
+		Password   string   `json:"password"`    // 패스워드
+		Timeout    Duration `json:"timeout"`     // 접속 제한 시간 (예: 60s)
+	}
+
+	CORS struct {
+		AllowOrigins []string `json:"allow_origins"` // 허용할 출처 목록
+	}
+
+	JWT struct {
+		Key    string   `json:"key"`    // 서명 키
+		Expiry Duration `json:"expiry"` // 토큰 유효시간 (예: 72h)
+	}
+
+	// JSON 에서 "72h" 처럼 쓰는 시간 간격
+	Duration struct {
+		time.Duration
+	}
+)
+
+func Default() *Config {
+	// 설정하지 않은 항목의 기본값: 로컬 개발 환경 기준
+	return &Config{
+		Env: Development,
+		HTTP: HTTP{
+			Addr:            ":1323",
+			SiteURL:         "https://www.somethingmore.co.kr",
+			ShutdownTimeout: Duration{30 * time.Second},
+		},
+		Log: Log{
+			Level:  "info",
+			Format: logging.FormatJSON,
+		},
+		Metrics: Metrics{
+			Enabled: true,
+		},
+		Tracing: tracing.Config{
+			Endpoint:    "http://localhost:4318/v1/traces",
+			SampleRatio: 1,
+			ServiceName: "stmore-api",
+		},
+		Mongo: Mongo{
+			URI:      "mongodb://localhost",
+			Database: "st_more",
+			Timeout:  Duration{60 * time.Second},
+		},
+		JWT: JWT{
+			Key:    "secret",
+			Expiry: Duration{72 * time.Hour},
+		},
+		Storage: storage.Config{
+			Backend: "local",
+		},
+	}
+}
+
+func Load(path string) (cfg *Config, err error) {
+	// 설정 파일이 없으면 기본값과 환경 변수만 사용한다
+	cfg = Default()
+	if path != "" {
+		byteValue, err := ioutil.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		if err == nil {
+			if err = json.Unmarshal(byteValue, cfg); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err = cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	cfg.fill()
+
+	if err = cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (cfg *Config) IsProduction() bool {
+	return cfg.Env == Production
+}
+
+func (cfg *Config) applyEnv() (err error) {
+	// 설정 파일보다 환경 변수가 우선한다: 비밀 값은 파일 대신 환경 변수로 넣을 수 있다
+	strs := map[string]*string{
+		"ENV":                  &cfg.Env,
+		"HTTP_ADDR":            &cfg.HTTP.Addr,
+		"SITE_URL":             &cfg.HTTP.SiteURL,
+		"LOG_LEVEL":            &cfg.Log.Level,
+		"LOG_FORMAT":           &cfg.Log.Format,
+		"METRICS_ADDR":         &cfg.Metrics.Addr,
+		"METRICS_TOKEN":        &cfg.Metrics.Token,
+		"TRACING_ENDPOINT":     &cfg.Tracing.Endpoint,
+		"TRACING_SERVICE_NAME": &cfg.Tracing.ServiceName,
+		"MONGO_URI":            &cfg.Mongo.URI,
+		"MONGO_DATABASE":       &cfg.Mongo.Database,
+		"MONGO_REPLICA_SET":    &cfg.Mongo.ReplicaSet,
+		"MONGO_USERNAME":       &cfg.Mongo.Username,
+		"MONGO_PASSWORD":       &cfg.Mongo.Password,
+		"JWT_KEY":              &cfg.JWT.Key,
+		"MAIL_EMAIL":           &cfg.Mail.Email,
+		"MAIL_PASSWORD":        &cfg.Mail.Password,
+		"MAIL_HOST":            &cfg.Mail.Host,
+		"MAIL_SECURITY":        &cfg.Mail.Security,
+		"MAIL_BACKEND":         &cfg.Mail.Backend,
+		"MAIL_DIR":             &cfg.Mail.Dir,
+		"STORAGE_BACKEND":      &cfg.Storage.Backend,
+		"STORAGE_PUBLIC_URL":   &cfg.Storage.PublicURL,
+		"STORAGE_DIR":          &cfg.Storage.Dir,
+		"STORAGE_PRIVATE_DIR":  &cfg.Storage.PrivateDir,
+		"STORAGE_PRIVATE_URL":  &cfg.Storage.PrivateURL,
+		"STORAGE_SIGNING_KEY":  &cfg.Storage.SigningKey,
+		"STORAGE_ENDPOINT":     &cfg.Storage.Endpoint,
+		"STORAGE_REGION":       &cfg.Storage.Region,
+		"STORAGE_BUCKET":       &cfg.Storage.Bucket,
+		"STORAGE_ACCESS_KEY":   &cfg.Storage.AccessKey,
+		"STORAGE_SECRET_KEY":   &cfg.Storage.SecretKey,
+	}
+	for name, p := range strs {
+		if v, ok := os.LookupEnv(EnvPrefix + name); ok {
+			*p = v
+		}
+	}
+
+	durations := map[string]*Duration{
+		"HTTP_SHUTDOWN_TIMEOUT": &cfg.HTTP.ShutdownTimeout,
+		"MONGO_TIMEOUT":         &cfg.Mongo.Timeout,
+		"JWT_EXPIRY":            &cfg.JWT.Expiry,
+	}
+	for name, p := range durations {
+		if v, ok := os.LookupEnv(EnvPrefix + name); ok {
+			if p.Duration, err = time.ParseDuration(v); err != nil {
+				return envError(name, err)
+			}
+		}
+	}
+
+	if v, ok := os.LookupEnv(EnvPrefix + "METRICS_ENABLED"); ok {
+		if cfg.Metrics.Enabled, err = strconv.ParseBool(v); err != nil {
+			return envError("METRICS_ENABLED", err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvPrefix + "TRACING_ENABLED"); ok {
+		if cfg.Tracing.Enabled, err = strconv.ParseBool(v); err != nil {
+			return envError("TRACING_ENABLED", err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvPrefix + "TRACING_SAMPLE_RATIO"); ok {
+		if cfg.Tracing.SampleRatio, err = strconv.ParseFloat(v, 64); err != nil {
+			return envError("TRACING_SAMPLE_RATIO", err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvPrefix + "MAIL_PORT"); ok {
+		if cfg.Mail.Port, err = strconv.Atoi(v); err != nil {
+			return envError("MAIL_PORT", err)
+		}
+	}
+	if v, ok := os.LookupEnv(EnvPrefix + "STORAGE_USE_SSL"); ok {
+		if cfg.Storage.UseSSL, err = strconv.ParseBool(v); err != nil {
+			return envError("STORAGE_USE_SSL", err)
+		}
+	}
+	// 쉼표로 구분한 목록
+	if v, ok := os.LookupEnv(EnvPrefix + "CORS_ALLOW_ORIGINS"); ok {
+		cfg.CORS.AllowOrigins = splitList(v)
+	}
+	if v, ok := os.LookupEnv(EnvPrefix + "STORAGE_LEGACY_URLS"); ok {
+		cfg.Storage.LegacyURLs = splitList(v)
+	}
+	return
+}
+
+func (cfg *Config) fill() {
+	// 다른 항목에서 값을 가져오는 기본값
+	if len(cfg.CORS.AllowOrigins) == 0 {
+		cfg.CORS.AllowOrigins = []string{cfg.HTTP.SiteURL}
+	}
+	// 운영 환경에서는 JWT 와 다른 서명 키를 따로 정해야 한다: Validate 가 확인한다
+	if cfg.Storage.SigningKey == "" && !cfg.IsProduction() {
+		cfg.Storage.SigningKey = cfg.JWT.Key
+	}
+	// 개발 환경에서 메일 서버를 정하지 않았다면 파일로 저장한다
+	if cfg.Env == Development && cfg.Mail.Backend == "" && cfg.Mail.Host == "" {
+		cfg.Mail.Backend = "file"
+		if cfg.Mail.Dir == "" {
+			cfg.Mail.Dir = "./mail"
+		}
+	}
+}
+
+func (m Mongo) DialInfo() (info *mgo.DialInfo, err error) {
+	// URI 를 해석한 뒤 따로 적은 항목으로 덮어쓴다
+	if info, err = mgo.ParseURL(m.URI); err != nil {
+		return
+	}
+	if m.ReplicaSet != "" {
+		info.ReplicaSetName = m.ReplicaSet
+	}
+	if m.Username != "" {
+		info.Username = m.Username
+		info.Password = m.Password
+	}
+	// 인증 데이터베이스: URI 에 없으면 사용할 데이터베이스
+	if info.Database == "" {
+		info.Database = m.Database
+	}
+	info.Timeout = m.Timeout.Duration
+	return
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) (err error) {
+	var s string
+	if err = json.Unmarshal(b, &s); err != nil {
+		return
+	}
+	d.Duration, err = time.ParseDuration(s)
+	return
+}
+
+func splitList(v string) (list []string) {
+	for _, s := range strings.Split(v, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			list = append(list, s)
+		}
+	}
+	return
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	// Default package
+	"fmt"
+	"errors"
+	"strings"
+	"net/url"
+	// Third Party package
+	"github.com/globalsign/mgo"
+	// User package
+	"github.com/backend/logging"
+	"github.com/backend/utility"
+)
+
+// 운영 환경에서 쓰는 JWT 서명 키의 최소 길이
+const MinKeyLength = 32
+
+func (cfg *Config) Validate() error {
+	// 잘못된 설정은 서버 시작 시 한꺼번에 알려준다
+	var problems []string
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(cfg.Env == Development || cfg.Env == Staging || cfg.Env == Production,
+		"env: development, staging, production 중 하나여야 합니다: %q", cfg.Env)
+
+	// HTTP
+	check(cfg.HTTP.Addr != "", "http.addr: 서버 주소가 비어 있습니다")
+	check(isAbsoluteURL(cfg.HTTP.SiteURL), "http.site_url: 올바른 주소가 아닙니다: %q", cfg.HTTP.SiteURL)
+	check(cfg.HTTP.ShutdownTimeout.Duration > 0, "http.shutdown_timeout: 0 보다 커야 합니다")
+
+	// Log
+	_, err := logging.ParseLevel(cfg.Log.Level)
+	check(err == nil, "log.level: debug, info, warn, error 중 하나여야 합니다: %q", cfg.Log.Level)
+	check(cfg.Log.Format == logging.FormatJSON || cfg.Log.Format == logging.FormatText,
+		"log.format: json, text 중 하나여야 합니다: %q", cfg.Log.Format)
+
+	// Metrics
+	// API 서버에서 제공하는 지표는 누구나 볼 수 있으므로 운영 환경에서는 토큰이나 관리용 포트를 써야 한다
+	if cfg.Metrics.Enabled {
+		check(cfg.Metrics.Addr != cfg.HTTP.Addr, "metrics.addr: API 서버와 다른 주소여야 합니다: %q", cfg.Metrics.Addr)
+		if cfg.Metrics.Addr == "" && cfg.IsProduction() {
+			check(len(cfg.Metrics.Token) >= MinKeyLength,
+				"metrics.token: 운영 환경에서 API 서버로 지표를 제공하려면 %d자 이상의 토큰이나 metrics.addr 를 설정해야 합니다", MinKeyLength)
+		}
+	}
+
+	// Tracing
+	if cfg.Tracing.Enabled {
+		check(isAbsoluteURL(cfg.Tracing.Endpoint), "tracing.endpoint: 올바른 수집기 주소가 아닙니다: %q", cfg.Tracing.Endpoint)
+		check(cfg.Tracing.ServiceName != "", "tracing.service_name: 서비스 이름이 비어 있습니다")
+	}
+	check(cfg.Tracing.SampleRatio >= 0 && cfg.Tracing.SampleRatio <= 1,
+		"tracing.sample_ratio: 0 과 1 사이여야 합니다: %v", cfg.Tracing.SampleRatio)
+
+	// Mongo
+	_, err = mgo.ParseURL(cfg.Mongo.URI)
+	check(err == nil, "mongo.uri: 올바른 접속 주소가 아닙니다: %v", err)
+	check(cfg.Mongo.Database != "", "mongo.database: 데이터베이스 이름이 비어 있습니다")
+	check(cfg.Mongo.Timeout.Duration > 0, "mongo.timeout: 0 보다 커야 합니다")
+
+	// CORS
+	for _, origin := range cfg.CORS.AllowOrigins {
+		if origin == "*" {
+			check(!cfg.IsProduction(), "cors.allow_origins: 운영 환경에서는 * 를 쓸 수 없습니다")
+			continue
+		}
+		check(isOrigin(origin), "cors.allow_origins: 올바른 출처가 아닙니다: %q", origin)
+	}
+
+	// JWT
+	check(cfg.JWT.Key != "", "jwt.key: 서명 키가 비어 있습니다")
+	if cfg.IsProduction() {
+		check(cfg.JWT.Key != Default().JWT.Key && len(cfg.JWT.Key) >= MinKeyLength,
+			"jwt.key: 운영 환경에서는 기본값이 아닌 %d자 이상의 키를 써야 합니다", MinKeyLength)
+	}
+	check(cfg.JWT.Expiry.Duration > 0, "jwt.expiry: 0 보다 커야 합니다")
+
+	// Mail
+	switch cfg.Mail.Backend {
+	case "", "smtp":
+		check(cfg.Mail.Host != "", "mail.host: SMTP 서버가 비어 있습니다")
+		check(cfg.Mail.Email != "", "mail.email: 발신자가 비어 있습니다")
+		check(cfg.Mail.Port >= 0 && cfg.Mail.Port < 65536, "mail.port: 올바른 포트가 아닙니다: %d", cfg.Mail.Port)
+		switch cfg.Mail.Security {
+		case "", utility.SecurityStartTLS, utility.SecurityTLS:
+		case utility.SecurityNone:
+			check(!cfg.IsProduction(), "mail.security: 운영 환경에서는 none 을 쓸 수 없습니다")
+		default:
+			check(false, "mail.security: starttls, tls, none 중 하나여야 합니다: %q", cfg.Mail.Security)
+		}
+	case "file":
+		check(cfg.Mail.Dir != "", "mail.dir: 메일을 저장할 경로가 비어 있습니다")
+	case "memory":
+		check(!cfg.IsProduction(), "mail.backend: 운영 환경에서는 memory 를 쓸 수 없습니다")
+	default:
+		check(false, "mail.backend: smtp, file, memory 중 하나여야 합니다: %q", cfg.Mail.Backend)
+	}
+
+	// Storage
+	// 주소를 정하지 않으면 localhost 주소를 쓰므로 운영 환경에서는 반드시 정해야 한다
+	if cfg.Storage.PublicURL != "" {
+		check(isAbsoluteURL(cfg.Storage.PublicURL), "storage.public_url: 올바른 주소가 아닙니다: %q", cfg.Storage.PublicURL)
+	} else {
+		check(!cfg.IsProduction(), "storage.public_url: 운영 환경에서는 파일 주소를 설정해야 합니다")
+	}
+	for _, legacy := range cfg.Storage.LegacyURLs {
+		check(isAbsoluteURL(legacy), "storage.legacy_urls: 올바른 주소가 아닙니다: %q", legacy)
+	}
+	switch cfg.Storage.Backend {
+	case "", "local":
+		if cfg.Storage.PrivateURL != "" {
+			check(isAbsoluteURL(cfg.Storage.PrivateURL), "storage.private_url: 올바른 주소가 아닙니다: %q", cfg.Storage.PrivateURL)
+		} else {
+			check(!cfg.IsProduction(), "storage.private_url: 운영 환경에서는 비공개 파일 주소를 설정해야 합니다")
+		}
+		if cfg.IsProduction() {
+			check(cfg.Storage.SigningKey != cfg.JWT.Key && len(cfg.Storage.SigningKey) >= MinKeyLength,
+				"storage.signing_key: 운영 환경에서는 jwt.key 와 다른 %d자 이상의 키를 써야 합니다", MinKeyLength)
+		}
+	case "s3":
+		check(cfg.Storage.Endpoint != "", "storage.endpoint: S3 엔드포인트가 비어 있습니다")
+		check(cfg.Storage.Bucket != "", "storage.bucket: 버킷이 비어 있습니다")
+		check(cfg.Storage.AccessKey != "" && cfg.Storage.SecretKey != "", "storage.access_key, storage.secret_key: 인증 정보가 비어 있습니다")
+	default:
+		check(false, "storage.backend: local, s3 중 하나여야 합니다: %q", cfg.Storage.Backend)
+	}
+
+	if len(problems) > 0 {
+		return errors.New("설정이 올바르지 않습니다:\n  " + strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func envError(name string, err error) error {
+	return fmt.Errorf("환경 변수 %s%s: %v", EnvPrefix, name, err)
+}
+
+func isAbsoluteURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func isOrigin(s string) bool {
+	// 출처는 경로 없이 scheme://host[:port] 만 쓴다
+	u, err := url.Parse(s)
+	return err == nil && isAbsoluteURL(s) && (u.Path == "" || u.Path == "/") && u.RawQuery == ""
+}
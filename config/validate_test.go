@@ -0,0 +1,58 @@
+package config
+
+import (
+	// Default package
+	"strings"
+	"testing"
+)
+
+func setProductionEnv(t *testing.T) {
+	// 파일 저장소를 뺀 운영 환경 설정
+	t.Setenv("STMORE_ENV", Production)
+	t.Setenv("STMORE_JWT_KEY", strings.Repeat("j", MinKeyLength))
+	t.Setenv("STMORE_METRICS_ADDR", "127.0.0.1:9090")
+	t.Setenv("STMORE_MAIL_HOST", "smtp.example.com")
+	t.Setenv("STMORE_MAIL_EMAIL", "noreply@example.com")
+}
+
+func TestProductionStorage(t *testing.T) {
+	setProductionEnv(t)
+	_, err := Load("")
+	if err == nil {
+		t.Fatal("파일 주소 없이 운영 환경 설정을 읽었습니다")
+	}
+	for _, field := range []string{"storage.public_url", "storage.private_url", "storage.signing_key"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("%s 를 확인하지 않았습니다: %v", field, err)
+		}
+	}
+
+	// JWT 키를 서명 키로 쓸 수 없다
+	t.Setenv("STMORE_STORAGE_PUBLIC_URL", "https://cdn.example.com")
+	t.Setenv("STMORE_STORAGE_PRIVATE_URL", "https://api.example.com/private")
+	t.Setenv("STMORE_STORAGE_SIGNING_KEY", strings.Repeat("j", MinKeyLength))
+	if _, err = Load(""); err == nil || !strings.Contains(err.Error(), "storage.signing_key") {
+		t.Errorf("JWT 키와 같은 서명 키를 받아들였습니다: %v", err)
+	}
+
+	t.Setenv("STMORE_STORAGE_SIGNING_KEY", strings.Repeat("s", MinKeyLength))
+	t.Setenv("STMORE_STORAGE_PRIVATE_DIR", "/srv/private")
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Storage.PrivateDir != "/srv/private" || cfg.Storage.PrivateURL != "https://api.example.com/private" || cfg.Storage.SigningKey != strings.Repeat("s", MinKeyLength) {
+		t.Errorf("환경 변수를 읽지 않았습니다: %+v", cfg.Storage)
+	}
+}
+
+func TestDevelopmentSigningKey(t *testing.T) {
+	// 개발 환경에서는 서명 키를 비워 두면 JWT 키를 쓴다
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Storage.SigningKey != cfg.JWT.Key {
+		t.Errorf("storage.signing_key = %q, want %q", cfg.Storage.SigningKey, cfg.JWT.Key)
+	}
+}
@@ -2,37 +2,21 @@ package handler
 
 import (
 	// Default package
+	"strconv"
 	"net/http"
+	"log/slog"
 	// Third Party package
 	"github.com/labstack/echo"
-	"github.com/globalsign/mgo/bson"
 	// User package
+	"github.com/backend/auth"
 	"github.com/backend/model"
-	"github.com/backend/utility"
+	"github.com/backend/repository"
 )
 
 func (h *Handler) ListUsers(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
-		return
-	}
-
-	// Validate Admin
-	if err = utility.AdminValidation(c); err != nil {
-		return
-	}
-
 	// Find users
-	var users []*model.User
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		Find(nil).
-		Select(bson.M{"password": 0}).
-		Sort("-is_admin").
-		Sort("-is_staff").
-		All(&users); err != nil {
+	users, err := h.Users.List(c.Request().Context(), repository.UserQuery{})
+	if err != nil {
 		return
 	}
 
@@ -40,17 +24,6 @@ func (h *Handler) ListUsers(c echo.Context) (err error) {
 }
 
 func (h *Handler) UpdateUserAuth(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
-		return
-	}
-
-	// Validate Admin
-	if err = utility.AdminValidation(c); err != nil {
-		return
-	}
-
 	// Bind object
 	u := new(model.User)
 	if err = c.Bind(u); err != nil {
@@ -60,42 +33,86 @@ func (h *Handler) UpdateUserAuth(c echo.Context) (err error) {
 	userEmail := c.Param("user_email")
 
 	// Update user authentication
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		Update(
-		bson.M{"email": userEmail},
-		bson.M{"$set":
-		bson.M{
-			"is_admin": u.IsAdmin,
-			"is_staff": u.IsStaff}}); err != nil {
+	target, err := h.Users.GetByEmail(c.Request().Context(), userEmail)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
 		return
 	}
+	target.IsAdmin, target.IsStaff = u.IsAdmin, u.IsStaff
+	if err = h.Users.Update(c.Request().Context(), target, "is_admin", "is_staff"); err != nil {
+		return
+	}
+	h.Principals.Invalidate(target.ID)
+
+	// 권한이 바뀐 회원에게 알림
+	role := "일반 회원"
+	if u.IsAdmin {
+		role = "관리자"
+	} else if u.IsStaff {
+		role = "필진"
+	}
+	if err := h.Notify(c.Request().Context(), target.ID, model.NotificationRoleChanged, "회원 권한이 "+role+"(으)로 변경되었습니다", ""); err != nil {
+		slog.ErrorContext(c.Request().Context(), "알림을 저장하지 못했습니다", "target_id", target.ID.Hex(), "error", err)
+	}
 
 	return c.NoContent(http.StatusOK)
 }
 
 func (h *Handler) ForceDestroyUser(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
+	userEmail := c.Param("user_email")
+
+	// Force Destroy user authentication
+	u, err := h.Users.GetByEmail(c.Request().Context(), userEmail)
+	if err == nil {
+		err = h.Users.Delete(c.Request().Context(), u.ID)
+	}
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
 		return
 	}
 
-	// Validate Admin
-	if err = utility.AdminValidation(c); err != nil {
-		return
+	h.Principals.Invalidate(u.ID)
+
+	// 회원의 미디어 라이브러리 정리
+	h.releaseUserMedia(c, u.ID)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) BanUser(c echo.Context) (err error) {
+	// 이용 정지 여부 변경: 정지한 회원은 로그인할 수 없고, 이미 발급받은 토큰도 거부된다
+	banned, err := strconv.ParseBool(c.FormValue("is_banned"))
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "정지 여부는 true 나 false 로 입력해야 합니다",
+		}
 	}
 
 	userEmail := c.Param("user_email")
+	if userEmail == auth.CurrentUser(c).Email {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "자기 자신은 정지할 수 없습니다",
+		}
+	}
 
-	// Force Destroy user authentication
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		Remove(bson.M{"email": userEmail}); err != nil {
+	u, err := h.Users.GetByEmail(c.Request().Context(), userEmail)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
 		return
 	}
+	u.IsBanned = banned
+	if err = h.Users.Update(c.Request().Context(), u, "is_banned"); err != nil {
+		return
+	}
+	h.Principals.Invalidate(u.ID)
 
-	return c.NoContent(http.StatusNoContent)
+	return c.NoContent(http.StatusOK)
 }
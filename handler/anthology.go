@@ -0,0 +1,319 @@
+package handler
+
+import (
+	// Default package
+	"bytes"
+	"context"
+	"strconv"
+	"net/http"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/repository"
+	"github.com/backend/utility"
+)
+
+func (h *Handler) CreateAnthology(c echo.Context) (err error) {
+	// Bind anthology object
+	// 섹션 구조를 담아야 하므로 JSON 으로 받는다
+	a := &model.Anthology{ID: bson.NewObjectId()}
+	if err = c.Bind(a); err != nil {
+		return
+	}
+
+	// Validation
+	if err = h.validateAnthology(c.Request().Context(), a); err != nil {
+		return
+	}
+	a.DateModified = ""
+
+	// Save anthology
+	if err = h.Anthologies.Create(c.Request().Context(), a); err != nil {
+		return
+	}
+
+	return c.JSON(http.StatusCreated, a)
+}
+
+func (h *Handler) ListAnthology(c echo.Context) (err error) {
+	// List anthologies from database
+	// 서문과 판권면은 받아오지 않음
+	anthologies, err := h.Anthologies.List(c.Request().Context())
+	if err != nil {
+		return
+	}
+
+	return c.JSON(http.StatusOK, anthologies)
+}
+
+func (h *Handler) RetrieveAnthology(c echo.Context) (err error) {
+	// Find anthology in database
+	a := new(model.Anthology)
+	if err = h.FindAnthology(c, a); err != nil {
+		return
+	}
+
+	return c.JSON(http.StatusOK, a)
+}
+
+func (h *Handler) PatchAnthology(c echo.Context) (err error) {
+	// Find anthology in database
+	a := new(model.Anthology)
+	if err = h.FindAnthology(c, a); err != nil {
+		return
+	}
+
+	// 기존 값 위에 요청 값을 덮어씌운다
+	id := a.ID
+	if err = c.Bind(a); err != nil {
+		return
+	}
+	a.ID = id
+
+	// Validation
+	if err = h.validateAnthology(c.Request().Context(), a); err != nil {
+		return
+	}
+
+	// Update anthology in database
+	if err = h.Anthologies.Update(c.Request().Context(), a, "title", "year", "preface", "colophon", "sections", "date_modified"); err != nil {
+		return
+	}
+
+	return c.JSON(http.StatusOK, a)
+}
+
+func (h *Handler) DestroyAnthology(c echo.Context) (err error) {
+	// Find anthology in database
+	a := new(model.Anthology)
+	if err = h.FindAnthology(c, a); err != nil {
+		return
+	}
+
+	// Destroy anthology in database
+	if err = h.Anthologies.Delete(c.Request().Context(), a.ID); err != nil {
+		return
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *Handler) ExportAnthologyPDF(c echo.Context) (err error) {
+	// Find anthology in database
+	a := new(model.Anthology)
+	if err = h.FindAnthology(c, a); err != nil {
+		return
+	}
+
+	// 수록작 불러오기
+	stories, err := h.anthologyStories(c, a)
+	if err != nil {
+		return
+	}
+
+	book := &utility.PdfBook{
+		Title:    a.Title,
+		Subtitle: strconv.Itoa(a.Year),
+		FontPath: utility.FontPath,
+	}
+
+	// 서문 → 섹션별 수록작 → 판권면 순서로 구성
+	if a.Preface != "" {
+		book.Chapters = append(book.Chapters, utility.PdfChapter{
+			Title:      "서문",
+			Paragraphs: utility.HTMLToText(a.Preface),
+		})
+	}
+	for _, section := range a.Sections {
+		book.Chapters = append(book.Chapters, utility.PdfChapter{Title: section.Title, Divider: true})
+		for _, id := range section.StoryIDs {
+			s := stories[id]
+			book.Chapters = append(book.Chapters, utility.PdfChapter{
+				Title:      s.Title,
+				Author:     s.AuthorNickname,
+				Paragraphs: utility.HTMLToText(s.Content),
+			})
+		}
+	}
+	if a.Colophon != "" {
+		book.Chapters = append(book.Chapters, utility.PdfChapter{
+			Title:      "판권",
+			Paragraphs: utility.HTMLToText(a.Colophon),
+		})
+	}
+
+	buf := new(bytes.Buffer)
+	if err = book.Write(buf); err != nil {
+		return
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, utility.AttachmentDisposition(a.Title+".pdf"))
+
+	return c.Blob(http.StatusOK, "application/pdf", buf.Bytes())
+}
+
+func (h *Handler) ExportAnthologyEPUB(c echo.Context) (err error) {
+	// Find anthology in database
+	a := new(model.Anthology)
+	if err = h.FindAnthology(c, a); err != nil {
+		return
+	}
+
+	// 수록작 불러오기
+	stories, err := h.anthologyStories(c, a)
+	if err != nil {
+		return
+	}
+
+	book := &utility.EpubBook{
+		Identifier: "urn:somethingmore:anthology:" + a.ID.Hex(),
+		Title:      a.Title,
+		Creator:    "썸띵모어",
+	}
+
+	// 서문 → 섹션별 수록작 → 판권면 순서로 구성
+	if a.Preface != "" {
+		body, err := utility.SanitizeXHTML(a.Preface)
+		if err != nil {
+			return err
+		}
+		book.Chapters = append(book.Chapters, utility.EpubChapter{Title: "서문", Body: body})
+	}
+	for _, section := range a.Sections {
+		chapter := utility.EpubChapter{Title: section.Title}
+		for _, id := range section.StoryIDs {
+			s := stories[id]
+			body, err := utility.SanitizeXHTML(s.Content)
+			if err != nil {
+				return err
+			}
+			chapter.Children = append(chapter.Children, utility.EpubChapter{
+				Title:  s.Title,
+				Author: s.AuthorNickname,
+				Body:   body,
+			})
+		}
+		book.Chapters = append(book.Chapters, chapter)
+	}
+	if a.Colophon != "" {
+		body, err := utility.SanitizeXHTML(a.Colophon)
+		if err != nil {
+			return err
+		}
+		book.Chapters = append(book.Chapters, utility.EpubChapter{Title: "판권", Body: body})
+	}
+
+	buf := new(bytes.Buffer)
+	if err = book.Write(buf); err != nil {
+		return
+	}
+
+	c.Response().Header().Set(echo.HeaderContentDisposition, utility.AttachmentDisposition(a.Title+".epub"))
+
+	return c.Blob(http.StatusOK, "application/epub+zip", buf.Bytes())
+}
+
+func (h *Handler) FindAnthology(c echo.Context, a *model.Anthology) (err error) {
+	// Get IDs
+	anthologyID := c.Param("anthology_id")
+	if !bson.IsObjectIdHex(anthologyID) {
+		return echo.ErrNotFound
+	}
+
+	// Find anthology in database
+	found, err := h.Anthologies.Get(c.Request().Context(), bson.ObjectIdHex(anthologyID))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
+		return
+	}
+	*a = *found
+	return
+}
+
+func (h *Handler) validateAnthology(ctx context.Context, a *model.Anthology) (err error) {
+	if a.Title == "" || a.Year == 0 {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "문집 제목과 발행 연도를 반드시 입력해야 합니다",
+		}
+	}
+
+	// 수록작은 모두 발행된 스토리여야 하며, 중복 수록은 허용하지 않음
+	var ids []bson.ObjectId
+	seen := make(map[bson.ObjectId]bool)
+	for _, section := range a.Sections {
+		if section.Title == "" {
+			return &echo.HTTPError{
+				Code:    http.StatusBadRequest,
+				Message: "섹션 제목을 반드시 입력해야 합니다",
+			}
+		}
+		for _, id := range section.StoryIDs {
+			if seen[id] {
+				return &echo.HTTPError{
+					Code:    http.StatusBadRequest,
+					Message: "같은 스토리를 두 번 수록할 수 없습니다",
+				}
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	// 수록작이 없는 문집
+	if len(ids) == 0 {
+		return
+	}
+
+	var count int
+	if count, err = h.Stories.Count(ctx, repository.PostQuery{
+		IDs:       ids,
+		Published: true}); err != nil {
+		return
+	}
+	if count != len(ids) {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "발행되지 않았거나 존재하지 않는 스토리가 포함되어 있습니다",
+		}
+	}
+	return
+}
+
+func (h *Handler) anthologyStories(c echo.Context, a *model.Anthology) (map[bson.ObjectId]*model.Post, error) {
+	// 문집에 수록된 스토리를 본문까지 불러와 ID 로 찾을 수 있게 만든다
+	var ids []bson.ObjectId
+	for _, section := range a.Sections {
+		ids = append(ids, section.StoryIDs...)
+	}
+
+	result := make(map[bson.ObjectId]*model.Post)
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	stories, err := h.Stories.List(c.Request().Context(), repository.PostQuery{
+		IDs:       ids,
+		Published: true})
+	if err != nil {
+		return nil, err
+	}
+
+	// 저장 이후 발행이 취소된 스토리가 있다면 문집을 만들 수 없음
+	if len(stories) != len(ids) {
+		return nil, &echo.HTTPError{
+			Code:    http.StatusConflict,
+			Message: "수록작 중 발행이 취소되었거나 삭제된 스토리가 있습니다",
+		}
+	}
+
+	for _, story := range stories {
+		h.MapAuthorNickname(c, story)
+		result[story.ID] = story
+	}
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	// Default package
+	"os"
+	"testing"
+	"net/url"
+	"net/http"
+	"path/filepath"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	"golang.org/x/image/font/gofont/goregular"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/utility"
+)
+
+func TestAnthology(t *testing.T) {
+	// 발행한 스토리로만 문집을 만들고, 보낸 값만 수정한다
+	s := newTestServer(t)
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	_, authorToken := s.user("author@example.com", "author", true, false)
+
+	// 문집 PDF 는 작업 디렉터리의 폰트를 쓴다: 한글이 없는 Go 폰트로 대신한다
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(filepath.Dir(utility.FontPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(utility.FontPath, goregular.TTF, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	story := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/story/", authorToken, http.StatusCreated, url.Values{"title": {"Story"}, "content": {"<p>본문</p>"}}), story)
+	draft := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/story/", authorToken, http.StatusCreated, url.Values{"title": {"Draft"}, "content": {"<p>본문</p>"}}), draft)
+	s.form(http.MethodPatch, "/story/publish/"+story.ID.Hex(), adminToken, http.StatusOK, url.Values{"is_published": {"true"}})
+
+	s.json(http.MethodPost, "/anthology/", adminToken, http.StatusBadRequest, echo.Map{
+		"title":    "Anthology",
+		"year":     2026,
+		"sections": []echo.Map{{"title": "Section", "story_ids": []bson.ObjectId{draft.ID}}}})
+	s.json(http.MethodPost, "/anthology/", authorToken, http.StatusUnauthorized, echo.Map{"title": "Anthology", "year": 2026})
+	anthology := new(model.Anthology)
+	s.decode(s.json(http.MethodPost, "/anthology/", adminToken, http.StatusCreated, echo.Map{
+		"title":    "Anthology",
+		"year":     2026,
+		"preface":  "Preface",
+		"sections": []echo.Map{{"title": "Section", "story_ids": []bson.ObjectId{story.ID}}}}), anthology)
+
+	// 목록에는 서문을 싣지 않는다
+	var anthologies []*model.Anthology
+	s.decode(s.get("/anthology/", adminToken, http.StatusOK), &anthologies)
+	if len(anthologies) != 1 || anthologies[0].Preface != "" {
+		t.Errorf("GET /anthology/ = %v, want 서문 없는 문집 1권", anthologies)
+	}
+
+	s.json(http.MethodPatch, "/anthology/"+anthology.ID.Hex(), adminToken, http.StatusOK, echo.Map{"colophon": "Colophon"})
+	retrieved := new(model.Anthology)
+	s.decode(s.get("/anthology/"+anthology.ID.Hex(), adminToken, http.StatusOK), retrieved)
+	if retrieved.Colophon != "Colophon" || retrieved.Preface != "Preface" || retrieved.Title != "Anthology" || len(retrieved.Sections) != 1 {
+		t.Errorf("PATCH /anthology/ 뒤 = %+v", retrieved)
+	}
+
+	if rec := s.get("/anthology/pdf/"+anthology.ID.Hex(), adminToken, http.StatusOK); rec.Header().Get(echo.HeaderContentType) != "application/pdf" {
+		t.Errorf("GET /anthology/pdf/ Content-Type = %q", rec.Header().Get(echo.HeaderContentType))
+	}
+	if rec := s.get("/anthology/epub/"+anthology.ID.Hex(), adminToken, http.StatusOK); rec.Header().Get(echo.HeaderContentType) != "application/epub+zip" {
+		t.Errorf("GET /anthology/epub/ Content-Type = %q", rec.Header().Get(echo.HeaderContentType))
+	}
+
+	s.request(http.MethodDelete, "/anthology/"+anthology.ID.Hex(), adminToken, http.StatusNoContent, nil, "")
+	s.get("/anthology/"+anthology.ID.Hex(), adminToken, http.StatusNotFound)
+}
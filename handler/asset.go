@@ -0,0 +1,285 @@
+package handler
+
+import (
+	// Default package
+	"io"
+	"fmt"
+	"time"
+	"errors"
+	"regexp"
+	"context"
+	"strings"
+	"net/url"
+	"net/http"
+	"log/slog"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/repository"
+	"github.com/backend/utility"
+)
+
+const (
+	AssetGracePeriod   = 24 * time.Hour // 참조가 사라진 파일을 지우기 전까지 기다리는 시간
+	AssetSweepInterval = time.Hour      // 정리 작업 간격
+)
+
+// 참조 정보가 필요한 글 컬렉션
+var assetCollections = []string{STORY, BOARD, NOTICE}
+
+// 저장소 키 모양의 경로: <해쉬값 앞 두 글자>/<SHA-256 해쉬값><확장자>
+var assetKeyPattern = regexp.MustCompile(`([0-9a-f]{2})/([0-9a-f]{64}(?:\.[a-z0-9]{1,9})?)$`)
+
+// 파일 참조를 모두 기록하기 전에는 정리하지 않는다
+var ErrAssetSweepBlocked = errors.New("파일 참조를 모두 기록하지 못해 파일 정리를 멈췄습니다")
+
+func (h *Handler) TrackAssets(ctx context.Context, refID bson.ObjectId, urls ...string) (err error) {
+	// refID 가 참조하는 파일 목록을 urls 로 바꾼다: urls 가 비어 있으면 모든 참조를 해제
+	_, err = h.trackAssets(ctx, refID, urls)
+	return
+}
+
+func (h *Handler) trackAssets(ctx context.Context, refID bson.ObjectId, urls []string) (unresolved []string, err error) {
+	// 저장소 주소가 아니지만 경로가 저장소 키 모양인 주소(public_url 을 바꾸기 전에 저장한 글 등)도 그 키로 기록하고 unresolved 로 돌려준다
+	// 잘못 짚으면 쓰지 않는 파일이 남을 뿐이고, 쓰고 있는 파일을 지우지는 않는다
+	keys := []string{}
+	seen := make(map[string]bool)
+	for _, u := range urls {
+		key, ok := h.Storage.Key(u)
+		if !ok {
+			if key, ok = assetKeyFromURL(u); ok {
+				unresolved = append(unresolved, u)
+			}
+		}
+		if ok && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	err = h.Assets.Track(ctx, refID, keys, time.Now())
+	return
+}
+
+func assetKeyFromURL(rawURL string) (string, bool) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	m := assetKeyPattern.FindStringSubmatch(u.Path)
+	if m == nil || !strings.HasPrefix(m[2], m[1]) {
+		return "", false
+	}
+	return m[1] + "/" + m[2], true
+}
+
+func (h *Handler) blockAssetSweep(err error) {
+	// err 가 nil 이면 정리를 다시 허용한다
+	h.assetSweep.Lock()
+	defer h.assetSweep.Unlock()
+	h.assetSweep.blocked = err
+}
+
+func (h *Handler) assetSweepBlocked() error {
+	h.assetSweep.Lock()
+	defer h.assetSweep.Unlock()
+	return h.assetSweep.blocked
+}
+
+func (h *Handler) trackPost(c echo.Context, p *model.Post) {
+	// 글이 쓰는 썸네일과 본문 이미지 기록: 실패해도 글 저장은 취소하지 않는다
+	if err := h.TrackAssets(c.Request().Context(), p.ID, postAssetURLs(p)...); err != nil {
+		slog.ErrorContext(c.Request().Context(), "파일 참조를 기록하지 못했습니다", "ref_id", p.ID.Hex(), "error", err)
+	}
+}
+
+func (h *Handler) ListOrphanedAssets(c echo.Context) (err error) {
+	// 지금 정리하면 지워질 파일 목록
+	report, err := h.SweepAssets(c.Request().Context(), true)
+	if err != nil {
+		return
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) DestroyOrphanedAssets(c echo.Context) (err error) {
+	// 다음 정리 작업을 기다리지 않고 바로 정리
+	report, err := h.SweepAssets(c.Request().Context(), false)
+	if errors.Is(err, ErrAssetSweepBlocked) {
+		return &echo.HTTPError{
+			Code:    http.StatusConflict,
+			Message: err.Error(),
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) StartAssetSweeper() {
+	// 서버 시작 시 한 번 호출: 기존 파일과 글의 참조를 다시 기록한 뒤 주기적으로 정리한다
+	// 참조를 다시 기록하는 동안에는 관리자가 정리를 요청해도 지우지 않는다
+	h.blockAssetSweep(fmt.Errorf("%w: 서버를 시작하며 파일 참조를 다시 기록하는 중입니다", ErrAssetSweepBlocked))
+	h.background("asset_sweeper", func() {
+		ctx := context.Background()
+		logger := slog.With("job", "asset_sweeper")
+		if err := h.discoverAssets(ctx); err != nil {
+			logger.Error("저장소의 파일 목록을 기록하지 못했습니다", "error", err)
+		}
+		if err := h.rebuildAssetRefs(ctx); err != nil {
+			// 참조를 모두 기록하지 못했다면 쓰고 있는 파일을 지울 수 있으므로 정리하지 않는다
+			logger.Error("파일 참조를 다시 기록하지 못해 정리 작업을 멈춥니다", "error", err)
+			h.blockAssetSweep(err)
+			return
+		}
+		h.blockAssetSweep(nil)
+
+		ticker := time.NewTicker(AssetSweepInterval)
+		defer ticker.Stop()
+		for {
+			report, err := h.SweepAssets(ctx, false)
+			if err != nil {
+				logger.Error("사용하지 않는 파일을 정리하지 못했습니다", "error", err)
+			} else if report.Count > 0 {
+				logger.Info("사용하지 않는 파일을 정리했습니다", "count", report.Count, "bytes", report.Size)
+			}
+			select {
+			case <-h.stopping():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+func (h *Handler) SweepAssets(ctx context.Context, dryRun bool) (report *model.AssetReport, err error) {
+	// 유예 기간이 지난 참조 없는 파일을 지운다
+	cutoff := time.Now().Add(-AssetGracePeriod)
+	report = &model.AssetReport{DryRun: dryRun, Assets: []*model.Asset{}}
+	blocked := h.assetSweepBlocked()
+	if blocked != nil {
+		if !dryRun {
+			return nil, blocked
+		}
+		report.Blocked = blocked.Error()
+	}
+	orphaned, err := h.Assets.ListOrphaned(ctx, cutoff)
+	if err != nil {
+		return
+	}
+	report.Assets = append(report.Assets, orphaned...)
+	for _, a := range report.Assets {
+		report.Count++
+		report.Size += a.Size
+	}
+	if dryRun {
+		return
+	}
+
+	for _, a := range report.Assets {
+		// 조회한 뒤에 다시 참조되었거나 같은 파일이 다시 올라왔다면 건너뛴다
+		if err = h.Assets.DeleteOrphaned(ctx, a.ID, cutoff); err != nil {
+			if err == repository.ErrNotFound {
+				err = nil
+				continue
+			}
+			return
+		}
+		if err = h.Storage.Delete(ctx, a.Key); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (h *Handler) discoverAssets(ctx context.Context) (err error) {
+	// 기록되지 않은 파일(이 기능 이전에 올린 파일 등)을 참조 없는 파일로 기록
+	now := time.Now()
+	return h.Storage.Walk(ctx, func(key string, size int64) error {
+		return h.Assets.Discover(ctx, key, size, now)
+	})
+}
+
+func (h *Handler) rebuildAssetRefs(ctx context.Context) (err error) {
+	// 모든 글과 미디어가 쓰는 파일을 다시 기록
+	// 저장소 주소로 되찾지 못한 파일 주소가 있으면 storage.public_url 이 바뀐 것이므로 오류를 돌려준다
+	var unresolved []string
+	track := func(refID bson.ObjectId, urls []string) error {
+		found, err := h.trackAssets(ctx, refID, urls)
+		unresolved = append(unresolved, found...)
+		return err
+	}
+	for _, collection := range assetCollections {
+		if err = h.posts(collection).Each(ctx, func(p *model.Post) error {
+			return track(p.ID, postAssetURLs(p))
+		}); err != nil {
+			return
+		}
+	}
+	if err = h.Media.Each(ctx, func(m *model.Media) error {
+		return track(m.ID, mediaAssetURLs(m))
+	}); err != nil {
+		return
+	}
+
+	if len(unresolved) > 0 {
+		sample := unresolved
+		if len(sample) > 5 {
+			sample = sample[:5]
+		}
+		return fmt.Errorf("%w: 저장소 주소가 아닌 파일 주소 %d개 (예: %s): 예전 주소를 storage.legacy_urls 에 추가해야 합니다",
+			ErrAssetSweepBlocked, len(unresolved), strings.Join(sample, ", "))
+	}
+	return
+}
+
+func (h *Handler) releaseUserMedia(c echo.Context, userID bson.ObjectId) {
+	// 탈퇴한 회원의 미디어 라이브러리 삭제: 글에서 쓰고 있는 이미지는 남는다
+	media, err := h.Media.List(c.Request().Context(), userID, 0, 0)
+	if err != nil {
+		slog.ErrorContext(c.Request().Context(), "회원의 미디어를 찾지 못했습니다", "owner_id", userID.Hex(), "error", err)
+		return
+	}
+	for _, m := range media {
+		if err := h.Media.Delete(c.Request().Context(), m.ID); err != nil {
+			slog.ErrorContext(c.Request().Context(), "회원의 미디어를 지우지 못했습니다", "media_id", m.ID.Hex(), "error", err)
+			continue
+		}
+		if err := h.TrackAssets(c.Request().Context(), m.ID); err != nil {
+			slog.ErrorContext(c.Request().Context(), "파일 참조를 해제하지 못했습니다", "ref_id", m.ID.Hex(), "error", err)
+		}
+	}
+}
+
+func postAssetURLs(p *model.Post) []string {
+	// 글이 쓰는 파일 주소: 썸네일과 본문 이미지
+	urls := utility.ImageSources(p.Content)
+	if p.Thumbnail != "" {
+		urls = append(urls, p.Thumbnail)
+	}
+	if t := p.Thumbnails; t != nil {
+		urls = append(urls, t.Card.URL, t.Card.WebP, t.Hero.URL, t.Hero.WebP, t.OG.URL, t.OG.WebP)
+	}
+	return urls
+}
+
+func mediaAssetURLs(m *model.Media) []string {
+	return []string{m.Content.URL, m.Content.WebP, m.Card.URL, m.Card.WebP}
+}
+
+// 저장한 파일 크기를 세는 Reader
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (r *countingReader) Read(p []byte) (n int, err error) {
+	n, err = r.r.Read(p)
+	r.n += int64(n)
+	return
+}
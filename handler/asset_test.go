@@ -0,0 +1,276 @@
+package handler
+
+import (
+	// Default package
+	"io"
+	"sync"
+	"time"
+	"bytes"
+	"errors"
+	"context"
+	"strings"
+	"testing"
+	"net/url"
+	"net/http"
+	// Third Party package
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/storage"
+	"github.com/backend/repository"
+)
+
+func (s *testServer) asset(data string, orphaned time.Time) string {
+	// 저장소에 파일을 두고 orphaned 부터 참조가 없던 것으로 기록한다
+	s.Helper()
+	ctx := context.Background()
+	key := AssetKey([]byte(data), ".png")
+	if err := s.store.Put(ctx, key, bytes.NewReader([]byte(data)), int64(len(data)), "image/png"); err != nil {
+		s.Fatal(err)
+	}
+	if _, err := s.h.Assets.Register(ctx, &model.Asset{Key: key, Size: int64(len(data)), DateCreated: orphaned, DateOrphaned: &orphaned}); err != nil {
+		s.Fatal(err)
+	}
+	return key
+}
+
+func (s *testServer) assetExists(key string) bool {
+	s.Helper()
+	r, err := s.store.Get(context.Background(), key)
+	if err != nil {
+		return false
+	}
+	r.Close()
+	return true
+}
+
+func TestSweepAssets(t *testing.T) {
+	// 유예 기간이 지난 참조 없는 파일만 지운다
+	s := newTestServer(t)
+	ctx := context.Background()
+	expired := s.asset("expired", time.Now().Add(-AssetGracePeriod-time.Hour))
+	recent := s.asset("recent", time.Now().Add(-AssetGracePeriod+time.Hour))
+	referenced := s.asset("referenced", time.Now().Add(-AssetGracePeriod-time.Hour))
+	if err := s.h.TrackAssets(ctx, bson.NewObjectId(), s.store.URL(referenced)); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := s.h.SweepAssets(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Count != 1 || report.Assets[0].Key != expired || !s.assetExists(expired) {
+		t.Fatalf("미리 보기 = %+v, want %s 만", report, expired)
+	}
+	if report, err = s.h.SweepAssets(ctx, false); err != nil {
+		t.Fatal(err)
+	}
+	if report.Count != 1 || s.assetExists(expired) {
+		t.Errorf("유예 기간이 지난 파일을 지우지 않았습니다: %+v", report)
+	}
+	if !s.assetExists(recent) || !s.assetExists(referenced) {
+		t.Errorf("유예 기간 중이거나 참조 중인 파일을 지웠습니다")
+	}
+
+	// 참조가 사라지면 그때부터 유예 기간을 센다
+	if err := s.h.TrackAssets(ctx, bson.NewObjectId()); err != nil {
+		t.Fatal(err)
+	}
+	if report, err = s.h.SweepAssets(ctx, false); err != nil || report.Count != 0 {
+		t.Errorf("방금 참조가 사라진 파일을 지웠습니다: %+v, %v", report, err)
+	}
+}
+
+func TestSweepAssetsSkipsReferenced(t *testing.T) {
+	// 목록을 읽은 뒤 다시 참조된 파일과 다시 올라온 파일은 지우지 않는다
+	s := newTestServer(t)
+	ctx := context.Background()
+	cutoff := time.Now().Add(-AssetGracePeriod)
+	reused := s.asset("reused", cutoff.Add(-time.Hour))
+	uploaded := s.asset("uploaded", cutoff.Add(-time.Hour))
+
+	orphaned, err := s.h.Assets.ListOrphaned(ctx, cutoff)
+	if err != nil || len(orphaned) != 2 {
+		t.Fatalf("ListOrphaned = %v, %v", orphaned, err)
+	}
+	if err := s.h.TrackAssets(ctx, bson.NewObjectId(), s.store.URL(reused)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.h.SaveAsset(ctx, bson.NewObjectId(), "uploaded.png", bytes.NewReader([]byte("uploaded"))); err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range orphaned {
+		if err := s.h.Assets.DeleteOrphaned(ctx, a.ID, cutoff); err != repository.ErrNotFound {
+			t.Errorf("DeleteOrphaned(%s) = %v, want ErrNotFound", a.Key, err)
+		}
+	}
+	if report, err := s.h.SweepAssets(ctx, false); err != nil || report.Count != 0 {
+		t.Errorf("SweepAssets = %+v, %v", report, err)
+	}
+	if !s.assetExists(reused) || !s.assetExists(uploaded) {
+		t.Error("다시 쓰는 파일을 지웠습니다")
+	}
+}
+
+func TestRebuildAssetRefsLegacyURL(t *testing.T) {
+	// public_url 을 바꾸기 전에 저장한 글의 파일도 참조로 기록하고, 주소를 모르면 정리를 멈춘다
+	s := newTestServer(t)
+	ctx := context.Background()
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	_, authorToken := s.user("author@example.com", "author", true, false)
+	old := time.Now().Add(-AssetGracePeriod - time.Hour)
+	legacy := s.asset("legacy", old)
+	host := s.asset("host", old)
+
+	// 예전 CDN 주소와 요청 Host 로 만든 주소
+	story := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/story/", authorToken, http.StatusCreated, url.Values{
+		"title": {"Story"},
+		"content": {`<p><img src="https://old-cdn.example.com/` + legacy + `">` +
+			`<img src="http://localhost:1323/assets/` + host + `?v=1"></p>`}}), story)
+
+	err := s.h.rebuildAssetRefs(ctx)
+	if !errors.Is(err, ErrAssetSweepBlocked) {
+		t.Fatalf("rebuildAssetRefs = %v, want ErrAssetSweepBlocked", err)
+	}
+	s.h.blockAssetSweep(err)
+	var report model.AssetReport
+	s.decode(s.get("/gc/assets/", adminToken, http.StatusOK), &report)
+	if report.Count != 0 || report.Blocked == "" {
+		t.Errorf("GET /gc/assets/ = %+v, want 정리 대상 없음과 멈춘 이유", report)
+	}
+	s.request(http.MethodPost, "/gc/assets/", adminToken, http.StatusConflict, nil, "")
+	if !s.assetExists(legacy) || !s.assetExists(host) {
+		t.Fatal("예전 주소로 쓰는 파일을 지웠습니다")
+	}
+
+	// 예전 주소를 설정하면 정리를 다시 시작한다
+	s.store.LegacyURLs = []string{"https://old-cdn.example.com", "http://localhost:1323/assets"}
+	if err := s.h.rebuildAssetRefs(ctx); err != nil {
+		t.Fatal(err)
+	}
+	s.h.blockAssetSweep(nil)
+	s.decode(s.request(http.MethodPost, "/gc/assets/", adminToken, http.StatusOK, nil, ""), &report)
+	if report.Count != 0 || !s.assetExists(legacy) || !s.assetExists(host) {
+		t.Errorf("POST /gc/assets/ = %+v: 예전 주소로 쓰는 파일을 지웠습니다", report)
+	}
+
+	// 글에서 빼면 유예 기간 뒤에 지운다
+	s.form(http.MethodPatch, "/story/"+story.ID.Hex(), authorToken, http.StatusOK, url.Values{"title": {"Story"}, "content": {"<p>이미지 없음</p>"}})
+	orphaned, err := s.h.Assets.ListOrphaned(ctx, time.Now().Add(time.Minute))
+	if err != nil || len(orphaned) != 2 {
+		t.Errorf("글에서 뺀 파일이 참조 없는 파일로 바뀌지 않았습니다: %v, %v", orphaned, err)
+	}
+}
+
+func TestAssetKeyFromURL(t *testing.T) {
+	key := AssetKey([]byte("image"), ".png")
+	cases := map[string]bool{
+		"https://cdn.example.com/" + key:                 true,
+		"http://localhost:1323/assets/" + key + "?v=1#a": true,
+		"https://example.com/" + key[:2] + "/a" + key[3:]: false, // 해쉬값이 아니다
+		"https://example.com/ff/" + key[3:]:               false, // 앞 두 글자가 다르다
+		"https://example.com/image.png":                   false,
+	}
+	for u, want := range cases {
+		got, ok := assetKeyFromURL(u)
+		if ok != want || (ok && got != key) {
+			t.Errorf("assetKeyFromURL(%q) = %q, %v, want %v", u, got, ok, want)
+		}
+	}
+}
+
+// 저장이 한 번 실패하는 저장소
+type failingStorage struct {
+	*storage.Local
+	fail bool
+}
+
+func (f *failingStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if f.fail {
+		f.fail = false
+		return errors.New("저장 실패")
+	}
+	return f.Local.Put(ctx, key, r, size, contentType)
+}
+
+func TestSaveAsset(t *testing.T) {
+	// 주소를 돌려줄 때는 파일이 저장되어 있어야 한다
+	s := newTestServer(t)
+	ctx := context.Background()
+	owner := bson.NewObjectId()
+
+	// 기록만 남고 파일이 없는 경우: 저장 전에 서버가 멈췄다
+	key := AssetKey([]byte("crashed"), ".png")
+	now := time.Now()
+	if _, err := s.h.Assets.Register(ctx, &model.Asset{Key: key, Size: 7, DateCreated: now, DateOrphaned: &now}); err != nil {
+		t.Fatal(err)
+	}
+	u, err := s.h.SaveAsset(ctx, owner, "crashed.png", strings.NewReader("crashed"))
+	if err != nil || u != s.store.URL(key) || !s.assetExists(key) {
+		t.Errorf("SaveAsset = %q, %v: 기록만 있던 파일을 저장하지 않았습니다", u, err)
+	}
+
+	// 저장에 실패하면 주소를 돌려주지 않고, 다음 업로드는 다시 저장한다
+	failing := &failingStorage{Local: s.store, fail: true}
+	s.h.Storage = failing
+	key = AssetKey([]byte("failed"), ".png")
+	if _, err := s.h.SaveAsset(ctx, owner, "failed.png", strings.NewReader("failed")); err == nil {
+		t.Fatal("저장에 실패했는데 주소를 돌려주었습니다")
+	}
+	if u, err = s.h.SaveAsset(ctx, owner, "failed.png", strings.NewReader("failed")); err != nil || !s.assetExists(key) {
+		t.Errorf("SaveAsset = %q, %v: 실패한 뒤 다시 올린 파일을 저장하지 않았습니다", u, err)
+	}
+	s.h.Storage = s.store
+
+	// 같은 파일을 동시에 올려도 모두 저장된 뒤에 주소를 돌려준다
+	key = AssetKey([]byte("concurrent"), ".png")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.h.SaveAsset(ctx, owner, "concurrent.png", strings.NewReader("concurrent")); err != nil {
+				t.Error(err)
+				return
+			}
+			if !s.assetExists(key) {
+				t.Error("파일을 저장하기 전에 주소를 돌려주었습니다")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestServeAssets(t *testing.T) {
+	// 공개 파일은 그대로, 비공개 파일은 서명이 맞고 기한이 남은 주소로만 내려받는다
+	s := newTestServer(t)
+	ctx := context.Background()
+	key := s.asset("public", time.Now())
+	rec := s.get(strings.TrimPrefix(s.store.URL(key), "http://example.com"), "", http.StatusOK)
+	if rec.Body.String() != "public" || rec.Header().Get("Cache-Control") != storage.CacheControl {
+		t.Errorf("GET 공개 파일 = %q, Cache-Control %q", rec.Body, rec.Header().Get("Cache-Control"))
+	}
+	rec = s.get("/assets/00/missing.png", "", http.StatusNotFound)
+	if rec.Header().Get("Cache-Control") != "" {
+		t.Errorf("없는 파일의 응답을 캐시합니다: %q", rec.Header().Get("Cache-Control"))
+	}
+
+	privateKey := storage.PrivatePrefix + "exports/report.txt"
+	if err := s.store.Put(ctx, privateKey, strings.NewReader("report"), 6, "text/plain"); err != nil {
+		t.Fatal(err)
+	}
+	signed, err := s.store.SignedURL(ctx, privateKey, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec := s.get(strings.TrimPrefix(signed, "http://example.com"), "", http.StatusOK); rec.Body.String() != "report" {
+		t.Errorf("GET 비공개 파일 = %q", rec.Body)
+	}
+	s.get("/private/exports/report.txt?expires=1&signature=x", "", http.StatusForbidden)
+	expired, err := s.store.SignedURL(ctx, privateKey, -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.get(strings.TrimPrefix(expired, "http://example.com"), "", http.StatusForbidden)
+}
@@ -9,19 +9,13 @@ import (
 	"github.com/labstack/echo"
 	"github.com/globalsign/mgo/bson"
 	// User package
-	"github.com/backend/model"
+	"github.com/backend/repository"
 )
 
 func (h *Handler) ListAuthors(c echo.Context) (err error) {
 	// Find users
-	var users []*model.User
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		Find(bson.M{"is_staff": true}).
-		Select(bson.M{"password": 0}).
-		Sort("-is_admin", "nickname").
-		All(&users); err != nil {
+	users, err := h.Users.List(c.Request().Context(), repository.UserQuery{Staff: true})
+	if err != nil {
 		return
 	}
 
@@ -35,6 +29,9 @@ func (h *Handler) ListStoryAuthor(c echo.Context) (err error) {
 
 	// Get Author IDs
 	AuthorID := c.Param(fmt.Sprint("author_id"))
+	if !bson.IsObjectIdHex(AuthorID) {
+		return echo.ErrNotFound
+	}
 
 	// Default pagination
 	// 페이지 당 최대 15개의 글만 쿼리
@@ -46,18 +43,14 @@ func (h *Handler) ListStoryAuthor(c echo.Context) (err error) {
 	}
 
 	// Find story in database
-	var stories []*model.Post
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(STORY).
-		Find(bson.M{
-		"author_id":    bson.ObjectIdHex(AuthorID),
-		"is_published": true}).
-		Select(bson.M{"content": 0}).
-		Sort("-date_created").
-		Skip((page - 1) * limit).
-		Limit(limit).
-		All(&stories); err != nil {
+	stories, err := h.Stories.List(c.Request().Context(), repository.PostQuery{
+		AuthorID:  bson.ObjectIdHex(AuthorID),
+		Published: true,
+		Summary:   true,
+		Skip:      (page - 1) * limit,
+		Limit:     limit,
+	})
+	if err != nil {
 		return
 	}
 
@@ -65,6 +58,8 @@ func (h *Handler) ListStoryAuthor(c echo.Context) (err error) {
 	for _, story := range stories {
 		h.MapAuthorNickname(c, story)
 	}
+	UseCardThumbnail(stories)
+	MarkAuthor(c, stories...)
 
 	return c.JSON(http.StatusOK, stories)
 }
@@ -72,17 +67,16 @@ func (h *Handler) ListStoryAuthor(c echo.Context) (err error) {
 func (h *Handler) CountStoryAuthor(c echo.Context) (err error) {
 	// Get Author IDs
 	AuthorID := c.Param(fmt.Sprint("author_id"))
+	if !bson.IsObjectIdHex(AuthorID) {
+		return echo.ErrNotFound
+	}
 
 	// int type 변수 지정
 	var count int
 
-	db := h.DB.Clone()
-	defer db.Close()
-	if count, err = db.DB(DBName).C(STORY).
-		Find(bson.M{
-		"author_id":    bson.ObjectIdHex(AuthorID),
-		"is_published": true}).
-		Count(); err != nil {
+	if count, err = h.Stories.Count(c.Request().Context(), repository.PostQuery{
+		AuthorID:  bson.ObjectIdHex(AuthorID),
+		Published: true}); err != nil {
 		return
 	}
 
@@ -4,25 +4,24 @@ import (
 	// Default package
 	"strconv"
 	"net/http"
+	"log/slog"
 	// Third Party package
 	"github.com/labstack/echo"
 	"github.com/globalsign/mgo/bson"
 	// User package
+	"github.com/backend/auth"
 	"github.com/backend/model"
+	"github.com/backend/repository"
 	"github.com/backend/utility"
 )
 
 func (h *Handler) CreateBoard(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
-		return
-	}
+	user := auth.CurrentUser(c)
 
 	// Bind board object
 	b := &model.Post{
 		ID:       bson.NewObjectId(),
-		AuthorID: bson.ObjectIdHex(userID), // 저자를 표시하기 위해 userID 삽입
+		AuthorID: user.ID, // 저자를 표시하기 위해 회원 ID 삽입
 	}
 
 	if err = c.Bind(b); err != nil {
@@ -42,12 +41,19 @@ func (h *Handler) CreateBoard(c echo.Context) (err error) {
 	b.IsPublished = true
 
 	// Save Post
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(BOARD).Insert(b); err != nil {
+	if err = h.Board.Create(c.Request().Context(), b); err != nil {
 		return
 	}
 
+	// 글이 쓰는 파일 기록
+	h.trackPost(c, b)
+
+	// 접속 중인 방문자에게 새 글 알림
+	h.Events.Publish(Event{
+		Type: EventBoard,
+		Data: echo.Map{"id": b.ID, "title": b.Title},
+	})
+
 	return c.JSON(http.StatusCreated, b)
 }
 
@@ -66,17 +72,12 @@ func (h *Handler) ListBoard(c echo.Context) (err error) {
 	}
 
 	// List boards from database
-	var boards []*model.Post
-
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(BOARD).
-		Find(nil).
-		Select(bson.M{"content": 0}). // 내용은 받아오지 않음으로써 응답시간 단축
-		Sort("-date_created").
-		Skip((page - 1) * limit).
-		Limit(limit).
-		All(&boards); err != nil {
+	boards, err := h.Board.List(c.Request().Context(), repository.PostQuery{
+		Summary: true, // 내용은 받아오지 않음으로써 응답시간 단축
+		Skip:    (page - 1) * limit,
+		Limit:   limit,
+	})
+	if err != nil {
 		return
 	}
 
@@ -84,6 +85,7 @@ func (h *Handler) ListBoard(c echo.Context) (err error) {
 	for _, board := range boards {
 		h.MapAuthorNickname(c, board)
 	}
+	MarkAuthor(c, boards...)
 
 	return c.JSON(http.StatusOK, boards)
 }
@@ -94,11 +96,7 @@ func (h *Handler) CountBoard(c echo.Context) (err error) {
 	var count int
 
 	// Get count of stories from database
-	db := h.DB.Clone()
-	defer db.Close()
-	if count, err = db.DB(DBName).C(BOARD).
-		Find(nil).
-		Count(); err != nil {
+	if count, err = h.Board.Count(c.Request().Context(), repository.PostQuery{}); err != nil {
 		return
 	}
 
@@ -120,17 +118,12 @@ func (h *Handler) RetrieveBoard(c echo.Context) (err error) {
 
 	// Map AuthorNickname
 	h.MapAuthorNickname(c, b)
+	MarkAuthor(c, b)
 
 	return c.JSON(http.StatusOK, b)
 }
 
 func (h *Handler) PatchBoard(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
-		return
-	}
-
 	// Object bind
 	b := new(model.Post)
 	if err = c.Bind(b); err != nil {
@@ -148,29 +141,17 @@ func (h *Handler) PatchBoard(c echo.Context) (err error) {
 	b.DateModified = c.FormValue("date_modified")
 
 	// Update story in database
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(BOARD).
-		Update(
-		bson.M{"_id": b.ID},
-		bson.M{"$set":
-		bson.M{
-			"title":         b.Title,
-			"content":       b.Content,
-			"date_modified": b.DateModified}}); err != nil {
+	if err = h.Board.Update(c.Request().Context(), b, "title", "content", "date_modified"); err != nil {
 		return
 	}
 
+	// 글이 쓰는 파일 기록
+	h.trackPost(c, b)
+
 	return c.JSON(http.StatusOK, b)
 }
 
 func (h *Handler) DestroyBoard(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
-		return
-	}
-
 	// Object bind
 	b := new(model.Post)
 	if err = c.Bind(b); err != nil {
@@ -183,12 +164,14 @@ func (h *Handler) DestroyBoard(c echo.Context) (err error) {
 	}
 
 	// Destroy board in database
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(BOARD).
-		Remove(bson.M{"_id": b.ID}); err != nil {
+	if err = h.Board.Delete(c.Request().Context(), b.ID); err != nil {
 		return
 	}
 
+	// 글이 쓰던 파일의 참조 해제: 유예 기간이 지나면 정리 작업이 지운다
+	if err := h.TrackAssets(c.Request().Context(), b.ID); err != nil {
+		slog.ErrorContext(c.Request().Context(), "파일 참조를 해제하지 못했습니다", "ref_id", b.ID.Hex(), "error", err)
+	}
+
 	return c.NoContent(http.StatusNoContent)
 }
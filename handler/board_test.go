@@ -0,0 +1,51 @@
+package handler
+
+import (
+	// Default package
+	"testing"
+	"net/url"
+	"net/http"
+	// User package
+	"github.com/backend/model"
+)
+
+func TestBoard(t *testing.T) {
+	// 로그인한 회원이 쓴 글에는 작성자 표시가 붙는다
+	s := newTestServer(t)
+	_, memberToken := s.user("member@example.com", "member", false, false)
+	_, otherToken := s.user("other@example.com", "other", false, false)
+
+	board := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/board/", memberToken, http.StatusCreated, url.Values{"title": {"Board"}, "content": {"<p>글</p>"}}), board)
+	s.form(http.MethodPost, "/board/", "", http.StatusBadRequest, url.Values{"title": {"Board"}, "content": {"<p>글</p>"}})
+
+	var boards []*model.Post
+	s.decode(s.get("/board/list/", "", http.StatusOK), &boards)
+	if len(boards) != 1 || boards[0].ID != board.ID || boards[0].AuthorNickname != "member" || boards[0].IsAuthor {
+		t.Errorf("GET /board/list/ = %v", boards)
+	}
+	s.decode(s.get("/board/list/", memberToken, http.StatusOK), &boards)
+	if len(boards) != 1 || !boards[0].IsAuthor {
+		t.Errorf("작성자의 GET /board/list/ = %v, want is_author", boards)
+	}
+	if count := s.get("/board/count/", "", http.StatusOK).Body.String(); count != "1" {
+		t.Errorf("GET /board/count/ = %s, want 1", count)
+	}
+
+	viewed := new(model.Post)
+	s.decode(s.get("/board/view/"+board.ID.Hex(), otherToken, http.StatusOK), viewed)
+	if viewed.IsAuthor || viewed.Content != "<p>글</p>" {
+		t.Errorf("GET /board/view/ = %+v", viewed)
+	}
+	s.form(http.MethodPatch, "/board/"+board.ID.Hex(), memberToken, http.StatusOK, url.Values{"title": {"Board"}, "content": {"<p>고친 글</p>"}})
+	s.decode(s.get("/board/view/"+board.ID.Hex(), memberToken, http.StatusOK), viewed)
+	if !viewed.IsAuthor || viewed.Content != "<p>고친 글</p>" {
+		t.Errorf("수정한 뒤 GET /board/view/ = %+v", viewed)
+	}
+
+	s.request(http.MethodDelete, "/board/"+board.ID.Hex(), memberToken, http.StatusNoContent, nil, "")
+	s.get("/board/view/"+board.ID.Hex(), "", http.StatusNotFound)
+	if count := s.get("/board/count/", "", http.StatusOK).Body.String(); count != "0" {
+		t.Errorf("지운 뒤 GET /board/count/ = %s, want 0", count)
+	}
+}
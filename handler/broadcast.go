@@ -0,0 +1,244 @@
+package handler
+
+import (
+	// Default package
+	"time"
+	"bytes"
+	"context"
+	"strconv"
+	"net/http"
+	"log/slog"
+	"html/template"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/auth"
+	"github.com/backend/model"
+	"github.com/backend/repository"
+	"github.com/backend/logging"
+	"github.com/backend/metrics"
+	"github.com/backend/utility"
+)
+
+const (
+	BroadcastBatchSize = 50                     // 진행 상황을 저장하는 단위
+	BroadcastInterval  = 200 * time.Millisecond // 메일 한 통을 보낸 뒤 쉬는 시간: 초당 최대 5통
+)
+
+func BroadcastRole(c echo.Context) (role string, err error) {
+	// 공지사항 메일 수신 대상: 기본값은 전체 회원
+	role = c.FormValue("email_role")
+	if role == "" {
+		role = "all"
+	}
+	if role != "all" && role != "staff" && role != "admin" {
+		return "", &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "메일 수신 대상은 all, staff, admin 중 하나여야 합니다",
+		}
+	}
+	return
+}
+
+func (h *Handler) BroadcastNotice(c echo.Context, n *model.Post, role string) (b *model.Broadcast, err error) {
+	// 공지사항 메일 발송 작업을 만들고 백그라운드에서 발송을 시작한다
+	b = &model.Broadcast{
+		ID:          bson.NewObjectId(),
+		NoticeID:    n.ID,
+		Role:        role,
+		Status:      model.BroadcastPending,
+		Failures:    []model.BroadcastFailure{},
+		DateCreated: time.Now(),
+	}
+
+	if err = h.Broadcasts.Create(c.Request().Context(), b); err != nil {
+		return
+	}
+
+	// 요청이 끝난 뒤에도 발송은 계속되어야 하므로 필요한 값은 미리 복사해 둔다
+	ctx, broadcast, notice, baseURL := logging.Detach(c.Request().Context()), *b, *n, BaseURL(c)
+	h.background("broadcast", func() { h.deliverBroadcast(ctx, broadcast, notice, baseURL) })
+
+	return
+}
+
+func (h *Handler) ListBroadcast(c echo.Context) (err error) {
+	// Get notice ID
+	noticeID := c.Param("notice_id")
+	if !bson.IsObjectIdHex(noticeID) {
+		return echo.ErrNotFound
+	}
+
+	// 공지사항별 발송 리포트
+	broadcasts, err := h.Broadcasts.List(c.Request().Context(), bson.ObjectIdHex(noticeID))
+	if err != nil {
+		return
+	}
+
+	return c.JSON(http.StatusOK, broadcasts)
+}
+
+func (h *Handler) Unsubscribe(c echo.Context) (err error) {
+	// 메일 하단의 수신 거부 링크: 로그인 없이 서명된 토큰으로 확인
+	// 메일 보안 검사나 링크 미리 읽기가 누르지 않은 링크를 열 수 있으므로 GET 은 확인 화면만 보여준다
+	userID, ok := utility.VerifySignedValue(Key, c.Param("token"))
+	if !ok || !bson.IsObjectIdHex(userID) {
+		return echo.ErrNotFound
+	}
+	if c.Request().Method == http.MethodGet {
+		return confirmPage(c, "공지사항 메일 수신 거부", "공지사항 메일을 더 이상 받지 않으시겠습니까?", "수신 거부")
+	}
+
+	// 확인 화면의 버튼이나 메일 프로그램의 원클릭 수신 거부(RFC 8058)
+	if err = h.Users.Update(c.Request().Context(), &model.User{ID: bson.ObjectIdHex(userID), UnsubscribedNotice: true}, "unsubscribed_notice"); err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
+		return
+	}
+
+	return c.String(http.StatusOK, "공지사항 메일 수신이 거부되었습니다\n")
+}
+
+// 수신 거부, 구독 취소 확인 화면: 버튼을 누르면 같은 주소로 POST 한다
+var confirmTemplate = template.Must(template.New("confirm").Parse(`<!DOCTYPE html>
+<html lang="ko">
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+</head>
+<body>
+<p>{{.Message}}</p>
+<form method="post"><button type="submit">{{.Button}}</button></form>
+</body>
+</html>
+`))
+
+func confirmPage(c echo.Context, title string, message string, button string) error {
+	buf := new(bytes.Buffer)
+	if err := confirmTemplate.Execute(buf, map[string]string{"Title": title, "Message": message, "Button": button}); err != nil {
+		return err
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	c.Response().Header().Set("Referrer-Policy", "no-referrer")
+	return c.HTMLBlob(http.StatusOK, buf.Bytes())
+}
+
+func (h *Handler) PatchSubscription(c echo.Context) (err error) {
+	user := auth.CurrentUser(c)
+
+	// 공지사항 메일 수신 여부 변경
+	unsubscribed, _ := strconv.ParseBool(c.FormValue("unsubscribed_notice"))
+
+	if err = h.Users.Update(c.Request().Context(), &model.User{ID: user.ID, UnsubscribedNotice: unsubscribed}, "unsubscribed_notice"); err != nil {
+		return
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) deliverBroadcast(ctx context.Context, b model.Broadcast, n model.Post, baseURL string) {
+	logger := slog.With("job", "broadcast", "broadcast_id", b.ID.Hex())
+
+	// 수신 대상: 활성화된 회원 중 역할 조건에 맞는 회원
+	query := repository.UserQuery{Active: true}
+	switch b.Role {
+	case "staff":
+		query.Staff = true
+	case "admin":
+		query.Admin = true
+	}
+
+	users, err := h.Users.List(ctx, query)
+	if err != nil {
+		logger.ErrorContext(ctx, "공지사항 메일 수신자를 찾지 못했습니다", "error", err)
+		return
+	}
+
+	// 수신 거부한 회원은 건너뛴다
+	var recipients []*model.User
+	for _, u := range users {
+		if u.UnsubscribedNotice {
+			b.Skipped++
+			continue
+		}
+		recipients = append(recipients, u)
+	}
+	b.Total = len(recipients)
+	b.Status = model.BroadcastSending
+	if err := h.Broadcasts.Update(ctx, &b, "status", "total", "skipped"); err != nil {
+		logger.ErrorContext(ctx, "발송 상태를 저장하지 못했습니다", "error", err)
+	}
+
+	// 발송 속도 제한
+	ticker := time.NewTicker(BroadcastInterval)
+	defer ticker.Stop()
+
+	noticeURL := SiteURL + "/notice/" + n.ID.Hex()
+	var remaining []*model.User
+loop:
+	for i, u := range recipients {
+		// 서버가 종료되면 남은 수신자는 outbox 에 넘긴다
+		select {
+		case <-h.stopping():
+			remaining = recipients[i:]
+			break loop
+		case <-ticker.C:
+		}
+
+		unsubscribeURL := baseURL + "/unsubscribe/" + utility.SignValue(Key, u.ID.Hex())
+		if err := h.sendNoticeEmail(ctx, u, &n, noticeURL, unsubscribeURL); err != nil {
+			logger.WarnContext(ctx, "공지사항 메일을 보내지 못했습니다", "recipient_id", u.ID.Hex(), "error", err)
+			b.Failed++
+			b.Failures = append(b.Failures, model.BroadcastFailure{Email: u.Email, Error: err.Error()})
+		} else {
+			b.Sent++
+		}
+
+		// 배치 단위로 진행 상황 저장
+		if (i+1)%BroadcastBatchSize == 0 {
+			if err := h.Broadcasts.Update(ctx, &b, "sent", "failed", "failures"); err != nil {
+				logger.ErrorContext(ctx, "발송 상태를 저장하지 못했습니다", "error", err)
+			}
+		}
+	}
+
+	for _, u := range remaining {
+		unsubscribeURL := baseURL + "/unsubscribe/" + utility.SignValue(Key, u.ID.Hex())
+		r, err := utility.NewNoticeEmail(u, &n, noticeURL, unsubscribeURL)
+		if err == nil {
+			err = h.Enqueue(ctx, model.OutboxNotice, r)
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "공지사항 메일을 outbox 에 넘기지 못했습니다", "recipient_id", u.ID.Hex(), "error", err)
+			b.Failed++
+			b.Failures = append(b.Failures, model.BroadcastFailure{Email: u.Email, Error: err.Error()})
+			continue
+		}
+		b.Queued++
+	}
+
+	// 발송 완료: 중단했으면 남은 메일은 outbox 가 재시작 후 보낸다
+	now := time.Now()
+	b.Status = model.BroadcastDone
+	if remaining != nil {
+		b.Status = model.BroadcastInterrupted
+	}
+	b.DateFinished = &now
+	if err := h.Broadcasts.Update(ctx, &b, "status", "sent", "failed", "queued", "failures", "date_finished"); err != nil {
+		logger.ErrorContext(ctx, "발송 상태를 저장하지 못했습니다", "error", err)
+	}
+	logger.InfoContext(ctx, "공지사항 메일 발송을 마쳤습니다", "status", b.Status, "sent", b.Sent, "failed", b.Failed, "queued", b.Queued, "skipped", b.Skipped)
+}
+
+func (h *Handler) sendNoticeEmail(ctx context.Context, u *model.User, n *model.Post, noticeURL string, unsubscribeURL string) (err error) {
+	defer func() { metrics.ObserveMail("notice", err) }()
+	r, err := utility.NewNoticeEmail(u, n, noticeURL, unsubscribeURL)
+	if err != nil {
+		return
+	}
+	return h.Mailer.Send(ctx, r)
+}
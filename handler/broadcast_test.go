@@ -0,0 +1,117 @@
+package handler
+
+import (
+	// Default package
+	"context"
+	"strings"
+	"testing"
+	"net/url"
+	"net/http"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/utility"
+)
+
+func TestBroadcastStopsOnShutdown(t *testing.T) {
+	// 종료 중에는 공지사항 메일과 소식지를 보내지 않고 outbox 에 넘긴 뒤, 재시작한 워커가 보낸다
+	s := newTestServer(t)
+	ctx := context.Background()
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	_, authorToken := s.user("author@example.com", "author", true, false)
+	s.user("member@example.com", "member", false, false)
+
+	story := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/story/", authorToken, http.StatusCreated, url.Values{"title": {"Story"}, "content": {"<p>Story</p>"}}), story)
+	s.form(http.MethodPatch, "/story/publish/"+story.ID.Hex(), adminToken, http.StatusOK, url.Values{"is_published": {"true"}})
+	for _, email := range []string{"reader@example.com", "reader2@example.com"} {
+		sub, err := s.h.Subscribers.GetOrCreate(ctx, &model.Subscriber{Email: email})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.get("/newsletter/confirm/"+utility.SignValue(Key, newsletterConfirm+sub.ID.Hex()), "", http.StatusFound)
+	}
+	s.deliverOutbox()
+	s.mailer.Reset()
+
+	s.h.Stop()
+	notice := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/notice/", adminToken, http.StatusCreated, url.Values{
+		"title":      {"Notice"},
+		"content":    {"<p>공지</p>"},
+		"send_email": {"true"}}), notice)
+	s.form(http.MethodPost, "/newsletter/send/", adminToken, http.StatusAccepted, url.Values{"subject": {"Newsletter"}})
+	s.wait()
+	if sent := s.mailer.Sent(); len(sent) != 0 {
+		t.Fatalf("종료 중에 메일을 %d통 보냈습니다", len(sent))
+	}
+
+	// 발송 기록은 중단 상태로 남고, 보내지 않은 메일은 모두 outbox 에 있다
+	broadcasts, err := s.h.Broadcasts.List(ctx, notice.ID)
+	if err != nil || len(broadcasts) != 1 {
+		t.Fatalf("Broadcasts.List = %v, %v", broadcasts, err)
+	}
+	if b := broadcasts[0]; b.Status != model.BroadcastInterrupted || b.Total != 3 || b.Queued != 3 || b.Sent != 0 || b.DateFinished == nil {
+		t.Errorf("공지사항 발송 기록 = %+v, want interrupted, queued 3", b)
+	}
+	issues, err := s.h.Newsletters.List(ctx)
+	if err != nil || len(issues) != 1 {
+		t.Fatalf("Newsletters.List = %v, %v", issues, err)
+	}
+	if issue := issues[0]; issue.Status != model.BroadcastInterrupted || issue.Total != 2 || issue.Queued != 2 || issue.Sent != 0 || issue.DateFinished == nil {
+		t.Errorf("소식지 발송 기록 = %+v, want interrupted, queued 2", issue)
+	}
+	pending, err := s.h.Outbox.List(ctx, model.OutboxPending, 0, 10)
+	if err != nil || len(pending) != 5 {
+		t.Fatalf("outbox = %d통, %v, want 5통", len(pending), err)
+	}
+
+	// outbox 로 보낸 메일도 수신 거부 헤더를 그대로 담는다
+	s.deliverOutbox()
+	if r := s.mailTo("member@example.com"); !strings.HasPrefix(r.Unsubscribe, "http://example.com/unsubscribe/") {
+		t.Errorf("공지사항 메일 List-Unsubscribe = %q", r.Unsubscribe)
+	}
+	if r := s.mailTo("reader@example.com"); r.Subject != "Newsletter" || !strings.HasPrefix(r.Unsubscribe, "http://example.com/newsletter/unsubscribe/") {
+		t.Errorf("소식지 = %q, List-Unsubscribe %q", r.Subject, r.Unsubscribe)
+	}
+	if sent := s.mailer.Sent(); len(sent) != 5 {
+		t.Errorf("outbox 에서 보낸 메일 = %d통, want 5통", len(sent))
+	}
+}
+
+func TestBroadcast(t *testing.T) {
+	// 역할 조건에 맞고 수신을 거부하지 않은 회원에게만 보내고, 발송 기록에 남긴다
+	s := newTestServer(t)
+	ctx := context.Background()
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	author, _ := s.user("author@example.com", "author", true, false)
+	s.user("member@example.com", "member", false, false)
+	if err := s.h.Users.Update(ctx, &model.User{ID: author.ID, UnsubscribedNotice: true}, "unsubscribed_notice"); err != nil {
+		t.Fatal(err)
+	}
+
+	notice := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/notice/", adminToken, http.StatusCreated, url.Values{
+		"title":      {"Notice"},
+		"content":    {"<p>공지</p>"},
+		"send_email": {"true"},
+		"email_role": {"staff"}}), notice)
+	s.form(http.MethodPost, "/notice/", adminToken, http.StatusBadRequest, url.Values{
+		"title":      {"Notice"},
+		"content":    {"<p>공지</p>"},
+		"send_email": {"true"},
+		"email_role": {"owner"}})
+	s.wait()
+	s.mailTo("admin@example.com")
+	if sent := s.mailer.Sent(); len(sent) != 1 {
+		t.Errorf("공지사항 메일 = %d통, want 필진 중 수신을 거부하지 않은 1명", len(sent))
+	}
+
+	var broadcasts []*model.Broadcast
+	s.decode(s.get("/notice/broadcast/"+notice.ID.Hex(), adminToken, http.StatusOK), &broadcasts)
+	if len(broadcasts) != 1 {
+		t.Fatalf("GET /notice/broadcast/ = %v, want 발송 기록 1건", broadcasts)
+	}
+	if b := broadcasts[0]; b.Role != "staff" || b.Status != model.BroadcastDone || b.Total != 1 || b.Sent != 1 || b.Skipped != 1 || b.Queued != 0 {
+		t.Errorf("발송 기록 = %+v", b)
+	}
+}
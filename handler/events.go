@@ -0,0 +1,201 @@
+package handler
+
+import (
+	// Default package
+	"fmt"
+	"sync"
+	"time"
+	"strconv"
+	"net/http"
+	"encoding/json"
+	// Third Party package
+	"github.com/labstack/echo"
+	// User package
+	"github.com/backend/auth"
+	"github.com/backend/metrics"
+)
+
+const (
+	EventHistorySize = 256              // Last-Event-ID 로 다시 받을 수 있는 최근 이벤트 수
+	EventBufferSize  = 32               // 구독자별 대기열 크기: 가득 차면 이벤트를 버린다
+	EventHeartbeat   = 25 * time.Second // 프록시가 연결을 끊지 않도록 보내는 주석 간격
+)
+
+// 이벤트 종류
+const (
+	EventBoard        = "board"        // 새 자유게시판 글
+	EventNotice       = "notice"       // 새 공지사항
+	EventStory        = "story"        // 스토리 발행
+	EventNotification = "notification" // 개인 알림
+)
+
+type (
+	Event struct {
+		ID      uint64      // 이벤트 번호: 서버가 시작된 시각부터 증가
+		Type    string      // 이벤트 종류
+		Data    interface{} // JSON 으로 보낼 내용
+		UserID  string      // 특정 회원에게만 보낼 때 사용
+		Private bool        // 로그인한 회원에게만 보낼 때 사용
+	}
+
+	Broker struct {
+		mu          sync.Mutex
+		nextID      uint64
+		history     []Event
+		subscribers map[*subscriber]bool
+	}
+
+	subscriber struct {
+		ch     chan Event
+		userID string // 비어 있으면 공개 스트림
+	}
+)
+
+func NewBroker() *Broker {
+	// 서버를 재시작해도 이전 이벤트 번호와 겹치지 않도록 시작 시각을 기준으로 번호를 매긴다
+	return &Broker{
+		nextID:      uint64(time.Now().UnixNano() / int64(time.Millisecond)),
+		subscribers: make(map[*subscriber]bool),
+	}
+}
+
+func (b *Broker) Publish(e Event) {
+	// 브로커가 없는 환경(CLI 등)에서는 아무것도 하지 않는다
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	e.ID = b.nextID
+	b.history = append(b.history, e)
+	if len(b.history) > EventHistorySize {
+		b.history = b.history[len(b.history)-EventHistorySize:]
+	}
+
+	for s := range b.subscribers {
+		if !s.accepts(e) {
+			continue
+		}
+		// 느린 구독자 때문에 다른 요청이 막히지 않도록 가득 찬 대기열은 건너뛴다
+		select {
+		case s.ch <- e:
+		default:
+		}
+	}
+}
+
+func (b *Broker) subscribe(userID string, lastID uint64) (s *subscriber, replay []Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	s = &subscriber{ch: make(chan Event, EventBufferSize), userID: userID}
+	b.subscribers[s] = true
+	metrics.EventSubscribers.Inc()
+
+	// 끊긴 동안 놓친 이벤트를 다시 보낸다
+	if lastID > 0 {
+		for _, e := range b.history {
+			if e.ID > lastID && s.accepts(e) {
+				replay = append(replay, e)
+			}
+		}
+	}
+	return
+}
+
+func (b *Broker) unsubscribe(s *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subscribers[s] {
+		delete(b.subscribers, s)
+		metrics.EventSubscribers.Dec()
+	}
+}
+
+func (s *subscriber) accepts(e Event) bool {
+	if e.UserID != "" {
+		return e.UserID == s.userID
+	}
+	if e.Private {
+		return s.userID != ""
+	}
+	return true
+}
+
+func (h *Handler) StreamEvents(c echo.Context) (err error) {
+	// Find user in database
+	// EventSource 는 헤더를 보낼 수 없으므로 토큰은 token 쿼리로 받는다
+	user := auth.CurrentUser(c)
+
+	return h.streamEvents(c, user.ID.Hex())
+}
+
+func (h *Handler) StreamPublicEvents(c echo.Context) (err error) {
+	// 로그인하지 않은 방문자를 위한 공개 스트림
+	return h.streamEvents(c, "")
+}
+
+func (h *Handler) streamEvents(c echo.Context, userID string) (err error) {
+	// 재접속한 브라우저는 Last-Event-ID 헤더로 마지막으로 받은 이벤트 번호를 알려준다
+	lastEventID := c.Request().Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = c.QueryParam("last_event_id")
+	}
+	lastID, _ := strconv.ParseUint(lastEventID, 10, 64)
+
+	s, replay := h.Events.subscribe(userID, lastID)
+	defer h.Events.unsubscribe(s)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+	res.Header().Set("Connection", "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no") // nginx 버퍼링 끄기
+	res.WriteHeader(http.StatusOK)
+
+	// 재접속 대기 시간
+	if _, err = fmt.Fprint(res, "retry: 3000\n\n"); err != nil {
+		return nil
+	}
+	for _, e := range replay {
+		if err = writeEvent(res, e); err != nil {
+			return nil
+		}
+	}
+	res.Flush()
+
+	heartbeat := time.NewTicker(EventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			// 클라이언트가 연결을 끊음
+			return nil
+		case <-h.stopping():
+			// 서버 종료: 브라우저는 retry 간격 뒤에 다른 서버로 다시 연결한다
+			return nil
+		case e := <-s.ch:
+			if err = writeEvent(res, e); err != nil {
+				return nil
+			}
+		case <-heartbeat.C:
+			if _, err = fmt.Fprint(res, ": ping\n\n"); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+	}
+}
+
+func writeEvent(res *echo.Response, e Event) (err error) {
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return
+	}
+	_, err = fmt.Fprintf(res, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+	return
+}
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	// Default package
+	"time"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"net/http"
+	"net/http/httptest"
+)
+
+func TestEvents(t *testing.T) {
+	// 요청이 끝나면 스트림을 닫는다: 회원 스트림은 token 쿼리로 인증한다
+	// 다시 연결하면 last_event_id 뒤의 이벤트 중 받을 수 있는 것만 다시 보낸다
+	s := newTestServer(t)
+	member, memberToken := s.user("member@example.com", "member", false, false)
+	other, _ := s.user("other@example.com", "other", false, false)
+	s.h.Events.Publish(Event{Type: EventBoard, Data: "before"})
+	lastID := strconv.FormatUint(s.h.Events.nextID, 10)
+	s.h.Events.Publish(Event{Type: EventNotice, Data: "public"})
+	s.h.Events.Publish(Event{Type: EventBoard, Data: "private", Private: true})
+	s.h.Events.Publish(Event{Type: EventNotification, Data: "member", UserID: member.ID.Hex()})
+	s.h.Events.Publish(Event{Type: EventNotification, Data: "other", UserID: other.ID.Hex()})
+
+	stream := func(target string) *httptest.ResponseRecorder {
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		req := httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx)
+		rec := httptest.NewRecorder()
+		s.e.ServeHTTP(rec, req)
+		return rec
+	}
+	cases := map[string][]string{
+		"/events/?token=" + memberToken + "&last_event_id=" + lastID: {"public", "private", "member"},
+		"/events/public/?last_event_id=" + lastID:                     {"public"},
+	}
+	for target, want := range cases {
+		rec := stream(target)
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Body.String(), "retry:") {
+			t.Errorf("GET %s = %d: %s", target, rec.Code, rec.Body)
+			continue
+		}
+		var got []string
+		for _, line := range strings.Split(rec.Body.String(), "\n") {
+			if data := strings.TrimPrefix(line, "data: "); data != line {
+				got = append(got, strings.Trim(data, `"`))
+			}
+		}
+		if strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Errorf("GET %s 이벤트 = %v, want %v", target, got, want)
+		}
+	}
+	if rec := stream("/events/"); rec.Code != http.StatusBadRequest {
+		t.Errorf("토큰 없는 GET /events/ = %d, want 400", rec.Code)
+	}
+}
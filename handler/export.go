@@ -0,0 +1,160 @@
+package handler
+
+import (
+	// Default package
+	"bytes"
+	"context"
+	"net/url"
+	"net/http"
+	"io/ioutil"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/repository"
+	"github.com/backend/utility"
+)
+
+func (h *Handler) ExportStory(c echo.Context) (err error) {
+	// Object bind
+	s := new(model.Post)
+	if err = c.Bind(s); err != nil {
+		return
+	}
+
+	// Find story in database
+	if err = h.FindPost(c, s, STORY); err != nil {
+		return
+	}
+
+	// 발행되지 않은 스토리는 내보낼 수 없음
+	if s.IsPublished == false {
+		return echo.ErrNotFound
+	}
+
+	// Map AuthorNickname
+	h.MapAuthorNickname(c, s)
+
+	book := &utility.EpubBook{
+		Identifier: "urn:somethingmore:story:" + s.ID.Hex(),
+		Title:      s.Title,
+		Creator:    s.AuthorNickname,
+	}
+
+	return h.sendEpub(c, book, []*model.Post{s})
+}
+
+func (h *Handler) ExportSeries(c echo.Context) (err error) {
+	// Get Author ID & series name
+	authorID := c.Param("author_id")
+	series := c.Param("series")
+	if !bson.IsObjectIdHex(authorID) || series == "" {
+		return echo.ErrNotFound
+	}
+
+	// Find stories in database
+	// 연재 순서대로 읽을 수 있도록 생성일자 순으로 정렬
+	stories, err := h.Stories.List(c.Request().Context(), repository.PostQuery{
+		AuthorID:  bson.ObjectIdHex(authorID),
+		Series:    series,
+		Published: true,
+		Oldest:    true})
+	if err != nil {
+		return
+	}
+
+	if len(stories) == 0 {
+		return echo.ErrNotFound
+	}
+
+	// Map AuthorNickname
+	h.MapAuthorNickname(c, stories[0])
+
+	book := &utility.EpubBook{
+		Identifier: "urn:somethingmore:series:" + authorID + ":" + url.PathEscape(series),
+		Title:      series,
+		Creator:    stories[0].AuthorNickname,
+	}
+
+	return h.sendEpub(c, book, stories)
+}
+
+func (h *Handler) ExportAuthor(c echo.Context) (err error) {
+	// Get Author ID
+	authorID := c.Param("author_id")
+	if !bson.IsObjectIdHex(authorID) {
+		return echo.ErrNotFound
+	}
+
+	// Find stories in database
+	// ListStoryAuthor 와 같은 조건으로, 페이지 구분 없이 본문까지 모두 가져온다
+	stories, err := h.Stories.List(c.Request().Context(), repository.PostQuery{
+		AuthorID:  bson.ObjectIdHex(authorID),
+		Published: true,
+		Oldest:    true})
+	if err != nil {
+		return
+	}
+
+	if len(stories) == 0 {
+		return echo.ErrNotFound
+	}
+
+	// Map AuthorNickname
+	h.MapAuthorNickname(c, stories[0])
+
+	book := &utility.EpubBook{
+		Identifier: "urn:somethingmore:author:" + authorID,
+		Title:      stories[0].AuthorNickname + " 작품집",
+		Creator:    stories[0].AuthorNickname,
+	}
+
+	return h.sendEpub(c, book, stories)
+}
+
+func (h *Handler) sendEpub(c echo.Context, book *utility.EpubBook, stories []*model.Post) (err error) {
+	// 스토리 슬라이스를 챕터로 변환하고 EPUB 파일을 응답으로 보낸다
+	for _, s := range stories {
+		body, err := utility.SanitizeXHTML(s.Content)
+		if err != nil {
+			return err
+		}
+		book.Chapters = append(book.Chapters, utility.EpubChapter{Title: s.Title, Body: body})
+
+		// 썸네일이 있는 첫 번째 스토리를 표지로 사용
+		if book.Cover == nil && s.Thumbnail != "" {
+			book.Cover, book.CoverType = h.readThumbnail(c.Request().Context(), s.Thumbnail)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err = book.Write(buf); err != nil {
+		return
+	}
+
+	// 한글 파일명을 위해 RFC 5987 형식으로 인코딩
+	c.Response().Header().Set(echo.HeaderContentDisposition, utility.AttachmentDisposition(book.Title+".epub"))
+
+	return c.Blob(http.StatusOK, "application/epub+zip", buf.Bytes())
+}
+
+func (h *Handler) readThumbnail(ctx context.Context, thumbnailURL string) ([]byte, string) {
+	// 썸네일 URL 에서 저장소의 키를 추출해 파일을 읽는다
+	// 읽을 수 없는 경우 표지 없이 내보낸다
+	key, ok := h.Storage.Key(thumbnailURL)
+	if !ok {
+		return nil, ""
+	}
+	f, err := h.Storage.Get(ctx, key)
+	if err != nil {
+		return nil, ""
+	}
+	defer f.Close()
+
+	content, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, ""
+	}
+	return content, http.DetectContentType(content)
+}
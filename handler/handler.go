@@ -1,13 +1,51 @@
 package handler
 
-import "github.com/globalsign/mgo"
+import (
+	// Default package
+	"sync"
+	"time"
+	// User package
+	"github.com/backend/auth"
+	"github.com/backend/storage"
+	"github.com/backend/repository"
+	"github.com/backend/utility"
+)
 
 type (
 	Handler struct {
-		DB *mgo.Session
+		Events     *Broker         // 실시간 이벤트: 없으면 발행하지 않는다
+		Mailer     utility.Mailer  // 메일 발송
+		Storage    storage.Storage // 업로드한 파일 저장소
+		Principals *auth.Cache     // 인증한 회원의 현재 상태: 회원 상태를 바꾸면 지운다
+
+		// 데이터베이스 저장소: 핸들러는 인터페이스로만 접근한다
+		repository.Repositories
+
+		outbox chan struct{} // 새 메일이 쌓이면 outbox 워커를 깨운다
+
+		// 종료 처리
+		quit     chan struct{}  // Stop 을 호출하면 닫힌다
+		quitOnce sync.Once
+		stopOnce sync.Once
+		workers  sync.WaitGroup // 종료할 때 기다리는 백그라운드 작업
+
+		// 파일 정리를 멈춘 이유: nil 이면 정리한다
+		assetSweep struct {
+			sync.Mutex
+			blocked error
+		}
+
+		// 최근 메일 서버 확인 결과
+		mailCheck struct {
+			sync.Mutex
+			checked time.Time
+			err     error
+		}
 	}
 )
 
-const (
-	Key = "secret"
+// 서버 시작 시 설정 값으로 바꾼다
+var (
+	Key     = "secret"                          // JWT 서명 키
+	SiteURL = "https://www.somethingmore.co.kr" // 프론트엔드 주소
 )
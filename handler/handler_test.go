@@ -0,0 +1,413 @@
+package handler
+
+import (
+	// Default package
+	"io"
+	"os"
+	"fmt"
+	"flag"
+	"sync"
+	"time"
+	"bytes"
+	"image"
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"net/url"
+	"net/http"
+	"image/png"
+	"image/color"
+	"path/filepath"
+	"encoding/json"
+	"mime/multipart"
+	"net/http/httptest"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/auth"
+	"github.com/backend/model"
+	"github.com/backend/openapi"
+	"github.com/backend/storage"
+	"github.com/backend/repository"
+	"github.com/backend/utility"
+)
+
+// 메모리 저장소, 메모리 메일, 임시 디렉터리의 로컬 파일 저장소로 띄운 서버: 라우트 표는 서버와 같다
+type testServer struct {
+	*testing.T
+	e      *echo.Echo
+	h      *Handler
+	r      *auth.Router
+	store  *storage.Local
+	mailer *utility.MemoryMailer
+}
+
+// 테스트 전체에서 요청이 도달한 라우트: "메소드 경로"
+var routeHits = struct {
+	sync.Mutex
+	routes []auth.Route
+	hits   map[string]bool
+}{hits: map[string]bool{}}
+
+func TestMain(m *testing.M) {
+	// 테스트를 모두 실행했다면 라우트 표의 모든 라우트에 요청했는지 확인한다
+	// 새 라우트를 추가하면 그 기능의 테스트에서 요청해야 한다
+	flag.Parse()
+	code := m.Run()
+	if code == 0 && !testFiltered() {
+		for _, route := range routeHits.routes {
+			if !routeHits.hits[route.Method+" "+route.Path] {
+				fmt.Fprintf(os.Stderr, "%s %s 에 요청한 테스트가 없습니다\n", route.Method, route.Path)
+				code = 1
+			}
+		}
+	}
+	os.Exit(code)
+}
+
+func testFiltered() bool {
+	// -run, -skip 으로 일부 테스트만 실행했다
+	for _, name := range []string{"test.run", "test.skip"} {
+		if f := flag.Lookup(name); f != nil && f.Value.String() != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func newTestServer(t *testing.T) *testServer {
+	dir := t.TempDir()
+	s := &testServer{
+		T: t,
+		e: echo.New(),
+		store: storage.NewLocal(storage.Config{
+			Dir:        filepath.Join(dir, "assets"),
+			PrivateDir: filepath.Join(dir, "private"),
+			PublicURL:  "http://example.com/assets",
+			PrivateURL: "http://example.com/private",
+			SigningKey: "test",
+		}),
+		mailer: &utility.MemoryMailer{From: "test@example.com"},
+	}
+	s.h = &Handler{
+		Repositories: repository.NewMemory(),
+		Events:       NewBroker(),
+		Mailer:       s.mailer,
+		Storage:      s.store,
+	}
+	s.h.Principals = auth.NewCache(s.h.LoadPrincipal, auth.CacheTTL)
+
+	// 라우터가 찾은 경로를 기록한다: 인증에 실패한 요청도 라우트에는 도달한 것으로 본다
+	s.e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			routeHits.Lock()
+			routeHits.hits[c.Request().Method+" "+c.Path()] = true
+			routeHits.Unlock()
+			return next(c)
+		}
+	})
+	s.r = auth.New(s.e, []byte(Key), s.h.Principals)
+	s.h.Routes(s.r, RouteOptions{Spec: openapi.Spec(), Metrics: true, MetricsToken: "metrics"})
+	routeHits.Lock()
+	routeHits.routes = s.r.Routes()
+	routeHits.Unlock()
+
+	t.Cleanup(func() {
+		// 공지사항 메일과 소식지 발송이 끝날 때까지 기다린다
+		s.h.Stop()
+		s.h.Wait(context.Background())
+	})
+	return s
+}
+
+func (s *testServer) user(email string, nickname string, staff bool, admin bool) (*model.User, string) {
+	// 활성화된 회원을 만들고 로그인 토큰을 돌려준다
+	s.Helper()
+	u := &model.User{
+		ID:       bson.NewObjectId(),
+		Email:    email,
+		Nickname: nickname,
+		Password: "password",
+		IsActive: true,
+		IsStaff:  staff,
+		IsAdmin:  admin,
+	}
+	if err := s.h.CreateUser(context.Background(), u); err != nil {
+		s.Fatal(err)
+	}
+	token, err := utility.CreateJWT(u).SignedString([]byte(Key))
+	if err != nil {
+		s.Fatal(err)
+	}
+	return u, token
+}
+
+func (s *testServer) request(method string, target string, token string, want int, body io.Reader, contentType string) *httptest.ResponseRecorder {
+	s.Helper()
+	req := httptest.NewRequest(method, target, body)
+	if contentType != "" {
+		req.Header.Set(echo.HeaderContentType, contentType)
+	}
+	if token != "" {
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.e.ServeHTTP(rec, req)
+	if rec.Code != want {
+		s.Fatalf("%s %s = %d, want %d: %s", method, target, rec.Code, want, rec.Body)
+	}
+	return rec
+}
+
+func (s *testServer) get(target string, token string, want int) *httptest.ResponseRecorder {
+	s.Helper()
+	return s.request(http.MethodGet, target, token, want, nil, "")
+}
+
+func (s *testServer) form(method string, target string, token string, want int, values url.Values) *httptest.ResponseRecorder {
+	s.Helper()
+	return s.request(method, target, token, want, strings.NewReader(values.Encode()), echo.MIMEApplicationForm)
+}
+
+func (s *testServer) json(method string, target string, token string, want int, v interface{}) *httptest.ResponseRecorder {
+	s.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		s.Fatal(err)
+	}
+	return s.request(method, target, token, want, bytes.NewReader(body), echo.MIMEApplicationJSON)
+}
+
+func (s *testServer) upload(target string, token string, want int, fileName string, data []byte, values url.Values) *httptest.ResponseRecorder {
+	// file 필드에 파일을 첨부한 multipart 요청
+	s.Helper()
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+	for key := range values {
+		w.WriteField(key, values.Get(key))
+	}
+	part, err := w.CreateFormFile("file", fileName)
+	if err != nil {
+		s.Fatal(err)
+	}
+	part.Write(data)
+	w.Close()
+	return s.request(http.MethodPost, target, token, want, body, w.FormDataContentType())
+}
+
+func (s *testServer) decode(rec *httptest.ResponseRecorder, v interface{}) {
+	s.Helper()
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		s.Fatalf("응답을 읽지 못했습니다: %v: %s", err, rec.Body)
+	}
+}
+
+func (s *testServer) deliverOutbox() {
+	// 워커 대신 outbox 에 쌓인 메일을 모두 보낸다
+	for s.h.deliverOutbox() {
+	}
+}
+
+func testPNG(t *testing.T) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, 64, 48))
+	for x := 0; x < 64; x++ {
+		for y := 0; y < 48; y++ {
+			img.Set(x, y, color.RGBA{uint8(x * 4), uint8(y * 5), 128, 255})
+		}
+	}
+	buf := new(bytes.Buffer)
+	if err := png.Encode(buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestHealth(t *testing.T) {
+	// 첫 화면, 상태 확인, 지표, API 문서
+	s := newTestServer(t)
+	s.get("/", "", http.StatusOK)
+	s.get("/healthz", "", http.StatusOK)
+	s.get("/readyz", "", http.StatusOK)
+
+	// 종료를 시작하면 준비되지 않은 것으로 본다
+	s.h.Stop()
+	s.get("/readyz", "", http.StatusServiceUnavailable)
+	s.get("/healthz", "", http.StatusOK)
+
+	s.get("/metrics", "", http.StatusUnauthorized)
+	if body := s.request(http.MethodGet, "/metrics", "metrics", http.StatusOK, nil, "").Body.String(); !strings.Contains(body, "# TYPE") {
+		t.Errorf("GET /metrics 에 지표가 없습니다: %.100s", body)
+	}
+	var spec struct {
+		Paths map[string]interface{} `json:"paths"`
+	}
+	s.decode(s.get("/openapi.json", "", http.StatusOK), &spec)
+	if _, ok := spec.Paths["/sign-up/"]; !ok {
+		t.Errorf("GET /openapi.json 에 /sign-up/ 이 없습니다")
+	}
+	s.get("/docs/", "", http.StatusOK)
+}
+
+func (s *testServer) mailTo(email string) *utility.Request {
+	// 받는 사람에게 보낸 메일: 한 통이어야 한다
+	s.Helper()
+	var found []*utility.Request
+	for _, r := range s.mailer.Sent() {
+		if r.To[0] == email {
+			found = append(found, r)
+		}
+	}
+	if len(found) != 1 {
+		s.Fatalf("%s 에 보낸 메일 = %d통, want 1통", email, len(found))
+	}
+	return found[0]
+}
+
+func (s *testServer) wait() {
+	// 공지사항 메일과 소식지 발송이 끝나기를 기다린다
+	s.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := s.h.Wait(ctx); err != nil {
+		s.Fatal(err)
+	}
+}
+
+func TestMails(t *testing.T) {
+	// 메일에 담긴 주소와 수신 거부 헤더를 실제 라우트로 따라가 본다
+	s := newTestServer(t)
+	admin, adminToken := s.user("admin@example.com", "admin", true, true)
+	_, authorToken := s.user("author@example.com", "author", true, false)
+	muted, _ := s.user("muted@example.com", "muted", false, false)
+	if err := s.h.Users.Update(context.Background(), &model.User{ID: muted.ID, UnsubscribedNotice: true}, "unsubscribed_notice"); err != nil {
+		t.Fatal(err)
+	}
+
+	// 가입 확인 메일: outbox 를 거쳐 회원의 언어로 보낸다
+	s.json(http.MethodPost, "/sign-up/", "", http.StatusCreated, echo.Map{"email": "member@example.com", "nickname": "member", "password": "password", "locale": "en"})
+	if sent := s.mailer.Sent(); len(sent) != 0 {
+		t.Fatalf("outbox 를 비우기 전에 메일을 보냈습니다: %d통", len(sent))
+	}
+	s.deliverOutbox()
+	r := s.mailTo("member@example.com")
+	if r.Subject != "Activate your Something More account" || r.Unsubscribe != "" {
+		t.Errorf("가입 확인 메일 = %q, List-Unsubscribe %q", r.Subject, r.Unsubscribe)
+	}
+	activateURL := "http://example.com/activate/member@example.com"
+	if !strings.Contains(r.Text, activateURL) || !strings.Contains(r.Body, activateURL) {
+		t.Fatalf("가입 확인 메일에 인증 주소가 없습니다: %s", r.Text)
+	}
+	s.get(strings.TrimPrefix(activateURL, "http://example.com"), "", http.StatusMovedPermanently)
+	s.json(http.MethodPost, "/sign-in/", "", http.StatusOK, echo.Map{"email": "member@example.com", "password": "password"})
+
+	// 비밀번호 초기화 메일: 메일에 적힌 새 비밀번호로 로그인할 수 있다
+	s.mailer.Reset()
+	s.get("/reset/member@example.com", "", http.StatusOK)
+	s.deliverOutbox()
+	r = s.mailTo("member@example.com")
+	if r.Subject != "Your Something More password has been reset" {
+		t.Errorf("비밀번호 초기화 메일 제목 = %q", r.Subject)
+	}
+	match := regexp.MustCompile(`Your new password is (\d+)\.`).FindStringSubmatch(r.Text)
+	if match == nil {
+		t.Fatalf("비밀번호 초기화 메일에 새 비밀번호가 없습니다: %s", r.Text)
+	}
+	s.json(http.MethodPost, "/sign-in/", "", http.StatusUnauthorized, echo.Map{"email": "member@example.com", "password": "password"})
+	s.json(http.MethodPost, "/sign-in/", "", http.StatusOK, echo.Map{"email": "member@example.com", "password": match[1]})
+	member, err := s.h.Users.GetByEmail(context.Background(), "member@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 공지사항 메일: 수신 거부한 회원은 빼고, 회원마다 자기 수신 거부 주소를 담는다
+	s.mailer.Reset()
+	s.form(http.MethodPost, "/notice/", adminToken, http.StatusCreated, url.Values{
+		"title":      {"Notice"},
+		"content":    {"<p>Hello</p><script>alert(1)</script>"},
+		"send_email": {"true"}})
+	s.wait()
+	if sent := s.mailer.Sent(); len(sent) != 3 {
+		t.Fatalf("공지사항 메일 = %d통, want 3통", len(sent))
+	}
+	for _, u := range []*model.User{admin, member} {
+		r = s.mailTo(u.Email)
+		unsubscribeURL := "http://example.com/unsubscribe/" + utility.SignValue(Key, u.ID.Hex())
+		if r.Unsubscribe != unsubscribeURL || !strings.Contains(r.Text, unsubscribeURL) {
+			t.Errorf("%s: List-Unsubscribe = %q, want %q", u.Email, r.Unsubscribe, unsubscribeURL)
+		}
+		if strings.Contains(r.Body, "<script>") {
+			t.Errorf("%s: 공지사항 본문을 정리하지 않았습니다: %s", u.Email, r.Body)
+		}
+	}
+	if r = s.mailTo("admin@example.com"); r.Subject != "[썸띵모어 공지] Notice" {
+		t.Errorf("한국어 공지사항 메일 제목 = %q", r.Subject)
+	}
+	r = s.mailTo("member@example.com")
+	if r.Subject != "[Something More] Notice" {
+		t.Errorf("영어 공지사항 메일 제목 = %q", r.Subject)
+	}
+
+	// 메일 프로그램의 수신 거부 버튼(POST)을 누르면 다음 공지사항 메일부터 받지 않는다
+	s.request(http.MethodPost, strings.TrimPrefix(r.Unsubscribe, "http://example.com"), "", http.StatusOK, nil, "")
+	s.mailer.Reset()
+	s.form(http.MethodPost, "/notice/", adminToken, http.StatusCreated, url.Values{
+		"title":      {"Notice 2"},
+		"content":    {"<p>Hello</p>"},
+		"send_email": {"true"}})
+	s.wait()
+	for _, r := range s.mailer.Sent() {
+		if r.To[0] == "member@example.com" || r.To[0] == "muted@example.com" {
+			t.Errorf("수신 거부한 회원에게 공지사항 메일을 보냈습니다: %s", r.To[0])
+		}
+	}
+
+	// 소식지 구독 확인 메일: 확인 주소를 열어야 소식지를 받는다
+	s.mailer.Reset()
+	s.form(http.MethodPost, "/newsletter/subscribe/", "", http.StatusAccepted, url.Values{"email": {"Reader@Example.com"}, "locale": {"en"}})
+	s.deliverOutbox()
+	r = s.mailTo("reader@example.com")
+	if r.Subject != "Confirm your Something More newsletter subscription" || r.Unsubscribe != "" {
+		t.Errorf("구독 확인 메일 = %q, List-Unsubscribe %q", r.Subject, r.Unsubscribe)
+	}
+	confirmURL := regexp.MustCompile(`http://example\.com/newsletter/confirm/\S+`).FindString(r.Text)
+	if confirmURL == "" {
+		t.Fatalf("구독 확인 메일에 확인 주소가 없습니다: %s", r.Text)
+	}
+
+	// 확인하지 않은 구독자에게는 소식지를 보내지 않는다
+	story := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/story/", authorToken, http.StatusCreated, url.Values{"title": {"Story"}, "content": {"<p>Story</p>"}}), story)
+	s.form(http.MethodPatch, "/story/publish/"+story.ID.Hex(), adminToken, http.StatusOK, url.Values{"is_published": {"true"}})
+	s.mailer.Reset()
+	s.form(http.MethodPost, "/newsletter/send/", adminToken, http.StatusAccepted, url.Values{"subject": {"Newsletter"}})
+	s.wait()
+	if sent := s.mailer.Sent(); len(sent) != 0 {
+		t.Fatalf("확인하지 않은 구독자에게 소식지를 보냈습니다: %d통", len(sent))
+	}
+
+	// 소식지: 확인한 구독자에게 스토리 목록과 수신 거부 주소를 담아 보낸다
+	s.get(strings.TrimPrefix(confirmURL, "http://example.com"), "", http.StatusFound)
+	s.form(http.MethodPost, "/newsletter/send/", adminToken, http.StatusAccepted, url.Values{"subject": {"Newsletter"}, "intro": {"<p>Intro</p>"}})
+	s.wait()
+	r = s.mailTo("reader@example.com")
+	if r.Subject != "Newsletter" {
+		t.Errorf("소식지 제목 = %q", r.Subject)
+	}
+	if !strings.Contains(r.Text, SiteURL+"/story/"+story.ID.Hex()) || !strings.Contains(r.Text, "Intro") {
+		t.Errorf("소식지에 소개글이나 스토리 주소가 없습니다: %s", r.Text)
+	}
+	if !strings.HasPrefix(r.Unsubscribe, "http://example.com/newsletter/unsubscribe/") || !strings.Contains(r.Text, r.Unsubscribe) {
+		t.Fatalf("소식지 List-Unsubscribe = %q", r.Unsubscribe)
+	}
+
+	// 수신 거부 버튼을 누르면 다음 소식지를 받지 않는다
+	s.request(http.MethodPost, strings.TrimPrefix(r.Unsubscribe, "http://example.com"), "", http.StatusOK, nil, "")
+	s.mailer.Reset()
+	s.form(http.MethodPost, "/newsletter/send/", adminToken, http.StatusAccepted, url.Values{"subject": {"Newsletter 2"}})
+	s.wait()
+	if sent := s.mailer.Sent(); len(sent) != 0 {
+		t.Errorf("수신 거부한 구독자에게 소식지를 보냈습니다: %d통", len(sent))
+	}
+}
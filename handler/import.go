@@ -0,0 +1,135 @@
+package handler
+
+import (
+	// Default package
+	"bytes"
+	"context"
+	"net/http"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/auth"
+	"github.com/backend/model"
+	"github.com/backend/repository"
+	"github.com/backend/importer"
+	"github.com/backend/metrics"
+)
+
+func (h *Handler) ImportStory(c echo.Context) (err error) {
+	user := auth.CurrentUser(c)
+
+	// Import file validation
+	file, err := c.FormFile("file")
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "가져올 파일을 반드시 첨부해야 합니다",
+		}
+	}
+
+	// 형식을 지정하지 않았다면 확장자로 추측
+	format := c.FormValue("format")
+	if format == "" {
+		format = importer.DetectFormat(file.Filename)
+	}
+
+	// File open
+	src, err := file.Open()
+	if err != nil {
+		return
+	}
+	defer src.Close()
+	metrics.UploadBytes.WithLabelValues("import").Add(float64(file.Size))
+
+	// 파일을 읽어 글 목록 만들기
+	entries, err := importer.Parse(format, src, file.Size)
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "파일을 읽을 수 없습니다: " + err.Error(),
+		}
+	}
+
+	// dry_run=false 를 명시적으로 보낸 경우에만 저장한다
+	report := &importer.Report{
+		Format:  format,
+		DryRun:  c.FormValue("dry_run") != "false",
+		Total:   len(entries),
+		Entries: entries,
+	}
+	if !report.DryRun {
+		if err = h.ImportEntries(c.Request().Context(), user.ID, report); err != nil {
+			return
+		}
+	}
+
+	return c.JSON(http.StatusOK, report)
+}
+
+func (h *Handler) ImportEntries(ctx context.Context, authorID bson.ObjectId, report *importer.Report) (err error) {
+	// 이미지는 저자의 미디어로 저장하므로 미디어 용량에 포함된다
+	u, err := h.Users.Get(ctx, authorID)
+	if err != nil {
+		return
+	}
+
+	// 가져온 글을 저자의 임시 저장 스토리로 저장
+	for _, entry := range report.Entries {
+		// 같은 제목과 작성일자의 스토리가 이미 있다면 이전에 가져온 글로 보고 건너뛴다
+		var count int
+		if count, err = h.Stories.Count(ctx, repository.PostQuery{
+			AuthorID:    authorID,
+			Title:       entry.Title,
+			DateCreated: entry.DateCreated}); err != nil {
+			return
+		}
+		if count > 0 {
+			entry.Warn("이미 가져온 글이므로 건너뜁니다")
+			report.Skipped++
+			continue
+		}
+
+		// 이미지를 정적 파일로 옮기고 본문의 주소를 교체
+		content := entry.Content
+		for _, image := range entry.Images {
+			if !image.Found {
+				continue
+			}
+			if m, err := h.saveImportedImage(ctx, u, image); err != nil {
+				entry.Warn("이미지 %s 를 저장하지 못했습니다: %v", image.Source, err)
+			} else {
+				content = importer.ReplaceImage(content, image.Source, m.Content.URL)
+			}
+		}
+
+		// 발행 승인 전의 스토리로 저장
+		s := &model.Post{
+			ID:           bson.NewObjectId(),
+			AuthorID:     authorID,
+			Title:        entry.Title,
+			Content:      content,
+			DateCreated:  entry.DateCreated,
+			DateModified: "",
+			Category:     entry.Category,
+			IsPublished:  false,
+		}
+		if err = h.Stories.Create(ctx, s); err != nil {
+			return
+		}
+		if err = h.TrackAssets(ctx, s.ID, postAssetURLs(s)...); err != nil {
+			return
+		}
+		report.Imported++
+	}
+	return
+}
+
+func (h *Handler) saveImportedImage(ctx context.Context, u *model.User, image *importer.Image) (*model.Media, error) {
+	// 내려받은 파일도 업로드한 미디어와 같이 실제 이미지인지 확인하고 다시 인코딩해 저장한다
+	data, err := image.Read(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return h.CreateMedia(ctx, u, image.Name, bytes.NewReader(data), "", "")
+}
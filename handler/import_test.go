@@ -0,0 +1,56 @@
+package handler
+
+import (
+	// Default package
+	"bytes"
+	"context"
+	"testing"
+	"net/url"
+	"net/http"
+	"archive/zip"
+	// User package
+	"github.com/backend/importer"
+	"github.com/backend/repository"
+)
+
+func TestImport(t *testing.T) {
+	// 기본은 미리 보기이고, dry_run=false 일 때만 임시 저장 스토리로 저장한다
+	s := newTestServer(t)
+	ctx := context.Background()
+	author, authorToken := s.user("author@example.com", "author", true, false)
+
+	archive := new(bytes.Buffer)
+	w := zip.NewWriter(archive)
+	f, err := w.Create("post.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("---\ntitle: Imported\ndate: 2026-10-01\n---\n본문\n"))
+	w.Close()
+
+	report := new(importer.Report)
+	s.decode(s.upload("/import/", authorToken, http.StatusOK, "posts.zip", archive.Bytes(), nil), report)
+	if !report.DryRun || report.Total != 1 || report.Imported != 0 {
+		t.Errorf("미리 보기 = %+v", report)
+	}
+	if count, _ := s.h.Stories.Count(ctx, repository.PostQuery{AuthorID: author.ID}); count != 0 {
+		t.Errorf("미리 보기에서 스토리 %d편을 저장했습니다", count)
+	}
+	s.upload("/import/", authorToken, http.StatusBadRequest, "post.txt", []byte("본문"), url.Values{"format": {"unknown"}})
+	s.upload("/import/", "", http.StatusBadRequest, "posts.zip", archive.Bytes(), nil)
+
+	s.decode(s.upload("/import/", authorToken, http.StatusOK, "posts.zip", archive.Bytes(), url.Values{"dry_run": {"false"}}), report)
+	if report.DryRun || report.Imported != 1 {
+		t.Errorf("가져오기 = %+v", report)
+	}
+	stories, err := s.h.Stories.List(ctx, repository.PostQuery{AuthorID: author.ID})
+	if err != nil || len(stories) != 1 || stories[0].Title != "Imported" || stories[0].IsPublished {
+		t.Fatalf("가져온 스토리 = %v, %v, want 임시 저장 스토리 1편", stories, err)
+	}
+
+	// 같은 글을 다시 가져오면 건너뛴다
+	s.decode(s.upload("/import/", authorToken, http.StatusOK, "posts.zip", archive.Bytes(), url.Values{"dry_run": {"false"}}), report)
+	if report.Imported != 0 || report.Skipped != 1 {
+		t.Errorf("다시 가져오기 = %+v, want 1편 건너뜀", report)
+	}
+}
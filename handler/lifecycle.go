@@ -0,0 +1,134 @@
+package handler
+
+import (
+	// Default package
+	"time"
+	"errors"
+	"context"
+	"net/http"
+	// Third Party package
+	"github.com/labstack/echo"
+	// User package
+	"github.com/backend/metrics"
+)
+
+const (
+	ReadyTimeout      = 5 * time.Second // 준비 상태 확인 하나에 허용하는 시간
+	MailCheckInterval = time.Minute     // 메일 서버 확인 결과를 재사용하는 시간: 매번 SMTP 에 접속하지 않는다
+)
+
+var errShuttingDown = errors.New("서버를 종료하는 중입니다")
+
+func (h *Handler) Healthz(c echo.Context) (err error) {
+	// 프로세스가 살아 있으면 항상 200
+	return c.JSON(http.StatusOK, echo.Map{"status": "ok"})
+}
+
+func (h *Handler) Readyz(c echo.Context) (err error) {
+	// 요청을 받을 수 있는지 확인: 하나라도 실패하면 503
+	status := http.StatusOK
+	checks := echo.Map{}
+	report := func(name string, err error) {
+		if err != nil {
+			status = http.StatusServiceUnavailable
+			checks[name] = err.Error()
+			return
+		}
+		checks[name] = "ok"
+	}
+
+	// 종료 중에는 로드밸런서가 새 요청을 보내지 않도록 준비되지 않은 것으로 알린다
+	if !h.isRunning() {
+		report("server", errShuttingDown)
+	}
+
+	ctx := c.Request().Context()
+	report("mongo", withTimeout(ctx, h.Database.Ping))
+	report("storage", withTimeout(ctx, h.Storage.Ping))
+	report("mail", h.pingMailer(ctx))
+
+	if status != http.StatusOK {
+		return c.JSON(status, echo.Map{"status": "unavailable", "checks": checks})
+	}
+	return c.JSON(status, echo.Map{"status": "ok", "checks": checks})
+}
+
+func (h *Handler) pingMailer(ctx context.Context) error {
+	// 최근에 확인한 결과가 있으면 그대로 쓴다
+	h.mailCheck.Lock()
+	defer h.mailCheck.Unlock()
+	if time.Since(h.mailCheck.checked) < MailCheckInterval {
+		return h.mailCheck.err
+	}
+	h.mailCheck.err = withTimeout(ctx, h.Mailer.Ping)
+	h.mailCheck.checked = time.Now()
+	return h.mailCheck.err
+}
+
+func withTimeout(ctx context.Context, fn func(ctx context.Context) error) error {
+	// fn 이 ctx 를 지키지 않더라도 ReadyTimeout 이 지나면 기다리지 않는다
+	ctx, cancel := context.WithTimeout(ctx, ReadyTimeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Handler) Stop() {
+	// 종료 시작: 실시간 이벤트 연결을 끊고 백그라운드 작업이 새 일을 시작하지 않도록 알린다
+	h.stopping()
+	h.stopOnce.Do(func() {
+		close(h.quit)
+	})
+}
+
+func (h *Handler) Wait(ctx context.Context) error {
+	// 진행 중인 백그라운드 작업(메일 발송, 파일 정리 등)이 끝나기를 ctx 가 끝날 때까지 기다린다
+	done := make(chan struct{})
+	go func() {
+		h.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (h *Handler) isRunning() bool {
+	select {
+	case <-h.stopping():
+		return false
+	default:
+		return true
+	}
+}
+
+func (h *Handler) stopping() <-chan struct{} {
+	h.quitOnce.Do(func() {
+		h.quit = make(chan struct{})
+	})
+	return h.quit
+}
+
+func (h *Handler) background(job string, fn func()) {
+	// 종료할 때 기다려야 하는 작업은 이 함수로 실행한다: 진행 중인 작업 수를 job 별로 센다
+	h.workers.Add(1)
+	jobs := metrics.BackgroundJobs.WithLabelValues(job)
+	jobs.Inc()
+	go func() {
+		defer h.workers.Done()
+		defer jobs.Dec()
+		fn()
+	}()
+}
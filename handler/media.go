@@ -0,0 +1,299 @@
+package handler
+
+import (
+	// Default package
+	"io"
+	"fmt"
+	"html"
+	"time"
+	"bytes"
+	"errors"
+	"context"
+	"strconv"
+	"net/http"
+	"log/slog"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/auth"
+	"github.com/backend/model"
+	"github.com/backend/repository"
+	"github.com/backend/metrics"
+	"github.com/backend/utility"
+)
+
+const (
+	// 회원별 미디어 저장 용량 기본값: 관리자가 회원마다 바꿀 수 있다
+	DefaultMediaQuota int64 = 200 << 20
+	// 관리자가 정할 수 있는 최대 용량 (MB)
+	MaxMediaQuotaMB int64 = 100 << 10
+)
+
+var ErrMediaQuota = errors.New("미디어 저장 공간이 부족합니다")
+
+func (h *Handler) UploadMedia(c echo.Context) (err error) {
+	// Find user in database
+	user := auth.CurrentUser(c)
+	u, err := h.Users.Get(c.Request().Context(), user.ID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
+		return
+	}
+
+	// File open
+	file, err := c.FormFile("file")
+	if err != nil {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "이미지 파일을 첨부해야 합니다",
+		}
+	}
+	src, err := file.Open()
+	if err != nil {
+		return
+	}
+	defer src.Close()
+	metrics.UploadBytes.WithLabelValues("media").Add(float64(file.Size))
+
+	m, err := h.CreateMedia(c.Request().Context(), u, file.Filename, src, c.FormValue("alt"), c.FormValue("caption"))
+	switch err {
+	case nil:
+	case utility.ErrUnsupportedImage:
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	case utility.ErrImageTooLarge, ErrMediaQuota:
+		return &echo.HTTPError{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: err.Error(),
+		}
+	default:
+		return
+	}
+
+	m.Markup = mediaMarkup(m)
+	return c.JSON(http.StatusCreated, m)
+}
+
+func (h *Handler) CreateMedia(ctx context.Context, u *model.User, fileName string, src io.Reader, alt string, caption string) (m *model.Media, err error) {
+	// 이미지 확인 후 본문용, 목록용 크기로 다시 인코딩
+	// 실제 내용이 이미지가 아니면 ErrUnsupportedImage, 너무 크면 ErrImageTooLarge, 용량이 부족하면 ErrMediaQuota
+	images, err := utility.ProcessImage(src, utility.MediaSpecs)
+	if err != nil {
+		return
+	}
+
+	// 용량 확인
+	var size int64
+	for _, img := range images {
+		size += int64(len(img.Data))
+	}
+	usage, err := h.Media.Usage(ctx, u.ID)
+	if err != nil {
+		return
+	}
+	if usage+size > mediaQuota(u) {
+		return nil, ErrMediaQuota
+	}
+
+	m = &model.Media{
+		ID:          bson.NewObjectId(),
+		OwnerID:     u.ID,
+		FileName:    fileName,
+		Size:        size,
+		Alt:         alt,
+		Caption:     caption,
+		DateCreated: time.Now(),
+	}
+
+	// 저장소에 저장
+	for _, img := range images {
+		assetURL, err := h.SaveAsset(ctx, u.ID, img.Name+img.Extension, bytes.NewReader(img.Data))
+		if err != nil {
+			return nil, err
+		}
+
+		variant := &m.Content
+		if img.Name == "card" {
+			variant = &m.Card
+		}
+		if img.ContentType == "image/webp" {
+			variant.WebP = assetURL
+		} else {
+			variant.URL, variant.Width, variant.Height = assetURL, img.Width, img.Height
+		}
+	}
+
+	if err = h.Media.Create(ctx, m); err != nil {
+		return nil, err
+	}
+
+	// 미디어가 쓰는 파일 기록
+	if err := h.TrackAssets(ctx, m.ID, mediaAssetURLs(m)...); err != nil {
+		slog.ErrorContext(ctx, "파일 참조를 기록하지 못했습니다", "ref_id", m.ID.Hex(), "error", err)
+	}
+	return m, nil
+}
+
+func (h *Handler) ListMedia(c echo.Context) (err error) {
+	user := auth.CurrentUser(c)
+
+	// Get query params
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	// Default pagination
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = 30
+	}
+
+	// 자신이 올린 미디어만 조회
+	// 생성일자 역순으로 정렬
+	media, err := h.Media.List(c.Request().Context(), user.ID, (page-1)*limit, limit)
+	if err != nil {
+		return
+	}
+	if media == nil {
+		media = []*model.Media{}
+	}
+	for _, m := range media {
+		m.Markup = mediaMarkup(m)
+	}
+
+	return c.JSON(http.StatusOK, media)
+}
+
+func (h *Handler) PatchMedia(c echo.Context) (err error) {
+	user := auth.CurrentUser(c)
+
+	// Get media ID
+	mediaID := c.Param("media_id")
+	if !bson.IsObjectIdHex(mediaID) {
+		return echo.ErrNotFound
+	}
+
+	// 대체 텍스트와 설명 수정: 자신의 미디어만 수정할 수 있음
+	m, err := h.Media.UpdateOwned(c.Request().Context(), &model.Media{
+		ID:      bson.ObjectIdHex(mediaID),
+		OwnerID: user.ID,
+		Alt:     c.FormValue("alt"),
+		Caption: c.FormValue("caption"),
+	}, "alt", "caption")
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
+		return
+	}
+
+	m.Markup = mediaMarkup(m)
+	return c.JSON(http.StatusOK, m)
+}
+
+func (h *Handler) DestroyMedia(c echo.Context) (err error) {
+	user := auth.CurrentUser(c)
+
+	// Get media ID
+	mediaID := c.Param("media_id")
+	if !bson.IsObjectIdHex(mediaID) {
+		return echo.ErrNotFound
+	}
+
+	// 자신의 미디어만 삭제할 수 있음
+	m, err := h.Media.DeleteOwned(c.Request().Context(), user.ID, bson.ObjectIdHex(mediaID))
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
+		return
+	}
+
+	// 파일 참조 해제: 글에 삽입된 이미지는 남고, 아무도 쓰지 않으면 정리 작업이 지운다
+	if err := h.TrackAssets(c.Request().Context(), m.ID); err != nil {
+		slog.ErrorContext(c.Request().Context(), "파일 참조를 해제하지 못했습니다", "ref_id", m.ID.Hex(), "error", err)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) ListMediaUsage(c echo.Context) (err error) {
+	// 회원별 사용량 합계
+	usages, err := h.Media.ListUsage(c.Request().Context())
+	if err != nil {
+		return
+	}
+	if usages == nil {
+		usages = []*model.MediaUsage{}
+	}
+
+	// 회원 정보와 용량 채우기
+	ids := make([]bson.ObjectId, len(usages))
+	for i, usage := range usages {
+		ids[i] = usage.UserID
+	}
+	users, err := h.Users.List(c.Request().Context(), repository.UserQuery{IDs: ids})
+	if err != nil {
+		return
+	}
+	userMap := make(map[bson.ObjectId]*model.User)
+	for _, u := range users {
+		userMap[u.ID] = u
+	}
+	for _, usage := range usages {
+		usage.Quota = DefaultMediaQuota
+		if u, ok := userMap[usage.UserID]; ok {
+			usage.Email, usage.Nickname, usage.Quota = u.Email, u.Nickname, mediaQuota(u)
+		}
+	}
+
+	return c.JSON(http.StatusOK, usages)
+}
+
+func (h *Handler) PatchMediaQuota(c echo.Context) (err error) {
+	// Validation: MB 단위, 0 이면 기본 용량으로 되돌린다
+	quota, err := strconv.ParseInt(c.FormValue("quota_mb"), 10, 64)
+	if err != nil || quota < 0 || quota > MaxMediaQuotaMB {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("용량은 0 이상 %d 이하의 MB 단위 정수여야 합니다", MaxMediaQuotaMB),
+		}
+	}
+
+	u, err := h.Users.GetByEmail(c.Request().Context(), c.Param("user_email"))
+	if err == nil {
+		u.MediaQuota = quota << 20
+		err = h.Users.Update(c.Request().Context(), u, "media_quota")
+	}
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
+		return
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func mediaQuota(u *model.User) int64 {
+	if u.MediaQuota > 0 {
+		return u.MediaQuota
+	}
+	return DefaultMediaQuota
+}
+
+func mediaMarkup(m *model.Media) string {
+	// 스토리, 자유게시판 본문에 붙여 넣을 HTML
+	img := fmt.Sprintf(`<img src="%s" alt="%s" width="%d" height="%d"/>`,
+		html.EscapeString(m.Content.URL), html.EscapeString(m.Alt), m.Content.Width, m.Content.Height)
+	if m.Caption == "" {
+		return img
+	}
+	return "<figure>" + img + "<figcaption>" + html.EscapeString(m.Caption) + "</figcaption></figure>"
+}
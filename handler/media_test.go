@@ -0,0 +1,107 @@
+package handler
+
+import (
+	// Default package
+	"time"
+	"context"
+	"strconv"
+	"strings"
+	"testing"
+	"net/url"
+	"net/http"
+	// User package
+	"github.com/backend/model"
+)
+
+func TestPatchMediaQuota(t *testing.T) {
+	// 용량은 MB 단위로 받아 바이트로 저장하고, 최대 용량을 넘거나 음수면 거부한다
+	s := newTestServer(t)
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	author, authorToken := s.user("author@example.com", "author", true, false)
+
+	for _, quota := range []string{"-1", strconv.FormatInt(MaxMediaQuotaMB+1, 10), "9223372036854775807", "1.5", ""} {
+		s.form(http.MethodPatch, "/media/quota/author@example.com", adminToken, http.StatusBadRequest, url.Values{"quota_mb": {quota}})
+	}
+	s.form(http.MethodPatch, "/media/quota/author@example.com", authorToken, http.StatusUnauthorized, url.Values{"quota_mb": {"1"}})
+	s.form(http.MethodPatch, "/media/quota/nobody@example.com", adminToken, http.StatusNotFound, url.Values{"quota_mb": {"1"}})
+
+	s.form(http.MethodPatch, "/media/quota/author@example.com", adminToken, http.StatusOK, url.Values{"quota_mb": {strconv.FormatInt(MaxMediaQuotaMB, 10)}})
+	u, err := s.h.Users.Get(context.Background(), author.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.MediaQuota != MaxMediaQuotaMB<<20 {
+		t.Errorf("media_quota = %d, want %d", u.MediaQuota, MaxMediaQuotaMB<<20)
+	}
+
+	// 용량을 넘는 업로드는 거부한다
+	if err := s.h.Users.Update(context.Background(), &model.User{ID: author.ID, MediaQuota: 1}, "media_quota"); err != nil {
+		t.Fatal(err)
+	}
+	s.upload("/media/", authorToken, http.StatusRequestEntityTooLarge, "image.png", testPNG(t), nil)
+
+	// 0 이면 기본 용량으로 되돌린다
+	s.form(http.MethodPatch, "/media/quota/author@example.com", adminToken, http.StatusOK, url.Values{"quota_mb": {"0"}})
+	s.upload("/media/", authorToken, http.StatusCreated, "image.png", testPNG(t), nil)
+}
+
+func TestMedia(t *testing.T) {
+	// 자신의 미디어만 고치고 지울 수 있고, 글에서 쓰는 이미지는 미디어를 지워도 남는다
+	s := newTestServer(t)
+	ctx := context.Background()
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	author, authorToken := s.user("author@example.com", "author", true, false)
+	_, memberToken := s.user("member@example.com", "member", false, false)
+
+	m := new(model.Media)
+	s.decode(s.upload("/media/", authorToken, http.StatusCreated, "image.png", testPNG(t), url.Values{"alt": {"그림"}}), m)
+	s.upload("/media/", authorToken, http.StatusBadRequest, "image.png", []byte("not an image"), nil)
+	if m.Content.URL == "" || m.Card.URL == "" || m.Alt != "그림" || !strings.Contains(m.Markup, `alt="그림"`) {
+		t.Errorf("POST /media/ = %+v", m)
+	}
+	s.get(strings.TrimPrefix(m.Content.URL, "http://example.com"), "", http.StatusOK)
+
+	var media []*model.Media
+	s.decode(s.get("/media/", authorToken, http.StatusOK), &media)
+	if len(media) != 1 || media[0].ID != m.ID {
+		t.Errorf("GET /media/ = %v, want %s", media, m.ID.Hex())
+	}
+	s.decode(s.get("/media/", memberToken, http.StatusOK), &media)
+	if len(media) != 0 {
+		t.Errorf("GET /media/ 에 남의 미디어가 있습니다: %v", media)
+	}
+
+	s.form(http.MethodPatch, "/media/"+m.ID.Hex(), memberToken, http.StatusNotFound, url.Values{"alt": {"남의 미디어"}})
+	s.form(http.MethodPatch, "/media/"+m.ID.Hex(), authorToken, http.StatusOK, url.Values{"alt": {"새 그림"}, "caption": {"설명"}})
+	s.decode(s.get("/media/", authorToken, http.StatusOK), &media)
+	if len(media) != 1 || media[0].Alt != "새 그림" || media[0].Caption != "설명" {
+		t.Errorf("PATCH /media/ 를 저장하지 않았습니다: %v", media)
+	}
+
+	var usages []*model.MediaUsage
+	s.decode(s.get("/media/usage/", adminToken, http.StatusOK), &usages)
+	if len(usages) != 1 || usages[0].UserID != author.ID || usages[0].Size != m.Size || usages[0].Quota != DefaultMediaQuota {
+		t.Errorf("GET /media/usage/ = %v", usages)
+	}
+
+	// 글에 넣은 이미지
+	s.form(http.MethodPost, "/story/", authorToken, http.StatusCreated, url.Values{
+		"title": {"Story"}, "content": {`<p>본문</p><img src="` + m.Content.URL + `">`}})
+	s.request(http.MethodDelete, "/media/"+m.ID.Hex(), memberToken, http.StatusNotFound, nil, "")
+	s.request(http.MethodDelete, "/media/"+m.ID.Hex(), authorToken, http.StatusOK, nil, "")
+	s.request(http.MethodDelete, "/media/"+m.ID.Hex(), authorToken, http.StatusNotFound, nil, "")
+	if usage, err := s.h.Media.Usage(ctx, author.ID); err != nil || usage != 0 {
+		t.Errorf("지운 미디어의 용량 = %d, %v", usage, err)
+	}
+	orphaned, err := s.h.Assets.ListOrphaned(ctx, time.Now().Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	released := map[string]bool{}
+	for _, a := range orphaned {
+		released[s.store.URL(a.Key)] = true
+	}
+	if released[m.Content.URL] || !released[m.Content.WebP] {
+		t.Errorf("참조 없는 파일 = %v: 글에 넣은 이미지는 남고 나머지만 풀려야 합니다", released)
+	}
+}
@@ -0,0 +1,260 @@
+package handler
+
+import (
+	// Default package
+	"time"
+	"context"
+	"strings"
+	"strconv"
+	"net/http"
+	"log/slog"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/logging"
+	"github.com/backend/metrics"
+	"github.com/backend/utility"
+	"github.com/backend/repository"
+)
+
+// 확인 메일을 다시 보내기까지 기다리는 시간: 구독 신청으로 다른 사람의 메일함을 채우지 못하게 한다
+const NewsletterConfirmCooldown = 15 * time.Minute
+
+// 소식지 토큰의 용도 구분: 같은 구독자라도 확인용과 구독 취소용 토큰은 서로 다르다
+const (
+	newsletterConfirm     = "confirm:"
+	newsletterUnsubscribe = "unsubscribe:"
+)
+
+func (h *Handler) SubscribeNewsletter(c echo.Context) (err error) {
+	// Validation: 대소문자만 다른 주소는 같은 구독자로 본다
+	email := strings.ToLower(strings.TrimSpace(c.FormValue("email")))
+	if email == "" || !strings.Contains(email, "@") {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "이메일 주소가 올바르지 않습니다",
+		}
+	}
+
+	// 처음 신청한 경우에만 구독자를 만든다
+	sub, err := h.Subscribers.GetOrCreate(c.Request().Context(), &model.Subscriber{
+		Email:       email,
+		Locale:      utility.MailLocale(c.FormValue("locale")),
+		DateCreated: time.Now(),
+	})
+	if err != nil {
+		return
+	}
+
+	// 이미 구독 중이라면 확인 메일을 다시 보내지 않는다
+	if sub.IsConfirmed {
+		return c.NoContent(http.StatusOK)
+	}
+
+	// 최근에 확인 메일을 보냈다면 다시 보내지 않는다: 응답은 보낸 경우와 같다
+	if err = h.Subscribers.MarkConfirmSent(c.Request().Context(), sub.ID, time.Now(), NewsletterConfirmCooldown); err != nil {
+		if err == repository.ErrNotFound {
+			return c.NoContent(http.StatusAccepted)
+		}
+		return
+	}
+
+	// Sending Email
+	confirmURL := BaseURL(c) + "/newsletter/confirm/" + utility.SignValue(Key, newsletterConfirm+sub.ID.Hex())
+	r, err := utility.NewNewsletterConfirmEmail(sub.Email, sub.Locale, confirmURL)
+	if err != nil {
+		return
+	}
+	if err = h.Enqueue(c.Request().Context(), model.OutboxNewsletterConfirm, r); err != nil {
+		return
+	}
+
+	return c.NoContent(http.StatusAccepted)
+}
+
+func (h *Handler) ConfirmNewsletter(c echo.Context) (err error) {
+	// 확인 메일의 링크: 서명된 토큰으로 구독자를 찾는다
+	subscriberID, ok := subscriberFromToken(c.Param("token"), newsletterConfirm)
+	if !ok {
+		return echo.ErrNotFound
+	}
+
+	if err = h.Subscribers.Confirm(c.Request().Context(), subscriberID, time.Now()); err != nil && err != repository.ErrNotFound {
+		return
+	}
+
+	// 메인 페이지로 리다이렉트
+	return c.Redirect(http.StatusFound, SiteURL)
+}
+
+func (h *Handler) UnsubscribeNewsletter(c echo.Context) (err error) {
+	// 소식지 하단의 구독 취소 링크: GET 은 확인 화면만 보여주고, 확인 화면의 버튼이나 원클릭 구독 취소(RFC 8058)의 POST 로 취소한다
+	subscriberID, ok := subscriberFromToken(c.Param("token"), newsletterUnsubscribe)
+	if !ok {
+		return echo.ErrNotFound
+	}
+	if c.Request().Method == http.MethodGet {
+		return confirmPage(c, "소식지 구독 취소", "소식지를 더 이상 받지 않으시겠습니까?", "구독 취소")
+	}
+
+	if err = h.Subscribers.Delete(c.Request().Context(), subscriberID); err != nil && err != repository.ErrNotFound {
+		return
+	}
+
+	return c.String(http.StatusOK, "소식지 구독이 취소되었습니다\n")
+}
+
+func (h *Handler) SendNewsletter(c echo.Context) (err error) {
+	// Validation
+	subject := c.FormValue("subject")
+	if subject == "" {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "소식지 제목을 반드시 입력해야 합니다",
+		}
+	}
+
+	// 최근 발행된 스토리: 기본값은 10편
+	limit, _ := strconv.Atoi(c.FormValue("limit"))
+	if limit == 0 {
+		limit = 10
+	}
+	stories, err := h.FindPublishedStories(c, 1, limit)
+	if err != nil {
+		return
+	}
+	if len(stories) == 0 {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "소식지에 실을 스토리가 없습니다",
+		}
+	}
+
+	issue := &model.NewsletterIssue{
+		ID:          bson.NewObjectId(),
+		Subject:     subject,
+		Intro:       c.FormValue("intro"),
+		Status:      model.BroadcastPending,
+		DateCreated: time.Now(),
+	}
+	for _, story := range stories {
+		issue.StoryIDs = append(issue.StoryIDs, story.ID)
+	}
+
+	if err = h.Newsletters.Create(c.Request().Context(), issue); err != nil {
+		return
+	}
+
+	// 요청이 끝난 뒤에도 발송은 계속되어야 하므로 필요한 값은 미리 복사해 둔다
+	ctx, sending, baseURL := logging.Detach(c.Request().Context()), *issue, BaseURL(c)
+	h.background("newsletter", func() { h.deliverNewsletter(ctx, sending, stories, baseURL) })
+
+	return c.JSON(http.StatusAccepted, issue)
+}
+
+func (h *Handler) ListNewsletter(c echo.Context) (err error) {
+	// 발송한 소식지와 발송 통계
+	issues, err := h.Newsletters.List(c.Request().Context())
+	if err != nil {
+		return
+	}
+
+	return c.JSON(http.StatusOK, issues)
+}
+
+func (h *Handler) deliverNewsletter(ctx context.Context, issue model.NewsletterIssue, stories []*model.Post, baseURL string) {
+	logger := slog.With("job", "newsletter", "issue_id", issue.ID.Hex())
+
+	// 구독을 확인한 구독자에게만 발송
+	subscribers, err := h.Subscribers.ListConfirmed(ctx)
+	if err != nil {
+		logger.ErrorContext(ctx, "소식지 구독자를 찾지 못했습니다", "error", err)
+		return
+	}
+
+	issue.Total = len(subscribers)
+	issue.Status = model.BroadcastSending
+	if err := h.Newsletters.Update(ctx, &issue, "status", "total"); err != nil {
+		logger.ErrorContext(ctx, "발송 상태를 저장하지 못했습니다", "error", err)
+	}
+
+	// 발송 속도 제한: 공지사항 메일과 같은 간격을 사용
+	ticker := time.NewTicker(BroadcastInterval)
+	defer ticker.Stop()
+
+	var remaining []*model.Subscriber
+loop:
+	for i, sub := range subscribers {
+		// 서버가 종료되면 남은 구독자는 outbox 에 넘긴다
+		select {
+		case <-h.stopping():
+			remaining = subscribers[i:]
+			break loop
+		case <-ticker.C:
+		}
+
+		unsubscribeURL := baseURL + "/newsletter/unsubscribe/" + utility.SignValue(Key, newsletterUnsubscribe+sub.ID.Hex())
+		if err := h.sendNewsletterDigest(ctx, sub, issue, stories, unsubscribeURL); err != nil {
+			logger.WarnContext(ctx, "소식지를 보내지 못했습니다", "subscriber_id", sub.ID.Hex(), "error", err)
+			issue.Failed++
+		} else {
+			issue.Sent++
+		}
+
+		// 배치 단위로 진행 상황 저장
+		if (i+1)%BroadcastBatchSize == 0 {
+			if err := h.Newsletters.Update(ctx, &issue, "sent", "failed"); err != nil {
+				logger.ErrorContext(ctx, "발송 상태를 저장하지 못했습니다", "error", err)
+			}
+		}
+	}
+
+	for _, sub := range remaining {
+		unsubscribeURL := baseURL + "/newsletter/unsubscribe/" + utility.SignValue(Key, newsletterUnsubscribe+sub.ID.Hex())
+		r, err := utility.NewNewsletterDigest(sub.Email, sub.Locale, issue.Subject, issue.Intro, stories, SiteURL, unsubscribeURL)
+		if err == nil {
+			err = h.Enqueue(ctx, model.OutboxNewsletter, r)
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "소식지를 outbox 에 넘기지 못했습니다", "subscriber_id", sub.ID.Hex(), "error", err)
+			issue.Failed++
+			continue
+		}
+		issue.Queued++
+	}
+
+	// 발송 완료: 중단했으면 남은 소식지는 outbox 가 재시작 후 보낸다
+	now := time.Now()
+	issue.Status = model.BroadcastDone
+	if remaining != nil {
+		issue.Status = model.BroadcastInterrupted
+	}
+	issue.DateFinished = &now
+	if err := h.Newsletters.Update(ctx, &issue, "status", "sent", "failed", "queued", "date_finished"); err != nil {
+		logger.ErrorContext(ctx, "발송 상태를 저장하지 못했습니다", "error", err)
+	}
+	logger.InfoContext(ctx, "소식지 발송을 마쳤습니다", "status", issue.Status, "sent", issue.Sent, "failed", issue.Failed, "queued", issue.Queued)
+}
+
+func (h *Handler) sendNewsletterDigest(ctx context.Context, sub *model.Subscriber, issue model.NewsletterIssue, stories []*model.Post, unsubscribeURL string) (err error) {
+	defer func() { metrics.ObserveMail("newsletter", err) }()
+	r, err := utility.NewNewsletterDigest(sub.Email, sub.Locale, issue.Subject, issue.Intro, stories, SiteURL, unsubscribeURL)
+	if err != nil {
+		return
+	}
+	return h.Mailer.Send(ctx, r)
+}
+
+func subscriberFromToken(token string, purpose string) (bson.ObjectId, bool) {
+	value, ok := utility.VerifySignedValue(Key, token)
+	if !ok || !strings.HasPrefix(value, purpose) {
+		return "", false
+	}
+	id := strings.TrimPrefix(value, purpose)
+	if !bson.IsObjectIdHex(id) {
+		return "", false
+	}
+	return bson.ObjectIdHex(id), true
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	// Default package
+	"context"
+	"testing"
+	"net/url"
+	"net/http"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/utility"
+)
+
+func TestNewsletter(t *testing.T) {
+	// 확인한 구독자에게만 보내고, 구독을 취소하면 구독자를 지운다
+	s := newTestServer(t)
+	ctx := context.Background()
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	_, authorToken := s.user("author@example.com", "author", true, false)
+
+	// 대소문자만 다른 주소는 같은 구독자다: 확인 메일은 한 번만 보낸다
+	s.form(http.MethodPost, "/newsletter/subscribe/", "", http.StatusAccepted, url.Values{"email": {"Reader@Example.com"}})
+	s.form(http.MethodPost, "/newsletter/subscribe/", "", http.StatusAccepted, url.Values{"email": {"reader@example.com"}})
+	s.form(http.MethodPost, "/newsletter/subscribe/", "", http.StatusBadRequest, url.Values{"email": {"reader"}})
+	s.deliverOutbox()
+	s.mailTo("reader@example.com")
+	sub, err := s.h.Subscribers.GetOrCreate(ctx, &model.Subscriber{Email: "reader@example.com"})
+	if err != nil || sub.IsConfirmed {
+		t.Fatalf("구독자 = %+v, %v, want 확인하지 않은 구독자", sub, err)
+	}
+
+	// 구독 확인: 다른 용도로 서명한 주소는 받지 않는다
+	s.get("/newsletter/confirm/"+utility.SignValue(Key, newsletterUnsubscribe+sub.ID.Hex()), "", http.StatusNotFound)
+	s.get("/newsletter/confirm/"+utility.SignValue(Key, newsletterConfirm+sub.ID.Hex()), "", http.StatusFound)
+	if confirmed, err := s.h.Subscribers.ListConfirmed(ctx); err != nil || len(confirmed) != 1 || confirmed[0].ID != sub.ID {
+		t.Fatalf("ListConfirmed = %v, %v", confirmed, err)
+	}
+	s.form(http.MethodPost, "/newsletter/subscribe/", "", http.StatusOK, url.Values{"email": {"reader@example.com"}})
+
+	// 실을 스토리가 없으면 보내지 않는다
+	s.form(http.MethodPost, "/newsletter/send/", adminToken, http.StatusBadRequest, url.Values{"subject": {"이번 달 소식"}})
+	story := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/story/", authorToken, http.StatusCreated, url.Values{"title": {"Story"}, "content": {"<p>본문</p>"}}), story)
+	s.form(http.MethodPatch, "/story/publish/"+story.ID.Hex(), adminToken, http.StatusOK, url.Values{"is_published": {"true"}})
+	s.form(http.MethodPost, "/newsletter/send/", adminToken, http.StatusBadRequest, url.Values{"subject": {""}})
+	s.form(http.MethodPost, "/newsletter/send/", authorToken, http.StatusUnauthorized, url.Values{"subject": {"이번 달 소식"}})
+	s.form(http.MethodPost, "/newsletter/send/", adminToken, http.StatusAccepted, url.Values{"subject": {"이번 달 소식"}})
+	s.wait()
+
+	var issues []*model.NewsletterIssue
+	s.decode(s.get("/newsletter/", adminToken, http.StatusOK), &issues)
+	if len(issues) != 1 {
+		t.Fatalf("GET /newsletter/ = %v, want 소식지 1건", issues)
+	}
+	if issue := issues[0]; len(issue.StoryIDs) != 1 || issue.StoryIDs[0] != story.ID || issue.Status != model.BroadcastDone || issue.Total != 1 || issue.Sent != 1 {
+		t.Errorf("소식지 발송 기록 = %+v", issue)
+	}
+
+	// 구독 취소: GET 은 확인 화면만 보여준다
+	unsubscribe := "/newsletter/unsubscribe/" + utility.SignValue(Key, newsletterUnsubscribe+sub.ID.Hex())
+	s.get("/newsletter/unsubscribe/"+utility.SignValue(Key, newsletterConfirm+sub.ID.Hex()), "", http.StatusNotFound)
+	s.get(unsubscribe, "", http.StatusOK)
+	if confirmed, _ := s.h.Subscribers.ListConfirmed(ctx); len(confirmed) != 1 {
+		t.Error("GET 으로 구독을 취소했습니다")
+	}
+	s.request(http.MethodPost, unsubscribe, "", http.StatusOK, nil, "")
+	if confirmed, _ := s.h.Subscribers.ListConfirmed(ctx); len(confirmed) != 0 {
+		t.Errorf("구독을 취소한 구독자가 남아 있습니다: %v", confirmed)
+	}
+	s.request(http.MethodPost, unsubscribe, "", http.StatusOK, nil, "")
+}
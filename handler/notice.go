@@ -2,32 +2,26 @@ package handler
 
 import (
 	// Default package
+	"time"
 	"strconv"
 	"net/http"
+	"log/slog"
 	// Third Party package
 	"github.com/labstack/echo"
 	"github.com/globalsign/mgo/bson"
 	// User package
+	"github.com/backend/auth"
 	"github.com/backend/model"
 	"github.com/backend/utility"
 )
 
 func (h *Handler) CreateNotice(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
-		return
-	}
-
-	// Validate admin
-	if err = utility.AdminValidation(c); err != nil {
-		return
-	}
+	user := auth.CurrentUser(c)
 
 	// Bind notice object
 	n := &model.Post{
 		ID:       bson.NewObjectId(),
-		AuthorID: bson.ObjectIdHex(userID), // 저자를 표시하기 위해 userID 을 삽입
+		AuthorID: user.ID, // 저자를 표시하기 위해 회원 ID 를 삽입
 	}
 
 	if err = c.Bind(n); err != nil {
@@ -46,13 +40,44 @@ func (h *Handler) CreateNotice(c echo.Context) (err error) {
 	n.DateModified = ""
 	n.IsPublished = true
 
+	// 상단 고정 및 게시 종료 일시
+	if _, err = bindNoticeOptions(c, n); err != nil {
+		return
+	}
+
+	// 메일 발송 옵션
+	sendEmail, _ := strconv.ParseBool(c.FormValue("send_email"))
+	role, err := BroadcastRole(c)
+	if err != nil {
+		return
+	}
+
 	// Save Post
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(NOTICE).Insert(n); err != nil {
+	if err = h.Notices.Create(c.Request().Context(), n); err != nil {
 		return
 	}
 
+	// 글이 쓰는 파일 기록
+	h.trackPost(c, n)
+
+	// 접속 중인 방문자에게 새 공지사항 알림
+	h.Events.Publish(Event{
+		Type: EventNotice,
+		Data: echo.Map{"id": n.ID, "title": n.Title, "is_pinned": n.IsPinned},
+	})
+
+	// 회원들에게 새 공지사항 알림
+	if err := h.NotifyAll(c.Request().Context(), model.NotificationNotice, "새 공지사항: "+n.Title, n.ID); err != nil {
+		slog.ErrorContext(c.Request().Context(), "알림을 저장하지 못했습니다", "notice_id", n.ID.Hex(), "error", err)
+	}
+
+	// 회원들에게 공지사항 메일 발송
+	if sendEmail {
+		if _, err = h.BroadcastNotice(c, n, role); err != nil {
+			return
+		}
+	}
+
 	return c.JSON(http.StatusCreated, n)
 }
 
@@ -71,38 +96,45 @@ func (h *Handler) ListNotice(c echo.Context) (err error) {
 	}
 
 	// List notices from database
-	var notices []*model.Post
-
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(NOTICE).
-		Find(nil).
-		Select(bson.M{"content": 0}). // 내용은 받아오지 않음으로써 응답시간 단축
-		Sort("-date_created"). // 생성일자 역순으로 정렬
-		Skip((page - 1) * limit).
-		Limit(limit).
-		All(&notices); err != nil {
+	// 게시 기한이 지난 공지는 공개 목록에서 제외
+	list, err := h.listNotice(c, true, page, limit)
+	if err != nil {
 		return
 	}
 
-	// Notice 슬라이스 순회하며 닉네임 매핑
-	for _, notice := range notices {
-		h.MapAuthorNickname(c, notice)
+	return c.JSON(http.StatusOK, list)
+}
+
+func (h *Handler) ListNoticeAdmin(c echo.Context) (err error) {
+	// Get query params
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	// Default pagination
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = 20
+	}
+
+	// List notices from database
+	// 관리자는 게시 기한이 지난 공지까지 모두 볼 수 있음
+	list, err := h.listNotice(c, false, page, limit)
+	if err != nil {
+		return
 	}
 
-	return c.JSON(http.StatusOK, notices)
+	return c.JSON(http.StatusOK, list)
 }
 
 func (h *Handler) CountNotice(c echo.Context) (err error) {
 	// int type 변수 지정
 	var count int
 
-	// Get count of stories from database
-	db := h.DB.Clone()
-	defer db.Close()
-	if count, err = db.DB(DBName).C(NOTICE).
-		Find(nil).
-		Count(); err != nil {
+	// Get count of notices from database
+	// 목록의 페이지 수를 계산할 수 있도록 고정 공지와 기한이 지난 공지는 제외
+	if count, err = h.Notices.CountUnpinned(c.Request().Context(), true); err != nil {
 		return
 	}
 
@@ -129,17 +161,6 @@ func (h *Handler) RetrieveNotice(c echo.Context) (err error) {
 }
 
 func (h *Handler) PatchNotice(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
-		return
-	}
-
-	// Validate admin
-	if err = utility.AdminValidation(c); err != nil {
-		return
-	}
-
 	// Object bind
 	n := new(model.Post)
 	if err = c.Bind(n); err != nil {
@@ -156,35 +177,25 @@ func (h *Handler) PatchNotice(c echo.Context) (err error) {
 	n.Content = c.FormValue("content")
 	n.DateModified = c.FormValue("date_modified")
 
+	// 상단 고정 및 게시 종료 일시: 보낸 값만 바꾼다
+	options, err := bindNoticeOptions(c, n)
+	if err != nil {
+		return
+	}
+
 	// Update story in database
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(NOTICE).
-		Update(
-		bson.M{"_id": n.ID},
-		bson.M{"$set":
-		bson.M{
-			"title":         n.Title,
-			"content":       n.Content,
-			"date_modified": n.DateModified}}); err != nil {
+	if err = h.Notices.Update(c.Request().Context(), n,
+		append([]string{"title", "content", "date_modified"}, options...)...); err != nil {
 		return
 	}
 
+	// 글이 쓰는 파일 기록
+	h.trackPost(c, n)
+
 	return c.JSON(http.StatusOK, n)
 }
 
 func (h *Handler) DestroyNotice(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
-		return
-	}
-
-	// Validate admin
-	if err = utility.AdminValidation(c); err != nil {
-		return
-	}
-
 	// Object bind
 	n := new(model.Post)
 	if err = c.Bind(n); err != nil {
@@ -197,12 +208,81 @@ func (h *Handler) DestroyNotice(c echo.Context) (err error) {
 	}
 
 	// Destroy board in database
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(NOTICE).
-		Remove(bson.M{"_id": n.ID}); err != nil {
+	if err = h.Notices.Delete(c.Request().Context(), n.ID); err != nil {
 		return
 	}
 
+	// 글이 쓰던 파일의 참조 해제: 유예 기간이 지나면 정리 작업이 지운다
+	if err := h.TrackAssets(c.Request().Context(), n.ID); err != nil {
+		slog.ErrorContext(c.Request().Context(), "파일 참조를 해제하지 못했습니다", "ref_id", n.ID.Hex(), "error", err)
+	}
+
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (h *Handler) listNotice(c echo.Context, active bool, page int, limit int) (list *model.NoticeList, err error) {
+	// 고정 공지와 일반 공지를 나누어 조회: active 이면 게시 기한이 지난 공지는 제외
+	list = &model.NoticeList{
+		Pinned:  []*model.Post{},
+		Notices: []*model.Post{},
+	}
+
+	// 고정 공지는 페이지와 상관없이 고정 순서대로 모두 가져온다
+	pinned, err := h.Notices.ListPinned(c.Request().Context(), active)
+	if err != nil {
+		return
+	}
+	list.Pinned = append(list.Pinned, pinned...)
+
+	// 일반 공지
+	notices, err := h.Notices.ListUnpinned(c.Request().Context(), active, (page-1)*limit, limit)
+	if err != nil {
+		return
+	}
+	list.Notices = append(list.Notices, notices...)
+
+	// Notice 슬라이스 순회하며 닉네임 매핑
+	for _, notice := range list.Pinned {
+		h.MapAuthorNickname(c, notice)
+	}
+	for _, notice := range list.Notices {
+		h.MapAuthorNickname(c, notice)
+	}
+
+	return
+}
+
+func bindNoticeOptions(c echo.Context, n *model.Post) (fields []string, err error) {
+	// 보낸 값만 바꾸고 바꾼 필드를 돌려준다: 보내지 않은 값은 그대로 둔다
+	form, err := c.FormParams()
+	if err != nil {
+		return
+	}
+
+	// 상단 고정 여부와 고정 순서: 순서가 작을수록 위에 표시
+	if _, ok := form["is_pinned"]; ok {
+		n.IsPinned, _ = strconv.ParseBool(form.Get("is_pinned"))
+		fields = append(fields, "is_pinned")
+	}
+	if _, ok := form["pin_order"]; ok {
+		n.PinOrder, _ = strconv.Atoi(form.Get("pin_order"))
+		fields = append(fields, "pin_order")
+	}
+
+	// 게시 종료 일시: 비어 있으면 기한 없이 게시
+	if _, ok := form["expires_at"]; ok {
+		n.ExpiresAt = nil
+		if expiresAt := form.Get("expires_at"); expiresAt != "" {
+			t, err := time.Parse(time.RFC3339, expiresAt)
+			if err != nil {
+				return nil, &echo.HTTPError{
+					Code:    http.StatusBadRequest,
+					Message: "게시 종료 일시의 형식이 올바르지 않습니다",
+				}
+			}
+			n.ExpiresAt = &t
+		}
+		fields = append(fields, "expires_at")
+	}
+	return
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	// Default package
+	"time"
+	"testing"
+	"net/url"
+	"net/http"
+	// User package
+	"github.com/backend/model"
+)
+
+func TestNotice(t *testing.T) {
+	// 게시 기한이 지난 공지는 공개 목록과 갯수에서 빠지고, 관리자 목록에는 남는다
+	s := newTestServer(t)
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	_, memberToken := s.user("member@example.com", "member", false, false)
+
+	pinned := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/notice/", adminToken, http.StatusCreated, url.Values{
+		"title":     {"Pinned"},
+		"content":   {"<p>고정 공지</p>"},
+		"is_pinned": {"true"}}), pinned)
+	notice := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/notice/", adminToken, http.StatusCreated, url.Values{
+		"title":      {"Notice"},
+		"content":    {"<p>공지</p>"},
+		"expires_at": {time.Now().Add(time.Hour).Format(time.RFC3339)}}), notice)
+	s.form(http.MethodPost, "/notice/", memberToken, http.StatusUnauthorized, url.Values{"title": {"Notice"}, "content": {"<p>공지</p>"}})
+	s.form(http.MethodPost, "/notice/", adminToken, http.StatusBadRequest, url.Values{"title": {"Notice"}, "content": {"<p>공지</p>"}, "expires_at": {"내일"}})
+
+	list := new(model.NoticeList)
+	s.decode(s.get("/notice/list/", "", http.StatusOK), list)
+	if len(list.Pinned) != 1 || list.Pinned[0].ID != pinned.ID || len(list.Notices) != 1 || list.Notices[0].ID != notice.ID {
+		t.Errorf("GET /notice/list/ = %+v, want 고정 공지 1건, 일반 공지 1건", list)
+	}
+	if count := s.get("/notice/count/", "", http.StatusOK).Body.String(); count != "1" {
+		t.Errorf("GET /notice/count/ = %s, want 1", count)
+	}
+
+	// 게시 기한이 지나면 공개 목록에서 빠진다
+	s.form(http.MethodPatch, "/notice/"+notice.ID.Hex(), adminToken, http.StatusOK, url.Values{
+		"title":      {"Notice"},
+		"content":    {"<p>고친 공지</p>"},
+		"expires_at": {time.Now().Add(-time.Minute).Format(time.RFC3339)}})
+	s.decode(s.get("/notice/list/", "", http.StatusOK), list)
+	if len(list.Pinned) != 1 || len(list.Notices) != 0 {
+		t.Errorf("기한이 지난 뒤 GET /notice/list/ = %+v, want 고정 공지만", list)
+	}
+	if count := s.get("/notice/count/", "", http.StatusOK).Body.String(); count != "0" {
+		t.Errorf("기한이 지난 뒤 GET /notice/count/ = %s, want 0", count)
+	}
+	s.get("/notice/admin/list/", memberToken, http.StatusUnauthorized)
+	s.decode(s.get("/notice/admin/list/", adminToken, http.StatusOK), list)
+	if len(list.Notices) != 1 || list.Notices[0].ID != notice.ID || list.Notices[0].ExpiresAt == nil {
+		t.Errorf("GET /notice/admin/list/ = %+v, want 기한이 지난 공지", list)
+	}
+
+	// expires_at 을 보내지 않으면 기한은 그대로, 비워 보내면 기한 없이 게시
+	s.form(http.MethodPatch, "/notice/"+notice.ID.Hex(), adminToken, http.StatusOK, url.Values{"title": {"Notice"}, "content": {"<p>고친 공지</p>"}})
+	if s.decode(s.get("/notice/list/", "", http.StatusOK), list); len(list.Notices) != 0 {
+		t.Errorf("expires_at 없이 수정했는데 기한이 사라졌습니다: %+v", list)
+	}
+	s.form(http.MethodPatch, "/notice/"+notice.ID.Hex(), adminToken, http.StatusOK, url.Values{"title": {"Notice"}, "content": {"<p>고친 공지</p>"}, "expires_at": {""}})
+	if s.decode(s.get("/notice/list/", "", http.StatusOK), list); len(list.Notices) != 1 {
+		t.Errorf("기한을 지웠는데 공개 목록에 없습니다: %+v", list)
+	}
+
+	viewed := new(model.Post)
+	s.decode(s.get("/notice/view/"+notice.ID.Hex(), "", http.StatusOK), viewed)
+	if viewed.Content != "<p>고친 공지</p>" || viewed.AuthorNickname != "admin" {
+		t.Errorf("GET /notice/view/ = %+v", viewed)
+	}
+	s.request(http.MethodDelete, "/notice/"+notice.ID.Hex(), memberToken, http.StatusUnauthorized, nil, "")
+	s.request(http.MethodDelete, "/notice/"+notice.ID.Hex(), adminToken, http.StatusNoContent, nil, "")
+	s.get("/notice/view/"+notice.ID.Hex(), "", http.StatusNotFound)
+}
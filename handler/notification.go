@@ -0,0 +1,212 @@
+package handler
+
+import (
+	// Default package
+	"time"
+	"context"
+	"strconv"
+	"net/http"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/auth"
+	"github.com/backend/model"
+	"github.com/backend/repository"
+)
+
+// 알림 보관 기간: 지난 알림은 MongoDB TTL 인덱스가 지운다
+const NotificationRetention = repository.NotificationRetention
+
+func (h *Handler) Notify(ctx context.Context, userID bson.ObjectId, notificationType string, message string, targetID bson.ObjectId) (err error) {
+	// 한 명의 회원에게 알림 생성: 해당 종류의 알림을 끈 회원은 건너뛴다
+	users, err := h.Users.List(ctx, repository.UserQuery{
+		IDs:     []bson.ObjectId{userID},
+		Unmuted: notificationType})
+	if err != nil || len(users) == 0 {
+		return
+	}
+
+	n := &model.Notification{
+		ID:          bson.NewObjectId(),
+		UserID:      userID,
+		Type:        notificationType,
+		Message:     message,
+		TargetID:    targetID,
+		DateCreated: time.Now(),
+	}
+	if err = h.Notifications.Create(ctx, n); err != nil {
+		return
+	}
+
+	// 접속 중인 회원에게 바로 전달
+	h.Events.Publish(Event{
+		Type:   EventNotification,
+		Data:   n,
+		UserID: userID.Hex(),
+	})
+	return
+}
+
+func (h *Handler) NotifyAll(ctx context.Context, notificationType string, message string, targetID bson.ObjectId) (err error) {
+	// 활성화된 모든 회원에게 알림 생성
+	users, err := h.Users.List(ctx, repository.UserQuery{
+		Active:  true,
+		Unmuted: notificationType})
+	if err != nil || len(users) == 0 {
+		return
+	}
+
+	now := time.Now()
+	notifications := make([]*model.Notification, len(users))
+	for i, u := range users {
+		notifications[i] = &model.Notification{
+			ID:          bson.NewObjectId(),
+			UserID:      u.ID,
+			Type:        notificationType,
+			Message:     message,
+			TargetID:    targetID,
+			DateCreated: now,
+		}
+	}
+	if err = h.Notifications.CreateMany(ctx, notifications); err != nil {
+		return
+	}
+
+	// 회원마다 알림 ID 가 다르므로 내용만 보낸다: 클라이언트는 알림 목록을 다시 불러온다
+	// 알림을 끈 회원도 이벤트는 받지만 목록에는 나타나지 않는다
+	h.Events.Publish(Event{
+		Type:    EventNotification,
+		Data:    echo.Map{"type": notificationType, "message": message, "target_id": targetID},
+		Private: true,
+	})
+	return
+}
+
+func (h *Handler) ListNotification(c echo.Context) (err error) {
+	user := auth.CurrentUser(c)
+
+	// Get query params
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	// Default pagination
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = 20
+	}
+
+	// List notifications from database
+	// 생성일자 역순으로 정렬
+	notifications, err := h.Notifications.List(c.Request().Context(), user.ID, (page-1)*limit, limit)
+	if err != nil {
+		return
+	}
+	if notifications == nil {
+		notifications = []*model.Notification{}
+	}
+
+	return c.JSON(http.StatusOK, notifications)
+}
+
+func (h *Handler) CountUnreadNotification(c echo.Context) (err error) {
+	user := auth.CurrentUser(c)
+
+	count, err := h.Notifications.CountUnread(c.Request().Context(), user.ID)
+	if err != nil {
+		return
+	}
+
+	// int type count 를 ascii 로 변환해서 리턴
+	return c.String(http.StatusOK, strconv.Itoa(count))
+}
+
+func (h *Handler) ReadNotification(c echo.Context) (err error) {
+	user := auth.CurrentUser(c)
+
+	// Get notification ID
+	notificationID := c.Param("notification_id")
+	if !bson.IsObjectIdHex(notificationID) {
+		return echo.ErrNotFound
+	}
+
+	// 자신의 알림만 읽음 처리할 수 있음
+	if err = h.Notifications.MarkRead(c.Request().Context(), user.ID, bson.ObjectIdHex(notificationID)); err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
+		return
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) ReadAllNotification(c echo.Context) (err error) {
+	user := auth.CurrentUser(c)
+
+	if err = h.Notifications.MarkAllRead(c.Request().Context(), user.ID); err != nil {
+		return
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) RetrieveNotificationPreference(c echo.Context) (err error) {
+	// Find user in database
+	user := auth.CurrentUser(c)
+	u, err := h.Users.Get(c.Request().Context(), user.ID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
+		return
+	}
+
+	return c.JSON(http.StatusOK, notificationPreference(u.MutedNotifications))
+}
+
+func (h *Handler) PatchNotificationPreference(c echo.Context) (err error) {
+	// Find user in database
+	user := auth.CurrentUser(c)
+	u, err := h.Users.Get(c.Request().Context(), user.ID)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
+		return
+	}
+
+	// 보낸 종류만 바꾼다: true 는 켜고 false 는 끄며, 보내지 않은 종류는 그대로 둔다
+	preference := notificationPreference(u.MutedNotifications)
+	for _, notificationType := range model.NotificationTypes {
+		if enabled, err := strconv.ParseBool(c.FormValue(notificationType)); err == nil {
+			preference[notificationType] = enabled
+		}
+	}
+	muted := []string{}
+	for _, notificationType := range model.NotificationTypes {
+		if !preference[notificationType] {
+			muted = append(muted, notificationType)
+		}
+	}
+
+	if err = h.Users.Update(c.Request().Context(), &model.User{ID: user.ID, MutedNotifications: muted}, "muted_notifications"); err != nil {
+		return
+	}
+
+	return c.JSON(http.StatusOK, notificationPreference(muted))
+}
+
+func notificationPreference(muted []string) map[string]bool {
+	// 알림 종류별 수신 여부
+	preference := make(map[string]bool)
+	for _, notificationType := range model.NotificationTypes {
+		preference[notificationType] = true
+	}
+	for _, notificationType := range muted {
+		preference[notificationType] = false
+	}
+	return preference
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	// Default package
+	"testing"
+	"net/url"
+	"net/http"
+	// User package
+	"github.com/backend/model"
+)
+
+func TestNotifications(t *testing.T) {
+	// 자신의 알림만 읽음으로 바꿀 수 있고, 끈 종류의 알림은 받지 않는다
+	s := newTestServer(t)
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	_, authorToken := s.user("author@example.com", "author", true, false)
+	_, memberToken := s.user("member@example.com", "member", false, false)
+
+	story := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/story/", authorToken, http.StatusCreated, url.Values{"title": {"Story"}, "content": {"<p>본문</p>"}}), story)
+	s.form(http.MethodPatch, "/story/publish/"+story.ID.Hex(), adminToken, http.StatusOK, url.Values{"is_published": {"true"}})
+	s.form(http.MethodPatch, "/story/publish/"+story.ID.Hex(), adminToken, http.StatusOK, url.Values{"is_published": {"false"}})
+
+	var notifications []*model.Notification
+	s.decode(s.get("/notifications/", authorToken, http.StatusOK), &notifications)
+	if len(notifications) != 2 {
+		t.Fatalf("GET /notifications/ = %v, want 발행, 발행 취소 알림", notifications)
+	}
+	if unread := s.get("/notifications/unread/", authorToken, http.StatusOK).Body.String(); unread != "2" {
+		t.Errorf("GET /notifications/unread/ = %s, want 2", unread)
+	}
+	s.request(http.MethodPatch, "/notifications/read/"+notifications[0].ID.Hex(), memberToken, http.StatusNotFound, nil, "")
+	s.request(http.MethodPatch, "/notifications/read/"+notifications[0].ID.Hex(), authorToken, http.StatusOK, nil, "")
+	if unread := s.get("/notifications/unread/", authorToken, http.StatusOK).Body.String(); unread != "1" {
+		t.Errorf("하나를 읽은 뒤 GET /notifications/unread/ = %s, want 1", unread)
+	}
+	s.request(http.MethodPatch, "/notifications/read/", authorToken, http.StatusOK, nil, "")
+	if unread := s.get("/notifications/unread/", authorToken, http.StatusOK).Body.String(); unread != "0" {
+		t.Errorf("모두 읽은 뒤 GET /notifications/unread/ = %s, want 0", unread)
+	}
+
+	// 알림 설정: 보낸 종류만 바뀐다
+	var preference map[string]bool
+	s.decode(s.get("/notifications/preferences/", memberToken, http.StatusOK), &preference)
+	for _, notificationType := range model.NotificationTypes {
+		if !preference[notificationType] {
+			t.Errorf("기본 알림 설정 %s = false", notificationType)
+		}
+	}
+	s.form(http.MethodPatch, "/notifications/preferences/", memberToken, http.StatusOK, url.Values{model.NotificationNotice: {"false"}})
+	s.decode(s.get("/notifications/preferences/", memberToken, http.StatusOK), &preference)
+	if preference[model.NotificationNotice] || !preference[model.NotificationRoleChanged] {
+		t.Errorf("GET /notifications/preferences/ = %v", preference)
+	}
+
+	// 공지사항 알림을 끈 회원은 새 공지사항 알림을 받지 않는다
+	s.form(http.MethodPost, "/notice/", adminToken, http.StatusCreated, url.Values{"title": {"Notice"}, "content": {"<p>공지</p>"}})
+	s.decode(s.get("/notifications/", memberToken, http.StatusOK), &notifications)
+	if len(notifications) != 0 {
+		t.Errorf("알림을 끈 회원이 공지사항 알림을 받았습니다: %v", notifications)
+	}
+	if unread := s.get("/notifications/unread/", authorToken, http.StatusOK).Body.String(); unread != "1" {
+		t.Errorf("GET /notifications/unread/ = %s, want 공지사항 알림 1", unread)
+	}
+}
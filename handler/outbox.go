@@ -0,0 +1,209 @@
+package handler
+
+import (
+	// Default package
+	"time"
+	"context"
+	"strconv"
+	"net/http"
+	"log/slog"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	"go.opentelemetry.io/otel/attribute"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/logging"
+	"github.com/backend/metrics"
+	"github.com/backend/tracing"
+	"github.com/backend/utility"
+	"github.com/backend/repository"
+)
+
+const (
+	OutboxWorkers      = 2                // 메일을 보내는 워커 수
+	OutboxPollInterval = 10 * time.Second // 새 메일이 없을 때 확인하는 간격
+	OutboxMaxAttempts  = 8                // 이 횟수만큼 실패하면 dead 상태가 된다
+	OutboxBaseDelay    = 30 * time.Second // 첫 재시도까지 기다리는 시간: 실패할 때마다 두 배
+	OutboxMaxDelay     = 6 * time.Hour    // 재시도 간격의 최댓값
+	OutboxLease        = 10 * time.Minute // 발송 중 서버가 죽어도 이 시간이 지나면 다시 보낸다
+)
+
+func (h *Handler) StartOutbox(workers int) {
+	// 서버 시작 시 한 번 호출: 재시작 전에 남아 있던 메일도 이어서 보낸다
+	h.outbox = make(chan struct{}, 1)
+	metrics.RegisterQueue("outbox", h.outboxDepth)
+	for i := 0; i < workers; i++ {
+		h.background("outbox", h.outboxWorker)
+	}
+}
+
+func (h *Handler) Enqueue(ctx context.Context, kind string, r *utility.Request) (err error) {
+	// 렌더링된 메일을 outbox 에 저장: 실제 발송은 워커가 맡는다
+	now := time.Now()
+	if err = h.Outbox.Create(ctx, &model.OutboxMessage{
+		ID:          bson.NewObjectId(),
+		Kind:        kind,
+		To:          r.To[0],
+		Subject:     r.Subject,
+		Body:        r.Body,
+		Text:        r.Text,
+		Unsubscribe: r.Unsubscribe,
+		Status:      model.OutboxPending,
+		NextAttempt: now,
+		DateCreated: now,
+		RequestID:   logging.RequestID(ctx),
+	}); err != nil {
+		return
+	}
+
+	h.wakeOutbox()
+	return
+}
+
+func (h *Handler) ListOutbox(c echo.Context) (err error) {
+	// Get query params
+	status := c.QueryParam("status")
+	page, _ := strconv.Atoi(c.QueryParam("page"))
+	limit, _ := strconv.Atoi(c.QueryParam("limit"))
+
+	// Default pagination
+	if page == 0 {
+		page = 1
+	}
+	if limit == 0 {
+		limit = 20
+	}
+
+	// 기본값은 실패한 메일: 포기한 메일과 재시도를 기다리는 메일
+	messages, err := h.Outbox.List(c.Request().Context(), status, (page-1)*limit, limit)
+	if err != nil {
+		return
+	}
+	if messages == nil {
+		messages = []*model.OutboxMessage{}
+	}
+
+	return c.JSON(http.StatusOK, messages)
+}
+
+func (h *Handler) ResendOutbox(c echo.Context) (err error) {
+	// Get message ID
+	messageID := c.Param("message_id")
+	if !bson.IsObjectIdHex(messageID) {
+		return echo.ErrNotFound
+	}
+
+	// 아직 보내지 못한 메일만 처음부터 다시 시도한다
+	if err = h.Outbox.Retry(c.Request().Context(), bson.ObjectIdHex(messageID), time.Now()); err != nil {
+		if err == repository.ErrNotFound {
+			return echo.ErrNotFound
+		}
+		return
+	}
+
+	h.wakeOutbox()
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) outboxDepth() float64 {
+	// 아직 보내지 못한 메일 수: 재시도를 기다리는 메일도 포함한다
+	n, err := h.Outbox.CountUnsent(context.Background())
+	if err != nil {
+		slog.Error("outbox 대기열 길이를 세지 못했습니다", "job", "outbox", "error", err)
+	}
+	return float64(n)
+}
+
+func (h *Handler) wakeOutbox() {
+	// 워커가 없거나 이미 깨어 있으면 건너뛴다
+	select {
+	case h.outbox <- struct{}{}:
+	default:
+	}
+}
+
+func (h *Handler) outboxWorker() {
+	ticker := time.NewTicker(OutboxPollInterval)
+	defer ticker.Stop()
+
+	for {
+		// 보낼 메일이 없을 때까지 계속 보낸 뒤 다음 신호를 기다린다
+		// 종료 중에는 보내던 메일까지만 보내고 멈춘다: 남은 메일은 다음 실행 때 보낸다
+		for h.isRunning() && h.deliverOutbox() {
+		}
+		select {
+		case <-h.stopping():
+			return
+		case <-h.outbox:
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *Handler) deliverOutbox() bool {
+	// 보낼 메일을 찾는 조회는 주기적으로 실행되므로 추적하지 않는다
+	// 보낼 차례가 된 메일 하나를 선점: 여러 워커나 서버가 같은 메일을 보내지 않는다
+	m, err := h.Outbox.Claim(context.Background(), time.Now(), OutboxLease)
+	if err != nil {
+		if err != repository.ErrNotFound {
+			slog.Error("outbox 에서 메일을 가져오지 못했습니다", "job", "outbox", "error", err)
+		}
+		return false
+	}
+
+	// 메일을 만든 요청의 ID 로 로그를 남긴다
+	// 메일 하나를 보내는 과정(SMTP 발송과 상태 저장)을 하나의 추적으로 기록한다
+	ctx := logging.WithRequestID(context.Background(), m.RequestID)
+	ctx, span := tracing.Start(ctx, "outbox deliver",
+		attribute.String("outbox.message_id", m.ID.Hex()),
+		attribute.String("outbox.kind", m.Kind),
+		attribute.Int("outbox.attempts", m.Attempts))
+	logger := slog.With("job", "outbox", "message_id", m.ID.Hex(), "kind", m.Kind, "attempts", m.Attempts)
+
+	r := &utility.Request{
+		To:          []string{m.To},
+		Subject:     m.Subject,
+		Body:        m.Body,
+		Text:        m.Text,
+		Unsubscribe: m.Unsubscribe,
+	}
+	sendErr := h.Mailer.Send(ctx, r)
+	metrics.ObserveMail(m.Kind, sendErr)
+	defer tracing.End(span, sendErr)
+
+	// 선점을 풀고 결과를 저장한다
+	m.LockedUntil = nil
+	fields := []string{"status", "locked_until"}
+	switch {
+	case sendErr == nil:
+		// 보낸 메일의 본문은 남겨 두지 않는다
+		now := time.Now()
+		m.Status, m.DateSent, m.Body, m.Text = model.OutboxSent, &now, "", ""
+		fields = append(fields, "date_sent", "body", "text")
+	case m.Attempts >= OutboxMaxAttempts:
+		logger.ErrorContext(ctx, "메일을 보내지 못해 재시도를 멈춥니다", "error", sendErr)
+		m.Status, m.LastError = model.OutboxDead, sendErr.Error()
+		fields = append(fields, "last_error")
+	default:
+		logger.WarnContext(ctx, "메일을 보내지 못했습니다: 나중에 다시 보냅니다", "error", sendErr)
+		m.Status, m.LastError, m.NextAttempt = model.OutboxPending, sendErr.Error(), time.Now().Add(outboxBackoff(m.Attempts))
+		fields = append(fields, "last_error", "next_attempt")
+	}
+	if err := h.Outbox.Update(ctx, m, fields...); err != nil {
+		logger.ErrorContext(ctx, "발송 상태를 저장하지 못했습니다", "error", err)
+	}
+	return true
+}
+
+func outboxBackoff(attempts int) time.Duration {
+	// 30초, 1분, 2분, 4분 ... 최대 6시간
+	delay := OutboxBaseDelay
+	for i := 1; i < attempts && delay < OutboxMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > OutboxMaxDelay {
+		delay = OutboxMaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	// Default package
+	"time"
+	"errors"
+	"context"
+	"testing"
+	"net/http"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/utility"
+)
+
+// 보내기에 실패하는 메일 서버
+type failingMailer struct {
+	*utility.MemoryMailer
+	err error
+}
+
+func (m *failingMailer) Send(ctx context.Context, r *utility.Request) error {
+	if m.err != nil {
+		return m.err
+	}
+	return m.MemoryMailer.Send(ctx, r)
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		1:  OutboxBaseDelay,
+		2:  2 * OutboxBaseDelay,
+		4:  8 * OutboxBaseDelay,
+		12: OutboxMaxDelay,
+		50: OutboxMaxDelay,
+	}
+	for attempts, want := range cases {
+		if got := outboxBackoff(attempts); got != want {
+			t.Errorf("outboxBackoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
+
+func TestOutboxRetry(t *testing.T) {
+	// 실패하면 간격을 늘려 가며 다시 보내고, OutboxMaxAttempts 번 실패하면 dead 상태로 멈춘다
+	s := newTestServer(t)
+	ctx := context.Background()
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	s.user("member@example.com", "member", false, false)
+	mailer := &failingMailer{MemoryMailer: s.mailer, err: errors.New("연결 실패")}
+	s.h.Mailer = mailer
+
+	s.get("/reset/member@example.com", "", http.StatusOK)
+	var messages []*model.OutboxMessage
+	s.decode(s.get("/outbox/?status=pending", adminToken, http.StatusOK), &messages)
+	if len(messages) != 1 || messages[0].Kind != model.OutboxResetPassword {
+		t.Fatalf("GET /outbox/?status=pending = %v, want 비밀번호 초기화 메일 1통", messages)
+	}
+	id := messages[0].ID
+
+	message := func() *model.OutboxMessage {
+		t.Helper()
+		for _, status := range []string{model.OutboxPending, model.OutboxSending, model.OutboxSent, model.OutboxDead} {
+			list, err := s.h.Outbox.List(ctx, status, 0, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, m := range list {
+				if m.ID == id {
+					return m
+				}
+			}
+		}
+		t.Fatalf("outbox 에 %s 메일이 없습니다", id.Hex())
+		return nil
+	}
+
+	for attempt := 1; attempt <= OutboxMaxAttempts; attempt++ {
+		before := time.Now().Truncate(time.Millisecond) // 저장소는 밀리초 단위로 저장한다
+		if !s.h.deliverOutbox() {
+			t.Fatalf("%d번째 시도: 보낼 메일이 없습니다", attempt)
+		}
+		m := message()
+		if m.Attempts != attempt || m.LastError != "연결 실패" || m.LockedUntil != nil {
+			t.Fatalf("%d번째 시도 뒤 = %+v", attempt, m)
+		}
+		if attempt == OutboxMaxAttempts {
+			if m.Status != model.OutboxDead {
+				t.Fatalf("%d번 실패한 메일의 상태 = %s, want dead", attempt, m.Status)
+			}
+			break
+		}
+
+		// 다음 시도는 outboxBackoff 뒤: 그 전에는 보내지 않는다
+		delay := outboxBackoff(attempt)
+		if m.Status != model.OutboxPending || m.NextAttempt.Before(before.Add(delay)) || m.NextAttempt.After(time.Now().Add(delay)) {
+			t.Fatalf("%d번째 시도 뒤 status = %s, next_attempt = %v, want pending, %v 뒤", attempt, m.Status, m.NextAttempt, delay)
+		}
+		if s.h.deliverOutbox() {
+			t.Fatalf("%d번째 시도 뒤: 재시도 시각 전에 다시 보냈습니다", attempt)
+		}
+		m.NextAttempt = time.Now()
+		if err := s.h.Outbox.Update(ctx, m, "next_attempt"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if s.h.deliverOutbox() {
+		t.Fatal("dead 상태의 메일을 다시 보냈습니다")
+	}
+
+	// 기본 목록은 실패한 메일이다: 관리자가 다시 보내면 처음부터 시도한다
+	s.decode(s.get("/outbox/", adminToken, http.StatusOK), &messages)
+	if len(messages) != 1 || messages[0].Status != model.OutboxDead {
+		t.Fatalf("GET /outbox/ = %v, want dead 메일 1통", messages)
+	}
+	s.request(http.MethodPatch, "/outbox/resend/"+id.Hex(), "", http.StatusBadRequest, nil, "")
+	s.request(http.MethodPatch, "/outbox/resend/"+id.Hex(), adminToken, http.StatusOK, nil, "")
+	if m := message(); m.Status != model.OutboxPending || m.Attempts != 0 {
+		t.Errorf("다시 보내기 = %+v, want pending, 0회", m)
+	}
+	mailer.err = nil
+	s.deliverOutbox()
+	s.mailTo("member@example.com")
+	if m := message(); m.Status != model.OutboxSent || m.DateSent == nil || m.Body != "" || m.Text != "" {
+		t.Errorf("보낸 메일 = %+v, want sent, 본문 없음", m)
+	}
+	s.request(http.MethodPatch, "/outbox/resend/"+id.Hex(), adminToken, http.StatusNotFound, nil, "")
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	// Default package
+	"net/http"
+	// Third Party package
+	"github.com/labstack/echo"
+	// User package
+	"github.com/backend/auth"
+	"github.com/backend/metrics"
+	"github.com/backend/openapi"
+	"github.com/backend/storage"
+)
+
+// 설정에 따라 달라지는 라우트
+type RouteOptions struct {
+	Spec         *openapi.Document // /openapi.json 으로 제공할 API 문서
+	Metrics      bool              // API 서버에서 /metrics 를 제공할지 여부: 관리용 포트를 쓰면 false
+	MetricsToken string            // /metrics 를 보호하는 토큰
+}
+
+func (h *Handler) Routes(r *auth.Router, o RouteOptions) {
+	// 모든 라우트를 인증 방식과 함께 등록한다: 서버와 API 문서 테스트가 같은 라우트 표를 쓴다
+	// Route: Static
+	// 로컬 저장소일 때만 API 서버가 직접 정적 파일을 서비스한다
+	// 공개 파일은 이름이 내용의 해쉬값이라 바뀌지 않으므로 오래 캐시하도록 한다
+	if local, ok := h.Storage.(*storage.Local); ok {
+		assetCacheControl := func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(c echo.Context) error {
+				c.Response().Header().Set("Cache-Control", storage.CacheControl)
+				err := next(c)
+				if err != nil {
+					// 없는 파일의 404 응답은 캐시하지 않는다
+					c.Response().Header().Del("Cache-Control")
+				}
+				return err
+			}
+		}
+		r.Static("/assets", local.Dir, assetCacheControl)     // 정적 파일
+		r.GET("/private/*", h.ServePrivateAsset, auth.Public) // 서명된 주소로 비공개 파일 내려받기
+	}
+
+	// Route: Index
+	r.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, "섬띵모어 API 서버\n")
+	}, auth.Public)
+
+	// Route: Health
+	r.GET("/healthz", h.Healthz, auth.Public) // 프로세스 동작 확인
+	r.GET("/readyz", h.Readyz, auth.Public)   // 데이터베이스, 저장소, 메일 서버 연결 확인
+
+	// Route: Metrics
+	// 관리용 포트를 정하지 않았다면 API 서버에서 토큰으로 보호해 제공한다
+	if o.Metrics {
+		r.GET("/metrics", echo.WrapHandler(metrics.Handler()), auth.Public, metrics.TokenAuth(o.MetricsToken)) // Prometheus 지표
+	}
+
+	// Route: Docs
+	r.GET("/openapi.json", openapi.JSONHandler(o.Spec), auth.Public)     // OpenAPI 문서
+	r.GET("/docs/", openapi.DocsHandler("/openapi.json"), auth.Public) // API 문서 화면
+
+	// Route: User
+	r.POST("/sign-up/", h.SignUpNormal, auth.Public)                   // 회원 가입
+	r.POST("/admin/", h.SignUpAdmin, auth.Admin)                       // 관리자 회원 가입
+	r.GET("/activate/:user_email", h.Activate, auth.Public)            // 이메일 회원 활성화
+	r.POST("/sign-in/", h.SignIn, auth.Public)                         // 로그인
+	r.PATCH("/patch/", h.PatchPassword, auth.Authenticated)            // 비밀번호 수정
+	r.PATCH("/nickname/", h.PatchNickname, auth.Authenticated)         // 닉네임 수정
+	r.DELETE("/destroy/", h.DestroyUser, auth.Authenticated)           // 회원 탈퇴
+	r.GET("/reset/:user_email", h.ResetPassword, auth.Public)          // 비밀번호 초기화
+	r.PATCH("/subscription/", h.PatchSubscription, auth.Authenticated) // 공지사항 메일 수신 여부 변경
+	r.GET("/unsubscribe/:token", h.Unsubscribe, auth.Public)           // 공지사항 메일 수신 거부 확인 화면
+	r.POST("/unsubscribe/:token", h.Unsubscribe, auth.Public)          // 공지사항 메일 수신 거부
+	r.PATCH("/locale/", h.PatchLocale, auth.Authenticated)             // 메일 언어 변경
+
+	// Route: Admin
+	r.GET("/users/", h.ListUsers, auth.Admin)                      // 전체 유저 리스트
+	r.PATCH("/users/:user_email", h.UpdateUserAuth, auth.Admin)    // 유저
+	r.DELETE("/users/:user_email", h.ForceDestroyUser, auth.Admin) // 유저 강제 탈퇴
+	r.PATCH("/users/ban/:user_email", h.BanUser, auth.Admin)       // 유저 이용 정지
+
+	// Route: Outbox
+	r.GET("/outbox/", h.ListOutbox, auth.Admin)                       // 발송 실패 메일 목록
+	r.PATCH("/outbox/resend/:message_id", h.ResendOutbox, auth.Admin) // 메일 다시 보내기
+
+	// Route: Media
+	r.POST("/media/", h.UploadMedia, auth.Authenticated)               // 미디어 업로드
+	r.GET("/media/", h.ListMedia, auth.Authenticated)                  // 내 미디어 목록
+	r.PATCH("/media/:media_id", h.PatchMedia, auth.Authenticated)      // 미디어 대체 텍스트, 설명 수정
+	r.DELETE("/media/:media_id", h.DestroyMedia, auth.Authenticated)   // 미디어 삭제
+	r.GET("/media/usage/", h.ListMediaUsage, auth.Admin)               // 회원별 미디어 사용량
+	r.PATCH("/media/quota/:user_email", h.PatchMediaQuota, auth.Admin) // 회원 미디어 용량 변경
+
+	// Route: Asset
+	r.GET("/gc/assets/", h.ListOrphanedAssets, auth.Admin)     // 정리 대상 파일 미리 보기
+	r.POST("/gc/assets/", h.DestroyOrphanedAssets, auth.Admin) // 정리 대상 파일 지금 삭제
+
+	// Route: Author
+	r.GET("/authors/", h.ListAuthors, auth.Public)                      // 필진 리스트
+	r.GET("/authors/:author_id", h.ListStoryAuthor, auth.Optional)      // 필진 스토리 리스트
+	r.GET("/authors/count/:author_id", h.CountStoryAuthor, auth.Public) // 필진 스토리 갯수
+
+	// Route: Notification
+	r.GET("/notifications/", h.ListNotification, auth.Authenticated)                           // 알림 목록
+	r.GET("/notifications/unread/", h.CountUnreadNotification, auth.Authenticated)             // 읽지 않은 알림 갯수
+	r.PATCH("/notifications/read/", h.ReadAllNotification, auth.Authenticated)                 // 알림 모두 읽음
+	r.PATCH("/notifications/read/:notification_id", h.ReadNotification, auth.Authenticated)    // 알림 읽음
+	r.GET("/notifications/preferences/", h.RetrieveNotificationPreference, auth.Authenticated) // 알림 설정 보기
+	r.PATCH("/notifications/preferences/", h.PatchNotificationPreference, auth.Authenticated)  // 알림 설정 변경
+
+	// Route: Story
+	r.POST("/story/", h.CreateStory, auth.Authenticated)                  // 스토리 생성
+	r.GET("/story/", h.ListStory, auth.Authenticated)                     // 스토리 리스트
+	r.GET("/story/client/", h.ClientListStory, auth.Optional)             // 클라이언트 스토리 리스트
+	r.GET("/story/count/", h.CountStory, auth.Authenticated)              // 스토리 총 갯수
+	r.GET("/story/view/:story_id", h.RetrieveStory, auth.Optional)        // 스토리 디테일
+	r.PATCH("/story/:story_id", h.PatchStory, auth.Authenticated)         // 스토리 수정
+	r.PATCH("/story/publish/:story_id", h.ChangePublishStory, auth.Authenticated) // 스토리 발행 상태 변경
+	r.DELETE("/story/:story_id", h.DestroyStory, auth.Authenticated)      // 스토리 삭제
+
+	// Route: Board
+	r.POST("/board/", h.CreateBoard, auth.Authenticated)             // 자유게시판 글 생성
+	r.GET("/board/list/", h.ListBoard, auth.Optional)                // 자유게시판 글 목록
+	r.GET("/board/count/", h.CountBoard, auth.Public)                // 자유게시판 글 갯수
+	r.GET("/board/view/:board_id", h.RetrieveBoard, auth.Optional)   // 자유게시판 글 보기
+	r.PATCH("/board/:board_id", h.PatchBoard, auth.Authenticated)    // 자유게시판 글 수정
+	r.DELETE("/board/:board_id", h.DestroyBoard, auth.Authenticated) // 자유게시판 글 삭제
+
+	// Route: Notice
+	r.POST("/notice/", h.CreateNotice, auth.Admin)                     // 공지사항 글 생성
+	r.GET("/notice/list/", h.ListNotice, auth.Public)                  // 공지사항 글 목록
+	r.GET("/notice/admin/list/", h.ListNoticeAdmin, auth.Admin)        // 관리자용 공지사항 글 목록
+	r.GET("/notice/count/", h.CountNotice, auth.Public)                // 공지사항 글 갯수
+	r.GET("/notice/view/:notice_id", h.RetrieveNotice, auth.Public)    // 공지사항 글 보기
+	r.PATCH("/notice/:notice_id", h.PatchNotice, auth.Admin)           // 공지사항 글 수정
+	r.DELETE("/notice/:notice_id", h.DestroyNotice, auth.Admin)        // 공지사항 글 삭제
+	r.GET("/notice/broadcast/:notice_id", h.ListBroadcast, auth.Admin) // 공지사항 메일 발송 리포트
+
+	// Route: Export
+	r.GET("/export/story/:story_id", h.ExportStory, auth.Public)            // 스토리 EPUB 내보내기
+	r.GET("/export/series/:author_id/:series", h.ExportSeries, auth.Public) // 연재 EPUB 내보내기
+	r.GET("/export/authors/:author_id", h.ExportAuthor, auth.Public)        // 필진 작품집 EPUB 내보내기
+
+	// Route: Newsletter
+	r.POST("/newsletter/subscribe/", h.SubscribeNewsletter, auth.Public)          // 소식지 구독 신청
+	r.GET("/newsletter/confirm/:token", h.ConfirmNewsletter, auth.Public)         // 소식지 구독 확인
+	r.GET("/newsletter/unsubscribe/:token", h.UnsubscribeNewsletter, auth.Public)  // 소식지 구독 취소 확인 화면
+	r.POST("/newsletter/unsubscribe/:token", h.UnsubscribeNewsletter, auth.Public) // 소식지 구독 취소
+	r.POST("/newsletter/send/", h.SendNewsletter, auth.Admin)                     // 소식지 발송
+	r.GET("/newsletter/", h.ListNewsletter, auth.Admin)                           // 소식지 발송 통계
+
+	// Route: Import
+	r.POST("/import/", h.ImportStory, auth.Authenticated) // 마크다운, WordPress 글 가져오기
+
+	// Route: Anthology
+	r.POST("/anthology/", h.CreateAnthology, auth.Admin)                      // 문집 생성
+	r.GET("/anthology/", h.ListAnthology, auth.Admin)                         // 문집 목록
+	r.GET("/anthology/:anthology_id", h.RetrieveAnthology, auth.Admin)        // 문집 디테일
+	r.PATCH("/anthology/:anthology_id", h.PatchAnthology, auth.Admin)         // 문집 수정
+	r.DELETE("/anthology/:anthology_id", h.DestroyAnthology, auth.Admin)      // 문집 삭제
+	r.GET("/anthology/pdf/:anthology_id", h.ExportAnthologyPDF, auth.Admin)   // 문집 인쇄용 PDF 생성
+	r.GET("/anthology/epub/:anthology_id", h.ExportAnthologyEPUB, auth.Admin) // 문집 EPUB 생성
+
+	// Route: Events
+	// EventSource 는 Authorization 헤더를 보낼 수 없으므로 token 쿼리로 인증한다
+	r.TokenLookup("query:token").GET("/events/", h.StreamEvents, auth.Authenticated) // 실시간 이벤트 스트림
+	r.GET("/events/public/", h.StreamPublicEvents, auth.Public)                      // 공개 실시간 이벤트 스트림
+}
@@ -2,71 +2,112 @@ package handler
 
 import (
 	// Default package
-	"os"
 	"io"
 	"fmt"
+	"bytes"
+	"mime"
+	"context"
+	"time"
 	"strings"
-	"strconv"
-	"math/rand"
+	"net/http"
+	"io/ioutil"
+	"encoding/hex"
+	"crypto/sha256"
 	"path/filepath"
 	"mime/multipart"
+	"log/slog"
 	// Third Party package
 	"github.com/labstack/echo"
-	"github.com/globalsign/mgo"
 	"github.com/globalsign/mgo/bson"
 	// User package
+	"github.com/backend/auth"
 	"github.com/backend/model"
+	"github.com/backend/metrics"
+	"github.com/backend/storage"
+	"github.com/backend/repository"
+	"github.com/backend/utility"
 )
 
-const DBName = "st_more"
-const USER = "users"
-const STORY = "story"
-const BOARD = "board"
-const NOTICE = "notice"
+// 데이터베이스 이름: 서버 시작 시 설정 값으로 바꾼다
+var DBName = "st_more"
 
-func (h *Handler) FindUser(id string) (err error) {
-	db := h.DB.Clone()
-	defer db.Close()
+const USER = repository.UserCollection
+const STORY = repository.StoryCollection
+const BOARD = repository.BoardCollection
+const NOTICE = repository.NoticeCollection
+const ANTHOLOGY = repository.AnthologyCollection
+const BROADCAST = repository.BroadcastCollection
+const SUBSCRIBER = repository.SubscriberCollection
+const NEWSLETTER = repository.NewsletterCollection
+const NOTIFICATION = repository.NotificationCollection
+const OUTBOX = repository.OutboxCollection
+const MEDIA = repository.MediaCollection
+const ASSET = repository.AssetCollection
 
-	if err = db.DB(DBName).C(USER).FindId(bson.ObjectIdHex(id)).One(nil); err != nil {
-		if err == mgo.ErrNotFound {
-			return echo.ErrNotFound
-		}
-		return
+func (h *Handler) LoadPrincipal(ctx context.Context, id bson.ObjectId) (*auth.Principal, error) {
+	// 인증 미들웨어가 토큰의 회원 ID 로 현재 상태를 읽는다
+	u, err := h.Users.Get(ctx, id)
+	if err == repository.ErrNotFound {
+		return nil, auth.ErrUnknownUser
 	}
-	return
+	if err != nil {
+		return nil, err
+	}
+	return &auth.Principal{
+		ID:       u.ID,
+		Email:    u.Email,
+		Nickname: u.Nickname,
+		IsActive: u.IsActive,
+		IsBanned: u.IsBanned,
+		IsStaff:  u.IsStaff,
+		IsAdmin:  u.IsAdmin,
+	}, nil
+}
+
+func (h *Handler) posts(q string) repository.Posts {
+	// 컬렉션 이름에 해당하는 글 저장소
+	switch q {
+	case STORY:
+		return h.Stories
+	case BOARD:
+		return h.Board
+	case NOTICE:
+		return h.Notices
+	}
+	panic("알 수 없는 글 컬렉션입니다: " + q)
 }
 
 func (h *Handler) FindPost(c echo.Context, p *model.Post, q string) (err error) {
 
 	// Get IDs
 	postID := c.Param(fmt.Sprintf("%s_id", q))
+	if !bson.IsObjectIdHex(postID) {
+		return echo.ErrNotFound
+	}
 
 	// Find story in database
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(q).
-		FindId(bson.ObjectIdHex(postID)).
-		One(p); err != nil {
-		if err == mgo.ErrNotFound {
+	found, err := h.posts(q).Get(c.Request().Context(), bson.ObjectIdHex(postID))
+	if err != nil {
+		if err == repository.ErrNotFound {
 			return echo.ErrNotFound
 		}
 		return
 	}
+	*p = *found
 	return
 }
 
 func (h *Handler) MapAuthorNickname(c echo.Context, p *model.Post) (err error) {
 	// 포스트 객체에 담긴 userID 를 이용해 AuthorNickname 을 구하는 함수
-	u := new(model.User)
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		FindId(p.AuthorID).One(u); err != nil {
-		if err == mgo.ErrNotFound {
+	u, err := h.Users.Get(c.Request().Context(), p.AuthorID)
+	if err != nil {
+		if err == repository.ErrNotFound {
 			// 유저를 찾을 수 없는 경우 닉네임에 "탈퇴한 회원" 값을 줌
 			p.AuthorNickname = "탈퇴한 회원"
+			return
 		}
+		// 호출하는 쪽에서 에러를 무시하므로 여기서 기록한다
+		slog.ErrorContext(c.Request().Context(), "작성자 닉네임을 찾지 못했습니다", "author_id", p.AuthorID.Hex(), "error", err)
 		return
 	}
 	p.AuthorNickname = u.Nickname
@@ -74,73 +115,165 @@ func (h *Handler) MapAuthorNickname(c echo.Context, p *model.Post) (err error) {
 	return
 }
 
+func MarkAuthor(c echo.Context, posts ...*model.Post) {
+	// 선택 인증 라우트에서 로그인한 회원이 쓴 글을 표시한다: 방문자면 아무것도 하지 않는다
+	user := auth.CurrentUser(c)
+	if user == nil {
+		return
+	}
+	for _, p := range posts {
+		p.IsAuthor = p.AuthorID == user.ID
+	}
+}
+
 func (h *Handler) UploadThumbnail(c echo.Context, s *model.Post, file *multipart.FileHeader) (err error) {
 	// File open
 	src, err := file.Open()
-	defer src.Close()
 	if err != nil {
 		return
 	}
+	defer src.Close()
+	metrics.UploadBytes.WithLabelValues("thumbnail").Add(float64(file.Size))
 
-	// 파일명 해쉬값 생성
-	letters := []byte ("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ")
-	var sliceStr []string // 빈 문자 타입 슬라이스 선언
-	for i := 0; i < 6; i++ { // 6차례 순회
-		randInt := rand.Intn(len(letters))        // letters 슬라이스의 길이 범위 안에서 랜덤 정수 생성
-		convertedStr := string(letters[randInt])  // 랜덤 정수를 인덱스로 하는 letters 의 요소를 꺼내 문자열로 변환
-		sliceStr = append(sliceStr, convertedStr) // 빈 슬라이스에 랜덤 문자열 추가
+	// 이미지 확인 후 크기별 썸네일 만들기
+	images, err := utility.ProcessThumbnail(src)
+	switch err {
+	case nil:
+	case utility.ErrUnsupportedImage:
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+		}
+	case utility.ErrImageTooLarge:
+		return &echo.HTTPError{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: err.Error(),
+		}
+	default:
+		return
 	}
-	resultStr := strings.Join(sliceStr, "")                 // 랜덤 문자열을 하나의 문자열로 합침
-	resultInt := strconv.FormatInt(rand.Int63n(100000), 10) // 100000 이하의 정수를 랜덤으로 생성해 문자열로 변환
 
-	// 파일명 생성하기
-	baseFileName := file.Filename                           // 원본 파일 이름
-	extension := filepath.Ext(baseFileName)                 // 확장자 추출
-	realName := strings.TrimSuffix(baseFileName, extension) // 확장자 제외한 이름 추출
-	newFileName := realName + "_" + resultStr + resultInt + extension // 새로운 파일명 생성
+	// 썸네일 저장
+	thumbnails := new(model.Thumbnails)
+	for _, img := range images {
+		assetURL, err := h.SaveAsset(c.Request().Context(), s.AuthorID, img.Name+img.Extension, bytes.NewReader(img.Data))
+		if err != nil {
+			return err
+		}
 
-	// 정적 파일 루트 디렉터리 생성하기: 디렉터리가 없으면 생성할 것
-	temporaryPath, _ := filepath.Abs("./")
-	assetsPath := filepath.Join(temporaryPath, "/assets/")
-	if _, err := os.Stat(assetsPath); os.IsNotExist(err) {
-		os.Mkdir(assetsPath, 0777)
+		var variant *model.ThumbnailVariant
+		switch img.Name {
+		case "card":
+			variant = &thumbnails.Card
+		case "hero":
+			variant = &thumbnails.Hero
+		case "og":
+			variant = &thumbnails.OG
+		default:
+			continue
+		}
+		if img.ContentType == "image/webp" {
+			variant.WebP = assetURL
+		} else {
+			variant.URL, variant.Width, variant.Height = assetURL, img.Width, img.Height
+		}
 	}
 
-	// 정적 파일 아래 필진 디렉터리 생성하기
-	authorPathValue := "/" + s.AuthorID.Hex() + "/" // authorID 로 된 path 값 생성
-	authorPath := filepath.Join(assetsPath, authorPathValue)
-	if _, err := os.Stat(authorPath); os.IsNotExist(err) {
-		os.Mkdir(authorPath, 0777)
+	// 파일 주소명을 Story object 에 넣기
+	s.Thumbnail = thumbnails.Hero.URL
+	s.Thumbnails = thumbnails
+	return
+}
+
+func UseCardThumbnail(posts []*model.Post) {
+	// 목록에서는 작은 썸네일을 보낸다
+	for _, p := range posts {
+		if p.Thumbnails != nil && p.Thumbnails.Card.URL != "" {
+			p.Thumbnail = p.Thumbnails.Card.URL
+		}
 	}
+}
 
-	// 파일 생성하기
-	dst, err := os.Create(filepath.Join(authorPath, newFileName)) // 파일 이름에 랜덤 문자열 추가하여 저장
-	defer dst.Close()
+func BaseURL(c echo.Context) string {
+	// 요청이 들어온 서버의 주소: API 링크를 만들 때 사용
+	return c.Scheme() + "://" + c.Request().Host
+}
+
+func (h *Handler) SaveAsset(ctx context.Context, authorID bson.ObjectId, fileName string, src io.Reader) (assetURL string, err error) {
+	// 파일 내용의 해쉬값으로 저장하고 공개 주소를 돌려준다
+	// 같은 내용의 파일은 한 번만 저장되고, 주소가 바뀌지 않으므로 오래 캐시할 수 있다
+	data, err := ioutil.ReadAll(src)
 	if err != nil {
 		return
 	}
 
-	// 복사하기
-	if _, err = io.Copy(dst, src); err != nil {
+	// 저장소에 저장하기: 키는 <해쉬값 앞 두 글자>/<해쉬값><확장자>
+	extension := AssetExtension(fileName)
+	key := AssetKey(data, extension)
+
+	// 파일 기록: 글이나 미디어가 참조하기 전까지는 참조 없는 파일이다
+	// 이미 기록된 파일이고 아직 참조가 없다면 정리 작업이 지우지 않도록 유예 기간을 다시 센다
+	now := time.Now()
+	if _, err = h.Assets.Register(ctx, &model.Asset{
+		Key:          key,
+		OwnerID:      authorID,
+		Size:         int64(len(data)),
+		DateCreated:  now,
+		DateOrphaned: &now,
+	}); err != nil {
+		return
+	}
+
+	// 이미 기록된 파일이어도 다시 저장한다: 같은 키에는 같은 내용이므로 덮어써도 되고,
+	// 먼저 올리던 요청이 실패했거나 저장 전에 서버가 멈췄더라도 주소를 돌려줄 때는 파일이 있다
+	// 저장하지 못하면 기록은 참조 없는 파일로 남아 유예 기간 뒤에 정리된다
+	if err = h.Storage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), mime.TypeByExtension(extension)); err != nil {
 		return
 	}
 
-	// 파일 주소명을 Story object 에 넣기
-	thumbnailURL := c.Scheme() + "://" + c.Request().Host + "/assets" + authorPathValue + newFileName
-	s.Thumbnail = thumbnailURL
-	return
+	// 파일 주소명 생성하기
+	return h.Storage.URL(key), nil
 }
 
-func (h *Handler) PatchThumbnail(s *model.Post) (err error) {
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(STORY).
-		Update(
-		bson.M{"_id": s.ID},
-		bson.M{"$set":
-		bson.M{
-			"thumbnail": s.Thumbnail}}); err != nil {
-		return
+func AssetKey(data []byte, extension string) string {
+	// 파일 내용의 SHA-256 해쉬값으로 만든 저장소 키
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	return hash[:2] + "/" + hash + extension
+}
+
+func AssetExtension(fileName string) string {
+	// 주소에 쓸 수 있는 확장자만 남긴다: 영문 소문자와 숫자가 아니면 확장자 없이 저장
+	extension := strings.ToLower(filepath.Ext(fileName))
+	if len(extension) < 2 || len(extension) > 10 {
+		return ""
 	}
-	return
+	for _, r := range extension[1:] {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return ""
+		}
+	}
+	return extension
+}
+
+func (h *Handler) ServePrivateAsset(c echo.Context) (err error) {
+	// 로컬 저장소의 비공개 파일: 서명된 주소로만 내려받을 수 있다
+	local, ok := h.Storage.(*storage.Local)
+	if !ok {
+		return echo.ErrNotFound
+	}
+
+	key := storage.PrivatePrefix + c.Param("*")
+	if !local.Verify(key, c.QueryParam("expires"), c.QueryParam("signature")) {
+		return echo.ErrForbidden
+	}
+	filePath, err := local.Path(key)
+	if err != nil {
+		return echo.ErrNotFound
+	}
+	return c.File(filePath)
+}
+
+func (h *Handler) PatchThumbnail(ctx context.Context, s *model.Post) (err error) {
+	return h.Stories.Update(ctx, s, "thumbnail", "thumbnails")
 }
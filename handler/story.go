@@ -4,25 +4,24 @@ import (
 	// Default package
 	"strconv"
 	"net/http"
+	"log/slog"
 	// Third Party package
 	"github.com/labstack/echo"
 	"github.com/globalsign/mgo/bson"
 	// User package
+	"github.com/backend/auth"
 	"github.com/backend/model"
+	"github.com/backend/repository"
 	"github.com/backend/utility"
 )
 
 func (h *Handler) CreateStory(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
-		return
-	}
+	user := auth.CurrentUser(c)
 
 	// Bind story object
 	s := &model.Post{
 		ID:       bson.NewObjectId(),
-		AuthorID: bson.ObjectIdHex(userID), // 저자를 표시하기 위해 userID 삽입
+		AuthorID: user.ID, // 저자를 표시하기 위해 회원 ID 삽입
 	}
 
 	if err = c.Bind(s); err != nil {
@@ -48,16 +47,18 @@ func (h *Handler) CreateStory(c echo.Context) (err error) {
 	s.Content = c.FormValue("content")
 	s.DateCreated = c.FormValue("date_created")
 	s.Category = c.FormValue("category")
+	s.Series = c.FormValue("series")
 	s.DateModified = ""
 	s.IsPublished = false
 
 	// Save Post
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(STORY).Insert(s); err != nil {
+	if err = h.Stories.Create(c.Request().Context(), s); err != nil {
 		return
 	}
 
+	// 글이 쓰는 파일 기록
+	h.trackPost(c, s)
+
 	return c.JSON(http.StatusCreated, s)
 }
 
@@ -76,20 +77,17 @@ func (h *Handler) ListStory(c echo.Context) (err error) {
 	}
 
 	// List stories from database
-	userID := utility.UserIDFromToken(c)
-	var stories []*model.Post
-
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(STORY).
-		Find(bson.M{"author_id": bson.ObjectIdHex(userID)}).
-		Select(bson.M{"content": 0}). // 내용은 받아오지 않음으로써 응답시간 단축
-		Sort("-date_created"). // 생성일자 역순으로 정렬
-		Skip((page - 1) * limit).
-		Limit(limit).
-		All(&stories); err != nil {
+	user := auth.CurrentUser(c)
+	stories, err := h.Stories.List(c.Request().Context(), repository.PostQuery{
+		AuthorID: user.ID,
+		Summary:  true, // 내용은 받아오지 않음으로써 응답시간 단축
+		Skip:     (page - 1) * limit,
+		Limit:    limit,
+	})
+	if err != nil {
 		return
 	}
+	UseCardThumbnail(stories)
 
 	return c.JSON(http.StatusOK, stories)
 }
@@ -109,17 +107,23 @@ func (h *Handler) ClientListStory(c echo.Context) (err error) {
 	}
 
 	// List stories from database
-	var stories []*model.Post
-
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(STORY).
-		Find(bson.M{"is_published": true}). // 발행 승인된 게시글만 쿼리
-		Select(bson.M{"content": 0}). // 내용은 받아오지 않음으로써 응답시간 단축
-		Sort("-date_created"). // 생성일자 역순으로 정렬
-		Skip((page - 1) * limit).
-		Limit(limit).
-		All(&stories); err != nil {
+	stories, err := h.FindPublishedStories(c, page, limit)
+	if err != nil {
+		return
+	}
+	MarkAuthor(c, stories...)
+
+	return c.JSON(http.StatusOK, stories)
+}
+
+func (h *Handler) FindPublishedStories(c echo.Context, page int, limit int) (stories []*model.Post, err error) {
+	// 발행 승인된 스토리를 최신순으로 조회: 클라이언트 목록과 소식지에서 사용
+	if stories, err = h.Stories.List(c.Request().Context(), repository.PostQuery{
+		Published: true, // 발행 승인된 게시글만 쿼리
+		Summary:   true, // 내용은 받아오지 않음으로써 응답시간 단축
+		Skip:      (page - 1) * limit,
+		Limit:     limit,
+	}); err != nil {
 		return
 	}
 
@@ -127,8 +131,9 @@ func (h *Handler) ClientListStory(c echo.Context) (err error) {
 	for _, story := range stories {
 		h.MapAuthorNickname(c, story)
 	}
+	UseCardThumbnail(stories)
 
-	return c.JSON(http.StatusOK, stories)
+	return
 }
 
 func (h *Handler) CountStory(c echo.Context) (err error) {
@@ -137,13 +142,8 @@ func (h *Handler) CountStory(c echo.Context) (err error) {
 	var count int
 
 	// Get count of stories from database
-	userID := utility.UserIDFromToken(c)
-
-	db := h.DB.Clone()
-	defer db.Close()
-	if count, err = db.DB(DBName).C(STORY).
-		Find(bson.M{"author_id": bson.ObjectIdHex(userID)}).
-		Count(); err != nil {
+	user := auth.CurrentUser(c)
+	if count, err = h.Stories.Count(c.Request().Context(), repository.PostQuery{AuthorID: user.ID}); err != nil {
 		return
 	}
 
@@ -165,17 +165,12 @@ func (h *Handler) RetrieveStory(c echo.Context) (err error) {
 
 	// Map AuthorNickname
 	h.MapAuthorNickname(c, s)
+	MarkAuthor(c, s)
 
 	return c.JSON(http.StatusOK, s)
 }
 
 func (h *Handler) PatchStory(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
-		return
-	}
-
 	// Object bind
 	s := new(model.Post)
 	if err = c.Bind(s); err != nil {
@@ -194,7 +189,7 @@ func (h *Handler) PatchStory(c echo.Context) (err error) {
 		if err = h.UploadThumbnail(c, s, file); err != nil {
 			return
 		}
-		if err = h.PatchThumbnail(s); err != nil {
+		if err = h.PatchThumbnail(c.Request().Context(), s); err != nil {
 			return
 		}
 	}
@@ -205,33 +200,21 @@ func (h *Handler) PatchStory(c echo.Context) (err error) {
 	s.DateModified = c.FormValue("date_modified")
 	s.IsPublished, _ = strconv.ParseBool(c.FormValue("is_published"))
 	s.Category = c.FormValue("category")
+	s.Series = c.FormValue("series")
 
 	// Update story in database
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(STORY).
-		Update(
-		bson.M{"_id": s.ID},
-		bson.M{"$set":
-		bson.M{
-			"title":         s.Title,
-			"content":       s.Content,
-			"date_modified": s.DateModified,
-			"is_published":  s.IsPublished,
-			"category":      s.Category}}); err != nil {
+	if err = h.Stories.Update(c.Request().Context(), s,
+		"title", "content", "date_modified", "is_published", "category", "series"); err != nil {
 		return
 	}
 
+	// 글이 쓰는 파일 기록
+	h.trackPost(c, s)
+
 	return c.JSON(http.StatusOK, s)
 }
 
 func (h *Handler) ChangePublishStory(c echo.Context) (err error) {
-	// Find user in database
-	userID := utility.UserIDFromToken(c)
-	if err = h.FindUser(userID); err != nil {
-		return
-	}
-
 	// Object bind
 	s := new(model.Post)
 	if err = c.Bind(s); err != nil {
@@ -244,22 +227,36 @@ func (h *Handler) ChangePublishStory(c echo.Context) (err error) {
 	}
 
 	// Add FormValues in Post Instance
+	wasPublished := s.IsPublished
 	s.DateModified = c.FormValue("date_modified")
 	s.IsPublished, _ = strconv.ParseBool(c.FormValue("is_published"))
 
 	// Update story in database
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(STORY).
-		Update(
-		bson.M{"_id": s.ID},
-		bson.M{"$set":
-		bson.M{
-			"date_modified": s.DateModified,
-			"is_published":  s.IsPublished}}); err != nil {
+	if err = h.Stories.Update(c.Request().Context(), s, "date_modified", "is_published"); err != nil {
 		return
 	}
 
+	// 발행 상태가 바뀌었다면 저자에게 알림
+	if wasPublished != s.IsPublished {
+		notificationType := model.NotificationStoryUnpublished
+		message := "'" + s.Title + "' 스토리의 발행이 취소되었습니다"
+		if s.IsPublished {
+			notificationType = model.NotificationStoryPublished
+			message = "'" + s.Title + "' 스토리가 발행되었습니다"
+		}
+		if err := h.Notify(c.Request().Context(), s.AuthorID, notificationType, message, s.ID); err != nil {
+			slog.ErrorContext(c.Request().Context(), "알림을 저장하지 못했습니다", "story_id", s.ID.Hex(), "error", err)
+		}
+	}
+
+	// 새로 발행된 스토리는 접속 중인 방문자에게 알림
+	if !wasPublished && s.IsPublished {
+		h.Events.Publish(Event{
+			Type: EventStory,
+			Data: echo.Map{"id": s.ID, "title": s.Title, "author_id": s.AuthorID},
+		})
+	}
+
 	return c.JSON(http.StatusOK, s)
 }
 
@@ -276,12 +273,14 @@ func (h *Handler) DestroyStory(c echo.Context) (err error) {
 	}
 
 	// Destroy story in database
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(STORY).
-		Remove(bson.M{"_id": s.ID}); err != nil {
+	if err = h.Stories.Delete(c.Request().Context(), s.ID); err != nil {
 		return
 	}
 
+	// 글이 쓰던 파일의 참조 해제: 유예 기간이 지나면 정리 작업이 지운다
+	if err := h.TrackAssets(c.Request().Context(), s.ID); err != nil {
+		slog.ErrorContext(c.Request().Context(), "파일 참조를 해제하지 못했습니다", "ref_id", s.ID.Hex(), "error", err)
+	}
+
 	return c.NoContent(http.StatusNoContent)
 }
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	// Default package
+	"context"
+	"testing"
+	"net/url"
+	"net/http"
+	// Third Party package
+	"github.com/labstack/echo"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/repository"
+)
+
+func TestStory(t *testing.T) {
+	// 발행한 스토리만 공개 목록, 필진 목록, 내보내기에 나오고, 발행 상태가 바뀌면 저자에게 알린다
+	s := newTestServer(t)
+	ctx := context.Background()
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	author, authorToken := s.user("author@example.com", "author", true, false)
+	s.user("member@example.com", "member", false, false)
+
+	story := new(model.Post)
+	s.decode(s.form(http.MethodPost, "/story/", authorToken, http.StatusCreated, url.Values{
+		"title":        {"Story"},
+		"content":      {"<p>본문</p>"},
+		"date_created": {"2026-10-01"},
+		"series":       {"series"}}), story)
+	s.form(http.MethodPost, "/story/", authorToken, http.StatusBadRequest, url.Values{"title": {"내용 없음"}})
+	s.get("/story/", "", http.StatusBadRequest)
+	var stories []*model.Post
+	s.decode(s.get("/story/", authorToken, http.StatusOK), &stories)
+	if len(stories) != 1 || stories[0].ID != story.ID || stories[0].Content != "" {
+		t.Errorf("GET /story/ = %v, want 내용 없는 스토리 1편", stories)
+	}
+	if count := s.get("/story/count/", authorToken, http.StatusOK).Body.String(); count != "1" {
+		t.Errorf("GET /story/count/ = %s, want 1", count)
+	}
+
+	// 수정
+	s.form(http.MethodPatch, "/story/"+story.ID.Hex(), authorToken, http.StatusOK, url.Values{
+		"title":         {"Story"},
+		"content":       {"<p>고친 본문</p>"},
+		"date_modified": {"2026-10-02"},
+		"series":        {"series"}})
+	viewed := new(model.Post)
+	s.decode(s.get("/story/view/"+story.ID.Hex(), "", http.StatusOK), viewed)
+	if viewed.Content != "<p>고친 본문</p>" || viewed.DateModified != "2026-10-02" || viewed.AuthorNickname != "author" {
+		t.Errorf("GET /story/view/ = %+v", viewed)
+	}
+
+	// 발행 전에는 공개 목록에 나오지 않는다
+	s.decode(s.get("/story/client/", "", http.StatusOK), &stories)
+	if len(stories) != 0 {
+		t.Errorf("발행 전 GET /story/client/ = %d편", len(stories))
+	}
+	if count := s.get("/authors/count/"+author.ID.Hex(), "", http.StatusOK).Body.String(); count != "0" {
+		t.Errorf("발행 전 GET /authors/count/ = %s", count)
+	}
+
+	// 발행
+	s.form(http.MethodPatch, "/story/publish/"+story.ID.Hex(), adminToken, http.StatusOK, url.Values{"is_published": {"true"}})
+	if saved, err := s.h.Stories.Get(ctx, story.ID); err != nil || !saved.IsPublished {
+		t.Errorf("발행 상태를 저장하지 않았습니다: %v, %v", saved, err)
+	}
+	s.decode(s.get("/story/client/", "", http.StatusOK), &stories)
+	if len(stories) != 1 || stories[0].AuthorNickname != "author" {
+		t.Errorf("GET /story/client/ = %v, want 발행한 스토리 1편", stories)
+	}
+	notifications, err := s.h.Notifications.List(ctx, author.ID, 0, 10)
+	if err != nil || len(notifications) != 1 || notifications[0].Type != model.NotificationStoryPublished || notifications[0].TargetID != story.ID {
+		t.Errorf("발행 알림 = %v, %v", notifications, err)
+	}
+
+	// 필진
+	var authors []*model.User
+	s.decode(s.get("/authors/", "", http.StatusOK), &authors)
+	if len(authors) != 2 {
+		t.Errorf("GET /authors/ = %d명, want 필진 2명", len(authors))
+	}
+	s.decode(s.get("/authors/"+author.ID.Hex(), "", http.StatusOK), &stories)
+	if len(stories) != 1 || stories[0].ID != story.ID {
+		t.Errorf("GET /authors/:author_id = %v", stories)
+	}
+	s.get("/authors/not-an-id", "", http.StatusNotFound)
+	if count := s.get("/authors/count/"+author.ID.Hex(), "", http.StatusOK).Body.String(); count != "1" {
+		t.Errorf("GET /authors/count/ = %s, want 1", count)
+	}
+
+	// EPUB 내보내기
+	for _, target := range []string{
+		"/export/story/" + story.ID.Hex(),
+		"/export/series/" + author.ID.Hex() + "/series",
+		"/export/authors/" + author.ID.Hex(),
+	} {
+		if rec := s.get(target, "", http.StatusOK); rec.Header().Get(echo.HeaderContentType) != "application/epub+zip" {
+			t.Errorf("GET %s Content-Type = %q", target, rec.Header().Get(echo.HeaderContentType))
+		}
+	}
+
+	// 발행 취소도 알린다
+	s.form(http.MethodPatch, "/story/publish/"+story.ID.Hex(), adminToken, http.StatusOK, url.Values{"is_published": {"false"}})
+	if notifications, _ = s.h.Notifications.List(ctx, author.ID, 0, 10); len(notifications) != 2 {
+		t.Errorf("발행 취소 알림 = %v", notifications)
+	}
+
+	// 삭제
+	s.request(http.MethodDelete, "/story/"+story.ID.Hex(), authorToken, http.StatusNoContent, nil, "")
+	s.get("/story/view/"+story.ID.Hex(), "", http.StatusNotFound)
+	if _, err := s.h.Stories.Get(ctx, story.ID); err != repository.ErrNotFound {
+		t.Errorf("지운 스토리가 남아 있습니다: %v", err)
+	}
+}
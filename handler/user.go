@@ -2,6 +2,7 @@ package handler
 
 import (
 	// Default package
+	"context"
 	"strconv"
 	"math/rand"
 	"net/http"
@@ -9,10 +10,10 @@ import (
 	"crypto/sha256"
 	// Third-party package
 	"github.com/labstack/echo"
-	"github.com/globalsign/mgo"
-	"github.com/globalsign/mgo/bson"
 	// User package
+	"github.com/backend/auth"
 	"github.com/backend/model"
+	"github.com/backend/repository"
 	"github.com/backend/utility"
 )
 
@@ -26,7 +27,7 @@ func HashPassword(p string) string {
 	return hex.EncodeToString(sum[:])
 }
 
-func (h *Handler) CreateUser(u *model.User) (err error) {
+func (h *Handler) CreateUser(ctx context.Context, u *model.User) (err error) {
 	// 회원 생성 메소드
 	// Validation
 	if u.Email == "" || u.Password == "" || u.Nickname == "" {
@@ -36,15 +37,16 @@ func (h *Handler) CreateUser(u *model.User) (err error) {
 		}
 	}
 
+	// 메일을 받을 언어: 지원하지 않는 언어는 한국어
+	u.Locale = utility.MailLocale(u.Locale)
+
 	// 패스워드 해쉬 후 저장
 	newPassword := HashPassword(u.Password)
 	u.Password = newPassword
 
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).Insert(u); err != nil {
+	if err = h.Users.Create(ctx, u); err != nil {
 		// 만일 발생한 오류가 중복 오류라면 400 에러를 발생시킨다
-		if mgo.IsDup(err) {
+		if err == repository.ErrDuplicate {
 			return &echo.HTTPError{
 				Code:    http.StatusBadRequest,
 				Message: "이메일이나 닉네임이 이미 존재합니다",
@@ -57,102 +59,82 @@ func (h *Handler) CreateUser(u *model.User) (err error) {
 
 func (h *Handler) SignUpNormal(c echo.Context) (err error) {
 	// Object bind
-	u := &model.User{ID: bson.NewObjectId()}
+	// 가입 요청에서는 이메일, 닉네임, 패스워드, 언어만 받는다: 권한, 활성화, 용량은 클라이언트가 정할 수 없다
+	req := new(model.SignUp)
 	// Go 언어의 간단한 조건식:
 	// 조건문 이전에 반드시 실행되는 구문을 세미콜론으로 구분해
 	// if 문 안에서 실행하도록 한다
-	if err = c.Bind(u); err != nil {
+	if err = c.Bind(req); err != nil {
 		return
 	}
+	u := req.User()
 
 	// CreateUser 실행 시 에러 핸들링
-	if err = h.CreateUser(u); err != nil {
+	if err = h.CreateUser(c.Request().Context(), u); err != nil {
 		return
 	}
 
 	// Sending Email
-	// go routine 을 사용한 비동기 처리
-	go utility.SendActivationEmail(c, u) // 어차피 서버 메인 함수가 종료되는 일은 없으므로 WaitGroup 은 필요없음
-
-	//// WaitGroup 생성, 1개의 go routine 기다림
-	//var wait sync.WaitGroup
-	//wait.Add(1)
-	//// 비동기 익명 함수 안에서 이메일 전송 함수 실행
-	//go func() {
-	//	defer wait.Done() // 함수 실행이 끝나면 WaitGroup 에 함수 실행이 끝났음을 알림
-	//
-	//}()
-	//
-	//wait.Wait() // go routine 모두 끝날 때까지 대기
+	// outbox 에 저장하면 워커가 발송하고 실패 시 재시도한다
+	r, err := utility.NewActivationEmail(c, u)
+	if err != nil {
+		return
+	}
+	if err = h.Enqueue(c.Request().Context(), model.OutboxActivation, r); err != nil {
+		return
+	}
 
+	// 패스워드 해쉬는 응답에 넣지 않는다
+	u.Password = ""
 	return c.JSON(http.StatusCreated, u)
 }
 
 func (h *Handler) SignUpAdmin(c echo.Context) (err error) {
 	// Object bind
-	u := &model.User{ID: bson.NewObjectId()}
+	req := new(model.SignUp)
 	// Go 언어의 간단한 조건식:
 	// 조건문 이전에 반드시 실행되는 구문을 세미콜론으로 구분해
 	// if 문 안에서 실행하도록 한다
-	if err = c.Bind(u); err != nil {
-		return
-	}
-
-	// CreateUser 실행 시 에러 핸들링
-	if err = h.CreateUser(u); err != nil {
+	if err = c.Bind(req); err != nil {
 		return
 	}
+	u := req.User()
 
 	// 권한 부여
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		Update(
-		bson.M{"_id": u.ID},
-		bson.M{"$set":
-		bson.M{
-			"is_active": true,
-			"is_staff":  true,
-			"is_admin":  true}}); err != nil {
+	u.IsActive, u.IsStaff, u.IsAdmin = true, true, true
+
+	// CreateUser 실행 시 에러 핸들링
+	if err = h.CreateUser(c.Request().Context(), u); err != nil {
 		return
 	}
 
+	// 패스워드 해쉬는 응답에 넣지 않는다
+	u.Password = ""
 	return c.JSON(http.StatusCreated, u)
 }
 
 func (h *Handler) Activate(c echo.Context) (err error) {
-	// Object bind
-	// Signup 과 달리 비어 있는 객체를 생성
-	u := new(model.User)
-	if err = c.Bind(u); err != nil {
-		return
-	}
-
 	// Find user email
 	userEmail := c.Param("user_email")
 
 	// Find user
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		Find(bson.M{"email": userEmail}).One(u); err != nil {
-		if err == mgo.ErrNotFound {
+	u, err := h.Users.GetByEmail(c.Request().Context(), userEmail)
+	if err != nil {
+		if err == repository.ErrNotFound {
 			return echo.ErrNotFound
 		}
 		return
 	}
 
 	// Active user
-	if err = db.DB(DBName).C(USER).
-		Update(
-		bson.M{"email": userEmail},
-		bson.M{"$set":
-		bson.M{"is_active": true}}); err != nil {
+	u.IsActive = true
+	if err = h.Users.Update(c.Request().Context(), u, "is_active"); err != nil {
 		return
 	}
+	h.Principals.Invalidate(u.ID)
 
 	// 메인 페이지로 리다이렉트
-	return c.Redirect(http.StatusMovedPermanently, "https://www.somethingmore.co.kr")
+	return c.Redirect(http.StatusMovedPermanently, SiteURL)
 }
 
 func (h *Handler) SignIn(c echo.Context) (err error) {
@@ -168,11 +150,8 @@ func (h *Handler) SignIn(c echo.Context) (err error) {
 	comparePassword := HashPassword(u.Password)
 
 	// Find user
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		Find(bson.M{"email": u.Email, "password": comparePassword}).One(u); err != nil {
-		if err == mgo.ErrNotFound {
+	if u, err = h.Users.Authenticate(c.Request().Context(), u.Email, comparePassword); err != nil {
+		if err == repository.ErrNotFound {
 			return &echo.HTTPError{
 				Code:    http.StatusUnauthorized,
 				Message: "이메일이나 패스워드가 올바르지 않습니다",
@@ -189,6 +168,14 @@ func (h *Handler) SignIn(c echo.Context) (err error) {
 		}
 	}
 
+	// 이용이 정지된 회원
+	if u.IsBanned {
+		return &echo.HTTPError{
+			Code:    http.StatusForbidden,
+			Message: "이용이 정지된 회원입니다",
+		}
+	}
+
 	// Create JWT
 	token := utility.CreateJWT(u)
 
@@ -218,18 +205,12 @@ func (h *Handler) PatchPassword(c echo.Context) (err error) {
 		}
 	}
 
-	// Find userID & password
-	userID := utility.UserIDFromToken(c)
+	// Get current user & password
+	user := auth.CurrentUser(c)
 	patchedPassword := HashPassword(u.Password)
 
 	// Patch password from database
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		Update(
-		bson.M{"_id": bson.ObjectIdHex(userID)},
-		bson.M{"$set":
-		bson.M{"password": patchedPassword}}); err != nil {
+	if err = h.Users.Update(c.Request().Context(), &model.User{ID: user.ID, Password: patchedPassword}, "password"); err != nil {
 		return
 	}
 
@@ -251,19 +232,13 @@ func (h *Handler) PatchNickname(c echo.Context) (err error) {
 		}
 	}
 
-	// Find userID
-	userID := utility.UserIDFromToken(c)
+	// Get current user
+	user := auth.CurrentUser(c)
 
 	// Patch Nickname
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		Update(
-		bson.M{"_id": bson.ObjectIdHex(userID)},
-		bson.M{"$set":
-		bson.M{"nickname": u.Nickname}}); err != nil {
+	if err = h.Users.Update(c.Request().Context(), &model.User{ID: user.ID, Nickname: u.Nickname}, "nickname"); err != nil {
 		// 만일 발생한 오류가 중복 오류라면 400 에러를 발생시킨다
-		if mgo.IsDup(err) {
+		if err == repository.ErrDuplicate {
 			return &echo.HTTPError{
 				Code:    http.StatusBadRequest,
 				Message: "닉네임이 이미 존재합니다",
@@ -272,10 +247,11 @@ func (h *Handler) PatchNickname(c echo.Context) (err error) {
 		return
 	}
 
+	h.Principals.Invalidate(user.ID)
+
 	// Object 를 기존 DB 데이터로 Bind
-	if err = db.DB(DBName).C(USER).
-		FindId(bson.ObjectIdHex(userID)).One(u); err != nil {
-		if err == mgo.ErrNotFound {
+	if u, err = h.Users.Get(c.Request().Context(), user.ID); err != nil {
+		if err == repository.ErrNotFound {
 			return echo.ErrNotFound
 		}
 		return
@@ -294,22 +270,34 @@ func (h *Handler) PatchNickname(c echo.Context) (err error) {
 	return c.JSON(http.StatusOK, u.Token)
 }
 
-func (h *Handler) ResetPassword(c echo.Context) (err error) {
-	// Bind object
-	u := new(model.User)
-	if err = c.Bind(u); err != nil {
+func (h *Handler) PatchLocale(c echo.Context) (err error) {
+	user := auth.CurrentUser(c)
+
+	// Validation
+	locale := c.FormValue("locale")
+	if utility.MailLocale(locale) != locale {
+		return &echo.HTTPError{
+			Code:    http.StatusBadRequest,
+			Message: "지원하지 않는 언어입니다",
+		}
+	}
+
+	// Patch locale
+	if err = h.Users.Update(c.Request().Context(), &model.User{ID: user.ID, Locale: locale}, "locale"); err != nil {
 		return
 	}
 
+	return c.NoContent(http.StatusOK)
+}
+
+func (h *Handler) ResetPassword(c echo.Context) (err error) {
 	// Find user email
 	userEmail := c.Param("user_email")
 
 	// Find user
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		Find(bson.M{"email": userEmail}).One(u); err != nil {
-		if err == mgo.ErrNotFound {
+	u, err := h.Users.GetByEmail(c.Request().Context(), userEmail)
+	if err != nil {
+		if err == repository.ErrNotFound {
 			return echo.ErrNotFound
 		}
 		return
@@ -324,16 +312,18 @@ func (h *Handler) ResetPassword(c echo.Context) (err error) {
 	u.Password = randomPassword // 난수로 생성된 패스워드를 User 모델에 덮어 씌움
 
 	// Update DB
-	if err = db.DB(DBName).C(USER).
-		Update(
-		bson.M{"email": u.Email},
-		bson.M{"$set":
-		bson.M{"password": hashedPassword}}); err != nil {
+	if err = h.Users.Update(c.Request().Context(), &model.User{ID: u.ID, Password: hashedPassword}, "password"); err != nil {
 		return
 	}
 
 	// Sending Email
-	go utility.SendResetPasswordEmail(u)
+	r, err := utility.NewResetPasswordEmail(u, SiteURL)
+	if err != nil {
+		return
+	}
+	if err = h.Enqueue(c.Request().Context(), model.OutboxResetPassword, r); err != nil {
+		return
+	}
 
 	return c.NoContent(http.StatusOK)
 }
@@ -348,15 +338,16 @@ func (h *Handler) DestroyUser(c echo.Context) (err error) {
 	// Find password
 	comparePassword := HashPassword(u.Password)
 
-	// Find userID
-	userID := utility.UserIDFromToken(c)
+	// Get current user
+	user := auth.CurrentUser(c)
 
 	// Destroy user from database
-	db := h.DB.Clone()
-	defer db.Close()
-	if err = db.DB(DBName).C(USER).
-		Remove(bson.M{"_id": bson.ObjectIdHex(userID), "password": comparePassword}); err != nil {
-		if err == mgo.ErrNotFound {
+	// 패스워드를 확인한 뒤 지운다
+	if _, err = h.Users.Authenticate(c.Request().Context(), user.Email, comparePassword); err == nil {
+		err = h.Users.Delete(c.Request().Context(), user.ID)
+	}
+	if err != nil {
+		if err == repository.ErrNotFound {
 			return &echo.HTTPError{
 				Code:    http.StatusBadRequest,
 				Message: "계정을 찾을 수 없거나 패스워드가 틀렸습니다",
@@ -365,5 +356,10 @@ func (h *Handler) DestroyUser(c echo.Context) (err error) {
 		return
 	}
 
+	h.Principals.Invalidate(user.ID)
+
+	// 회원의 미디어 라이브러리 정리
+	h.releaseUserMedia(c, user.ID)
+
 	return c.NoContent(http.StatusNoContent)
 }
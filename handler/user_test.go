@@ -0,0 +1,169 @@
+package handler
+
+import (
+	// Default package
+	"regexp"
+	"context"
+	"testing"
+	"net/url"
+	"net/http"
+	// Third Party package
+	"github.com/labstack/echo"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/utility"
+	"github.com/backend/repository"
+)
+
+func TestSignUpIgnoresPrivileges(t *testing.T) {
+	// 가입 요청에 권한, 활성화, 이용 정지, 용량을 넣어도 일반 회원으로 가입된다
+	s := newTestServer(t)
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+
+	cases := []struct {
+		target string
+		token  string
+		email  string
+	}{
+		{"/sign-up/", "", "member@example.com"},
+		{"/admin/", adminToken, "admin2@example.com"},
+	}
+	for _, tc := range cases {
+		target, email := tc.target, tc.email
+		u := new(model.User)
+		s.decode(s.json(http.MethodPost, target, tc.token, http.StatusCreated, echo.Map{
+			"email":       email,
+			"nickname":    email,
+			"password":    "password",
+			"is_admin":    true,
+			"is_staff":    true,
+			"is_active":   true,
+			"is_banned":   true,
+			"media_quota": 1 << 40,
+		}), u)
+		if u.Password != "" {
+			t.Errorf("POST %s 응답에 패스워드 해쉬가 있습니다", target)
+		}
+
+		saved, err := s.h.Users.GetByEmail(context.Background(), email)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if saved.IsBanned || saved.MediaQuota != 0 {
+			t.Errorf("POST %s: is_banned = %v, media_quota = %d", target, saved.IsBanned, saved.MediaQuota)
+		}
+		if target == "/sign-up/" && (saved.IsAdmin || saved.IsStaff || saved.IsActive) {
+			t.Errorf("POST /sign-up/ 으로 권한을 얻었습니다: %+v", saved)
+		}
+	}
+
+	// 활성화하지 않은 회원은 로그인할 수 없고, 관리자 라우트도 쓸 수 없다
+	s.json(http.MethodPost, "/sign-in/", "", http.StatusUnauthorized, echo.Map{"email": "member@example.com", "password": "password"})
+	s.get("/activate/member@example.com", "", http.StatusMovedPermanently)
+	var token string
+	s.decode(s.json(http.MethodPost, "/sign-in/", "", http.StatusOK, echo.Map{"email": "member@example.com", "password": "password"}), &token)
+	s.get("/users/", token, http.StatusUnauthorized)
+	s.json(http.MethodPost, "/admin/", token, http.StatusUnauthorized, echo.Map{"email": "a@example.com", "nickname": "a", "password": "password"})
+}
+
+func TestUserAccount(t *testing.T) {
+	// 회원이 직접 바꾸는 값은 저장되고, 다시 로그인할 때 반영된다
+	s := newTestServer(t)
+	ctx := context.Background()
+	author, _ := s.user("author@example.com", "author", true, false)
+	s.json(http.MethodPost, "/sign-up/", "", http.StatusCreated, echo.Map{"email": "member@example.com", "nickname": "member", "password": "password"})
+	s.get("/activate/member@example.com", "", http.StatusMovedPermanently)
+	var token string
+	s.decode(s.json(http.MethodPost, "/sign-in/", "", http.StatusOK, echo.Map{"email": "member@example.com", "password": "password"}), &token)
+
+	// 비밀번호
+	s.json(http.MethodPatch, "/patch/", token, http.StatusOK, echo.Map{"password": "changed"})
+	s.json(http.MethodPost, "/sign-in/", "", http.StatusUnauthorized, echo.Map{"email": "member@example.com", "password": "password"})
+	s.json(http.MethodPost, "/sign-in/", "", http.StatusOK, echo.Map{"email": "member@example.com", "password": "changed"})
+
+	// 닉네임, 메일 언어, 공지사항 메일 수신 여부
+	s.json(http.MethodPatch, "/nickname/", token, http.StatusBadRequest, echo.Map{"nickname": ""})
+	s.decode(s.json(http.MethodPatch, "/nickname/", token, http.StatusOK, echo.Map{"nickname": "member2"}), &token)
+	s.form(http.MethodPatch, "/locale/", token, http.StatusOK, url.Values{"locale": {"en"}})
+	s.form(http.MethodPatch, "/subscription/", token, http.StatusOK, url.Values{"unsubscribed_notice": {"true"}})
+	u, err := s.h.Users.GetByEmail(ctx, "member@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Nickname != "member2" || u.Locale != "en" || !u.UnsubscribedNotice {
+		t.Errorf("nickname = %q, locale = %q, unsubscribed_notice = %v", u.Nickname, u.Locale, u.UnsubscribedNotice)
+	}
+
+	// 메일의 수신 거부 주소: GET 은 확인 화면만 보여주고 POST 로 수신을 거부한다
+	unsubscribe := "/unsubscribe/" + utility.SignValue(Key, author.ID.Hex())
+	s.get(unsubscribe, "", http.StatusOK)
+	if u, _ := s.h.Users.Get(ctx, author.ID); u.UnsubscribedNotice {
+		t.Error("GET 으로 수신을 거부했습니다")
+	}
+	s.request(http.MethodPost, unsubscribe, "", http.StatusOK, nil, "")
+	if u, _ := s.h.Users.Get(ctx, author.ID); !u.UnsubscribedNotice {
+		t.Error("POST 로 수신을 거부하지 않았습니다")
+	}
+	s.request(http.MethodPost, "/unsubscribe/"+utility.SignValue("other", author.ID.Hex()), "", http.StatusNotFound, nil, "")
+
+	// 비밀번호 초기화: 새 비밀번호를 메일로 보낸다
+	s.deliverOutbox()
+	s.mailer.Reset()
+	s.get("/reset/nobody@example.com", "", http.StatusNotFound)
+	s.get("/reset/member@example.com", "", http.StatusOK)
+	s.json(http.MethodPost, "/sign-in/", "", http.StatusUnauthorized, echo.Map{"email": "member@example.com", "password": "changed"})
+	s.deliverOutbox()
+	password := regexp.MustCompile(`\b\d{1,6}\b`).FindString(s.mailTo("member@example.com").Text)
+	s.json(http.MethodPost, "/sign-in/", "", http.StatusOK, echo.Map{"email": "member@example.com", "password": password})
+
+	// 탈퇴: 비밀번호가 맞아야 한다
+	s.json(http.MethodDelete, "/destroy/", token, http.StatusBadRequest, echo.Map{"password": "wrong"})
+	s.json(http.MethodDelete, "/destroy/", token, http.StatusNoContent, echo.Map{"password": password})
+	if _, err := s.h.Users.GetByEmail(ctx, "member@example.com"); err != repository.ErrNotFound {
+		t.Errorf("탈퇴한 회원이 남아 있습니다: %v", err)
+	}
+	s.get("/notifications/", token, http.StatusUnauthorized)
+}
+
+func TestAdminUsers(t *testing.T) {
+	// 관리자가 바꾼 권한과 이용 정지는 이미 발급한 토큰에도 바로 반영된다
+	s := newTestServer(t)
+	ctx := context.Background()
+	_, adminToken := s.user("admin@example.com", "admin", true, true)
+	member, memberToken := s.user("member@example.com", "member", false, false)
+	s.user("leaving@example.com", "leaving", false, false)
+
+	var users []*model.User
+	s.decode(s.get("/users/", adminToken, http.StatusOK), &users)
+	if len(users) != 3 {
+		t.Errorf("GET /users/ = %d명, want 3명", len(users))
+	}
+	s.get("/users/", memberToken, http.StatusUnauthorized)
+
+	// 권한 변경과 알림
+	s.json(http.MethodPatch, "/users/member@example.com", adminToken, http.StatusOK, echo.Map{"is_staff": true})
+	if u, _ := s.h.Users.Get(ctx, member.ID); !u.IsStaff || u.IsAdmin {
+		t.Errorf("is_staff = %v, is_admin = %v", u.IsStaff, u.IsAdmin)
+	}
+	var notifications []*model.Notification
+	s.decode(s.get("/notifications/", memberToken, http.StatusOK), &notifications)
+	if len(notifications) != 1 || notifications[0].Type != model.NotificationRoleChanged {
+		t.Errorf("권한 변경 알림 = %v", notifications)
+	}
+
+	// 이용 정지
+	s.form(http.MethodPatch, "/users/ban/admin@example.com", adminToken, http.StatusBadRequest, url.Values{"is_banned": {"true"}})
+	s.form(http.MethodPatch, "/users/ban/member@example.com", adminToken, http.StatusBadRequest, url.Values{"is_banned": {"yes"}})
+	s.form(http.MethodPatch, "/users/ban/member@example.com", adminToken, http.StatusOK, url.Values{"is_banned": {"true"}})
+	s.get("/notifications/", memberToken, http.StatusForbidden)
+	s.json(http.MethodPost, "/sign-in/", "", http.StatusForbidden, echo.Map{"email": "member@example.com", "password": "password"})
+	s.form(http.MethodPatch, "/users/ban/member@example.com", adminToken, http.StatusOK, url.Values{"is_banned": {"false"}})
+	s.get("/notifications/", memberToken, http.StatusOK)
+
+	// 강제 탈퇴
+	s.request(http.MethodDelete, "/users/leaving@example.com", adminToken, http.StatusNoContent, nil, "")
+	if _, err := s.h.Users.GetByEmail(ctx, "leaving@example.com"); err != repository.ErrNotFound {
+		t.Errorf("강제 탈퇴한 회원이 남아 있습니다: %v", err)
+	}
+	s.request(http.MethodDelete, "/users/leaving@example.com", adminToken, http.StatusNotFound, nil, "")
+}
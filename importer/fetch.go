@@ -0,0 +1,105 @@
+package importer
+
+import (
+	// Default package
+	"net"
+	"time"
+	"errors"
+	"context"
+	"net/url"
+	"net/http"
+)
+
+// 외부 이미지를 내려받는 클라이언트
+// 회원이 올린 글의 주소로 요청하므로 내부망(사설, 루프백, 링크 로컬 등) 주소로는 연결하지 않는다
+// 리다이렉트한 주소도 연결할 때마다 다시 확인하고, 환경 변수의 프록시는 쓰지 않는다
+var imageClient = &http.Client{
+	Timeout: 30 * time.Second,
+	Transport: &http.Transport{
+		Proxy:                 nil,
+		DialContext:           dialPublic,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 20 * time.Second,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	},
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 5 {
+			return errors.New("리다이렉트가 너무 많습니다")
+		}
+		return checkImageURL(req.URL)
+	},
+}
+
+var imageDialer = &net.Dialer{Timeout: 10 * time.Second}
+
+// 연결하지 않는 주소 범위: net.IP 의 IsPrivate, IsLoopback 등으로 확인할 수 없는 범위
+var blockedNetworks = parseCIDRs(
+	"0.0.0.0/8",       // 현재 네트워크
+	"100.64.0.0/10",   // CGNAT
+	"192.0.0.0/24",    // IETF 프로토콜 할당
+	"198.18.0.0/15",   // 벤치마크
+	"240.0.0.0/4",     // 예약
+	"64:ff9b::/96",    // NAT64: IPv4 주소로 바뀐다
+	"64:ff9b:1::/48",  // 로컬 NAT64
+	"2001::/32",       // Teredo
+	"2002::/16",       // 6to4
+)
+
+func parseCIDRs(cidrs ...string) (networks []*net.IPNet) {
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return
+}
+
+func checkImageURL(u *url.URL) error {
+	// http, https 주소만 내려받는다
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("http, https 주소의 이미지만 가져올 수 있습니다")
+	}
+	if u.Hostname() == "" {
+		return errors.New("이미지 주소에 호스트가 없습니다")
+	}
+	return nil
+}
+
+func dialPublic(ctx context.Context, network, address string) (net.Conn, error) {
+	// 호스트 이름을 직접 찾아 공개 주소인지 확인한 뒤 그 주소로 연결한다
+	// 확인한 뒤 다시 이름을 찾지 않으므로 DNS 응답을 바꿔 내부망으로 연결하게 할 수 없다
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, errors.New(host + ": 주소를 찾을 수 없습니다")
+	}
+	for _, addr := range addrs {
+		if !isPublicIP(addr.IP) {
+			return nil, errors.New(host + ": 내부망 주소로는 연결할 수 없습니다")
+		}
+	}
+	return imageDialer.DialContext(ctx, network, net.JoinHostPort(addrs[0].IP.String(), port))
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,184 @@
+package importer
+
+import (
+	// Default package
+	"io"
+	"fmt"
+	"path"
+	"time"
+	"context"
+	"strings"
+	"net/url"
+	"net/http"
+	"io/ioutil"
+	// Third Party package
+	"golang.org/x/net/html"
+)
+
+// 가져온 글의 작성일자 형식
+const DateFormat = time.RFC3339
+
+const (
+	MaxImageSize   = 10 << 20  // 외부 이미지, 압축 파일 안의 파일 하나의 최대 크기: 10MB
+	MaxArchiveSize = 100 << 20 // 압축 파일을 모두 풀었을 때의 최대 크기: 압축 폭탄 방지
+)
+
+var (
+	ErrTooLarge        = fmt.Errorf("파일이 %dMB 보다 큽니다", MaxImageSize>>20)
+	ErrArchiveTooLarge = fmt.Errorf("압축을 푼 크기가 %dMB 보다 큽니다", MaxArchiveSize>>20)
+)
+
+const (
+	FormatMarkdown  = "markdown"
+	FormatWordPress = "wordpress"
+)
+
+type Entry struct {
+	Title       string   `json:"title"`              // 글 제목
+	Content     string   `json:"-"`                  // HTML 로 변환된 본문
+	DateCreated string   `json:"date_created"`       // 원본 작성일자
+	Category    string   `json:"category"`           // 카테고리
+	Source      string   `json:"source"`             // 원본 파일명 또는 링크
+	Images      []*Image `json:"images"`             // 본문에 포함된 이미지
+	Warnings    []string `json:"warnings,omitempty"` // 가져오면서 발생한 경고
+}
+
+type Image struct {
+	Source string `json:"source"` // 본문에 적힌 원래 주소
+	Name   string `json:"name"`   // 저장할 파일명
+	Data   []byte `json:"-"`      // 압축 파일 안에서 읽은 이미지: 비어 있으면 Source 에서 내려받는다
+	Found  bool   `json:"found"`  // 이미지를 읽을 수 있는지 여부
+}
+
+type Report struct {
+	Format   string   `json:"format"`   // markdown 또는 wordpress
+	DryRun   bool     `json:"dry_run"`  // true 이면 아무것도 저장하지 않음
+	Total    int      `json:"total"`    // 발견된 글 수
+	Imported int      `json:"imported"` // 저장된 글 수
+	Skipped  int      `json:"skipped"`  // 건너뛴 글 수
+	Entries  []*Entry `json:"entries"`  // 글 목록
+}
+
+func (e *Entry) Warn(format string, args ...interface{}) {
+	e.Warnings = append(e.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (i *Image) Read(ctx context.Context) ([]byte, error) {
+	// 압축 파일 안의 이미지는 메모리에서, 그 외에는 원래 주소에서 내려받는다
+	if i.Data != nil {
+		return i.Data, nil
+	}
+
+	u, err := url.Parse(i.Source)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkImageURL(u); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := imageClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", i.Source, resp.Status)
+	}
+
+	// 최대 크기를 넘으면 잘라서 저장하지 않고 실패로 처리한다
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImageSize {
+		return nil, ErrTooLarge
+	}
+	return data, nil
+}
+
+func ReplaceImage(content, source, url string) string {
+	// 본문의 이미지 주소를 새로 저장된 주소로 교체
+	return strings.Replace(content, `"`+html.EscapeString(source)+`"`, `"`+url+`"`, -1)
+}
+
+func imageSources(content string) (sources []string) {
+	// 본문 HTML 에서 img 태그의 src 속성을 모두 찾는다
+	z := html.NewTokenizer(strings.NewReader(content))
+	seen := make(map[string]bool)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			return
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "img" {
+				continue
+			}
+			for hasAttr {
+				var key, val []byte
+				key, val, hasAttr = z.TagAttr()
+				if string(key) == "src" && len(val) > 0 && !seen[string(val)] {
+					seen[string(val)] = true
+					sources = append(sources, string(val))
+				}
+			}
+		}
+	}
+}
+
+func imageName(source string) string {
+	// 쿼리스트링을 제외한 경로의 마지막 부분을 파일명으로 사용
+	if u, err := url.Parse(source); err == nil {
+		return path.Base(u.Path)
+	}
+	return path.Base(source)
+}
+
+func isRemote(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+func parseDate(value string) (string, bool) {
+	// 블로그마다 다른 날짜 형식을 DateFormat 으로 통일
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02 15:04:05",
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04",
+		"2006-01-02",
+		time.RFC1123Z,
+		time.RFC1123,
+	}
+	value = strings.TrimSpace(value)
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(DateFormat), true
+		}
+	}
+	return "", false
+}
+
+func DetectFormat(fileName string) string {
+	// 확장자로 파일 형식을 추측: zip 은 마크다운, xml 은 WordPress
+	switch strings.ToLower(path.Ext(fileName)) {
+	case ".zip":
+		return FormatMarkdown
+	case ".xml":
+		return FormatWordPress
+	}
+	return ""
+}
+
+func Parse(format string, r io.ReaderAt, size int64) ([]*Entry, error) {
+	switch format {
+	case FormatMarkdown:
+		return ParseMarkdownZip(r, size)
+	case FormatWordPress:
+		return ParseWordPress(io.NewSectionReader(r, 0, size))
+	}
+	return nil, fmt.Errorf("지원하지 않는 형식입니다: %q", format)
+}
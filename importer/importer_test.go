@@ -0,0 +1,112 @@
+package importer
+
+import (
+	// Default package
+	"net"
+	"bytes"
+	"context"
+	"strings"
+	"net/http"
+	"archive/zip"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	blocked := []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.0.1", "169.254.169.254",
+		"100.64.0.1", "0.0.0.0", "::1", "fe80::1", "fc00::1", "::ffff:10.0.0.1", "64:ff9b::a9fe:a9fe",
+	}
+	for _, addr := range blocked {
+		if isPublicIP(net.ParseIP(addr)) {
+			t.Errorf("isPublicIP(%s) = true", addr)
+		}
+	}
+	for _, addr := range []string{"8.8.8.8", "2606:4700:4700::1111"} {
+		if !isPublicIP(net.ParseIP(addr)) {
+			t.Errorf("isPublicIP(%s) = false", addr)
+		}
+	}
+}
+
+func TestReadRejectsInternalAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("secret"))
+	}))
+	defer server.Close()
+
+	for _, source := range []string{server.URL + "/a.png", "file:///etc/passwd", "ftp://example.com/a.png"} {
+		image := &Image{Source: source}
+		if data, err := image.Read(context.Background()); err == nil {
+			t.Errorf("Read(%s) = %q, want error", source, data)
+		}
+	}
+}
+
+func TestReadRejectsRedirectToInternalAddress(t *testing.T) {
+	// 공개 주소에서 내부망으로 리다이렉트하는 경우: 리다이렉트마다 주소를 확인한다
+	req, _ := http.NewRequest(http.MethodGet, "http://127.0.0.1/latest/meta-data", nil)
+	if err := imageClient.CheckRedirect(req, []*http.Request{req}); err != nil {
+		t.Fatalf("CheckRedirect(http) = %v", err)
+	}
+	if _, err := dialPublic(context.Background(), "tcp", "127.0.0.1:80"); err == nil {
+		t.Error("dialPublic(127.0.0.1) succeeded")
+	}
+	req, _ = http.NewRequest(http.MethodGet, "file:///etc/passwd", nil)
+	if err := imageClient.CheckRedirect(req, []*http.Request{req}); err == nil {
+		t.Error("CheckRedirect(file) succeeded")
+	}
+}
+
+func zipArchive(t *testing.T, files map[string][]byte) *bytes.Reader {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for name, data := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(data)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return bytes.NewReader(buf.Bytes())
+}
+
+func TestParseMarkdownZipLimits(t *testing.T) {
+	post := []byte("---\ntitle: 제목\n---\n![](big.png)\n")
+
+	// 너무 큰 이미지는 잘라서 읽지 않고 경고를 남긴다
+	r := zipArchive(t, map[string][]byte{"post.md": post, "big.png": make([]byte, MaxImageSize+1)})
+	entries, err := ParseMarkdownZip(r, r.Size())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Images[0].Found || entries[0].Images[0].Data != nil {
+		t.Fatalf("oversized image was read: %+v", entries[0].Images[0])
+	}
+	if !strings.Contains(strings.Join(entries[0].Warnings, "\n"), ErrTooLarge.Error()) {
+		t.Errorf("warnings = %q", entries[0].Warnings)
+	}
+
+	// 너무 큰 마크다운 파일은 실패
+	r = zipArchive(t, map[string][]byte{"post.md": make([]byte, MaxImageSize+1)})
+	if _, err = ParseMarkdownZip(r, r.Size()); err == nil {
+		t.Error("oversized markdown was read")
+	}
+
+	// 압축을 푼 크기의 합이 너무 크면 실패
+	files := map[string][]byte{}
+	var content []string
+	for i := 0; i <= MaxArchiveSize/MaxImageSize; i++ {
+		name := strings.Repeat("a", i+1) + ".png"
+		files[name] = make([]byte, MaxImageSize)
+		content = append(content, "![]("+name+")")
+	}
+	files["post.md"] = []byte(strings.Join(content, "\n\n"))
+	r = zipArchive(t, files)
+	if _, err = ParseMarkdownZip(r, r.Size()); err != ErrArchiveTooLarge {
+		t.Errorf("ParseMarkdownZip = %v, want ErrArchiveTooLarge", err)
+	}
+}
@@ -0,0 +1,194 @@
+package importer
+
+import (
+	// Default package
+	"io"
+	"fmt"
+	"path"
+	"time"
+	"bytes"
+	"strings"
+	"io/ioutil"
+	"archive/zip"
+	// Third Party package
+	"gopkg.in/yaml.v2"
+	"github.com/russross/blackfriday/v2"
+)
+
+type frontMatter struct {
+	Title      string      `yaml:"title"`
+	Date       interface{} `yaml:"date"`
+	Category   string      `yaml:"category"`
+	Categories []string    `yaml:"categories"`
+	Draft      bool        `yaml:"draft"`
+}
+
+func ParseMarkdownZip(r io.ReaderAt, size int64) (entries []*Entry, err error) {
+	// 마크다운 파일과 이미지를 담은 zip 파일을 읽어 글 목록을 만든다
+	z, err := zip.NewReader(r, size)
+	if err != nil {
+		return
+	}
+
+	// 이미지 경로를 찾기 위해 압축 파일 안의 모든 파일을 경로로 색인
+	a := &archive{
+		files:     make(map[string]*zip.File),
+		read:      make(map[*zip.File][]byte),
+		remaining: MaxArchiveSize,
+	}
+	for _, f := range z.File {
+		a.files[path.Clean(f.Name)] = f
+	}
+
+	for _, f := range z.File {
+		ext := strings.ToLower(path.Ext(f.Name))
+		if f.FileInfo().IsDir() || (ext != ".md" && ext != ".markdown") {
+			continue
+		}
+		// macOS 가 압축할 때 만드는 메타데이터 파일은 무시
+		if strings.HasPrefix(f.Name, "__MACOSX/") || strings.HasPrefix(path.Base(f.Name), "._") {
+			continue
+		}
+
+		raw, err := a.readFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", f.Name, err)
+		}
+		e, err := parseMarkdown(f, raw, a)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return
+}
+
+func parseMarkdown(f *zip.File, raw []byte, a *archive) (*Entry, error) {
+	e := &Entry{Source: f.Name}
+
+	// Front matter 분리
+	meta, body := splitFrontMatter(raw)
+	fm := new(frontMatter)
+	if meta != nil {
+		if err := yaml.Unmarshal(meta, fm); err != nil {
+			e.Warn("front matter 를 읽을 수 없습니다: %v", err)
+		}
+	}
+
+	// 제목이 없으면 파일명을 제목으로 사용
+	e.Title = strings.TrimSpace(fm.Title)
+	if e.Title == "" {
+		e.Title = strings.TrimSuffix(path.Base(f.Name), path.Ext(f.Name))
+		e.Warn("제목이 없어 파일명을 제목으로 사용합니다")
+	}
+
+	// 작성일자가 없으면 파일의 수정 시각을 사용
+	switch date := fm.Date.(type) {
+	case time.Time:
+		e.DateCreated = date.Format(DateFormat)
+	case string:
+		if parsed, ok := parseDate(date); ok {
+			e.DateCreated = parsed
+		} else {
+			e.Warn("작성일자 %q 를 해석할 수 없습니다", date)
+		}
+	}
+	if e.DateCreated == "" {
+		e.DateCreated = f.Modified.Format(DateFormat)
+	}
+
+	e.Category = fm.Category
+	if e.Category == "" && len(fm.Categories) > 0 {
+		e.Category = fm.Categories[0]
+	}
+	if fm.Draft {
+		e.Warn("원본에서 초안으로 표시된 글입니다")
+	}
+
+	// 본문을 HTML 로 변환
+	e.Content = string(blackfriday.Run(body))
+
+	// 본문의 이미지를 압축 파일 안에서 찾는다
+	dir := path.Dir(f.Name)
+	for _, source := range imageSources(e.Content) {
+		image := &Image{Source: source, Name: imageName(source)}
+		if isRemote(source) {
+			image.Found = true
+		} else if zf, ok := a.files[path.Join(dir, source)]; ok {
+			data, err := a.readFile(zf)
+			if err == ErrArchiveTooLarge {
+				return nil, err
+			} else if err != nil {
+				e.Warn("이미지 %s 를 읽을 수 없습니다: %v", source, err)
+			} else {
+				image.Data = data
+				image.Found = true
+			}
+		} else {
+			e.Warn("이미지 %s 를 압축 파일에서 찾을 수 없습니다", source)
+		}
+		e.Images = append(e.Images, image)
+	}
+
+	return e, nil
+}
+
+func splitFrontMatter(raw []byte) (meta, body []byte) {
+	// --- 로 둘러싸인 YAML front matter 를 본문과 분리
+	raw = bytes.TrimPrefix(raw, []byte("\xef\xbb\xbf")) // UTF-8 BOM 제거
+	normalized := bytes.Replace(raw, []byte("\r\n"), []byte("\n"), -1)
+	if !bytes.HasPrefix(normalized, []byte("---\n")) {
+		return nil, normalized
+	}
+	rest := normalized[4:]
+	end := bytes.Index(rest, []byte("\n---"))
+	if end < 0 {
+		return nil, normalized
+	}
+	meta = rest[:end]
+	body = rest[end+4:]
+	if i := bytes.IndexByte(body, '\n'); i >= 0 {
+		body = body[i+1:]
+	} else {
+		body = nil
+	}
+	return
+}
+
+// 압축 파일 안의 파일을 읽는다: 압축을 푼 크기의 합이 MaxArchiveSize 를 넘지 않도록 남은 크기를 센다
+type archive struct {
+	files     map[string]*zip.File
+	read      map[*zip.File][]byte // 여러 글이 같은 이미지를 쓰면 한 번만 읽는다
+	remaining int64
+}
+
+func (a *archive) readFile(f *zip.File) ([]byte, error) {
+	if data, ok := a.read[f]; ok {
+		return data, nil
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	// 최대 크기를 넘으면 잘라서 읽지 않고 실패로 처리한다
+	limit := int64(MaxImageSize)
+	if a.remaining < limit {
+		limit = a.remaining
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(rc, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		if limit < MaxImageSize {
+			return nil, ErrArchiveTooLarge
+		}
+		return nil, ErrTooLarge
+	}
+	a.remaining -= int64(len(data))
+	a.read[f] = data
+	return data, nil
+}
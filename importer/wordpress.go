@@ -0,0 +1,118 @@
+package importer
+
+import (
+	// Default package
+	"io"
+	"strings"
+	"encoding/xml"
+)
+
+// WordPress WXR 파일의 구조
+// 네임스페이스 버전(1.0 ~ 1.2)에 상관없이 읽기 위해 wp 네임스페이스는 생략한다
+type wxr struct {
+	Items []wxrItem `xml:"channel>item"`
+}
+
+type wxrItem struct {
+	Title      string        `xml:"title"`
+	Link       string        `xml:"link"`
+	PubDate    string        `xml:"pubDate"`
+	Content    string        `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PostID     string        `xml:"post_id"`
+	PostDate   string        `xml:"post_date"`
+	PostType   string        `xml:"post_type"`
+	Status     string        `xml:"status"`
+	Categories []wxrCategory `xml:"category"`
+}
+
+type wxrCategory struct {
+	Domain string `xml:"domain,attr"`
+	Name   string `xml:",chardata"`
+}
+
+func ParseWordPress(r io.Reader) (entries []*Entry, err error) {
+	// WordPress 에서 내보낸 WXR 파일을 읽어 글 목록을 만든다
+	doc := new(wxr)
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	if err = decoder.Decode(doc); err != nil {
+		return
+	}
+
+	// 첨부 파일, 페이지 등은 건너뛰고 글만 가져온다
+	for _, item := range doc.Items {
+		if item.PostType != "post" {
+			continue
+		}
+		// 휴지통에 버린 글은 가져오지 않는다
+		if item.Status == "trash" {
+			continue
+		}
+		entries = append(entries, parseWordPressItem(item))
+	}
+	return
+}
+
+func parseWordPressItem(item wxrItem) *Entry {
+	e := &Entry{
+		Title:  strings.TrimSpace(item.Title),
+		Source: item.Link,
+	}
+	if e.Source == "" {
+		e.Source = "post_id:" + item.PostID
+	}
+
+	if e.Title == "" {
+		e.Title = "제목 없음"
+		e.Warn("제목이 없는 글입니다")
+	}
+	if item.Status != "publish" {
+		e.Warn("원본에서 %s 상태인 글입니다", item.Status)
+	}
+
+	// post_date 가 없으면 RSS 의 pubDate 를 사용
+	if date, ok := parseDate(item.PostDate); ok {
+		e.DateCreated = date
+	} else if date, ok := parseDate(item.PubDate); ok {
+		e.DateCreated = date
+	} else {
+		e.Warn("작성일자를 해석할 수 없습니다")
+	}
+
+	for _, category := range item.Categories {
+		if category.Domain == "category" {
+			e.Category = strings.TrimSpace(category.Name)
+			break
+		}
+	}
+
+	// WordPress 는 문단을 빈 줄로만 구분해서 저장하므로 p 태그로 감싸 준다
+	e.Content = autoParagraph(item.Content)
+
+	// 본문 이미지는 모두 원래 블로그에서 내려받는다
+	for _, source := range imageSources(e.Content) {
+		image := &Image{Source: source, Name: imageName(source), Found: isRemote(source)}
+		if !image.Found {
+			e.Warn("이미지 %s 의 주소가 올바르지 않습니다", source)
+		}
+		e.Images = append(e.Images, image)
+	}
+
+	return e
+}
+
+func autoParagraph(content string) string {
+	// 이미 블록 태그로 작성된 본문은 그대로 둔다
+	content = strings.TrimSpace(strings.Replace(content, "\r\n", "\n", -1))
+	if strings.Contains(content, "<p>") || strings.Contains(content, "<!-- wp:") {
+		return content
+	}
+
+	var paragraphs []string
+	for _, block := range strings.Split(content, "\n\n") {
+		if block = strings.TrimSpace(block); block != "" {
+			paragraphs = append(paragraphs, "<p>"+strings.Replace(block, "\n", "<br />\n", -1)+"</p>")
+		}
+	}
+	return strings.Join(paragraphs, "\n")
+}
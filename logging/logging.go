@@ -0,0 +1,146 @@
+package logging
+
+import (
+	// Default package
+	"io"
+	"strings"
+	"context"
+	"net/url"
+	"log/slog"
+	// Third Party package
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 로그 형식
+const (
+	FormatJSON = "json" // 로그 수집기용 (기본값)
+	FormatText = "text" // 로컬 개발용
+)
+
+// 민감한 값 대신 기록하는 문자열
+const Redacted = "[REDACTED]"
+
+// 이 이름이거나 이 이름으로 끝나는 키의 값은 기록하지 않는다
+var sensitiveKeys = []string{
+	"password",
+	"token",
+	"authorization",
+	"cookie",
+	"secret",
+	"secret_key",
+	"access_key",
+	"signing_key",
+	"api_key",
+	"signature", // 비공개 파일의 서명된 주소
+}
+
+type ctxKey int
+
+const (
+	requestIDKey ctxKey = iota
+	userIDKey
+)
+
+func New(w io.Writer, format string, level slog.Level) *slog.Logger {
+	// 요청 ID 와 회원 ID 를 붙이고 민감한 값을 가리는 로거
+	opts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	}
+	var h slog.Handler
+	if format == FormatText {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+	return slog.New(&contextHandler{h})
+}
+
+func ParseLevel(s string) (level slog.Level, err error) {
+	// debug, info, warn, error
+	err = level.UnmarshalText([]byte(s))
+	return
+}
+
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+func WithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, userIDKey, id)
+}
+
+func UserID(ctx context.Context) string {
+	id, _ := ctx.Value(userIDKey).(string)
+	return id
+}
+
+func Detach(ctx context.Context) context.Context {
+	// 응답을 보낸 뒤에도 이어지는 작업용: 요청 ID 와 회원 ID 는 남기고 취소는 따르지 않는다
+	return context.WithoutCancel(ctx)
+}
+
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if key == s || strings.HasSuffix(key, "_"+s) || strings.HasSuffix(key, "-"+s) {
+			return true
+		}
+	}
+	return false
+}
+
+func RedactURL(rawURL string) string {
+	// 쿼리에 담긴 토큰 등을 가린다: 실시간 이벤트는 token 쿼리로 인증한다
+	u, err := url.Parse(rawURL)
+	if err != nil || u.RawQuery == "" {
+		return rawURL
+	}
+	q := u.Query()
+	for key := range q {
+		if IsSensitive(key) {
+			q.Set(key, Redacted)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if IsSensitive(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+	return a
+}
+
+// context 에 담긴 요청 ID, 회원 ID, 추적 ID 를 모든 로그에 붙인다
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestID(ctx); id != "" {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	if id := UserID(ctx); id != "" {
+		r.AddAttrs(slog.String("user_id", id))
+	}
+	// 추적 수집기에서 같은 요청의 스팬을 찾을 수 있도록 한다
+	if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{h.Handler.WithGroup(name)}
+}
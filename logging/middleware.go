@@ -0,0 +1,136 @@
+package logging
+
+import (
+	// Default package
+	"fmt"
+	"time"
+	"regexp"
+	"strings"
+	"net/url"
+	"log/slog"
+	"crypto/rand"
+	"runtime/debug"
+	"encoding/hex"
+	// Third Party package
+	"github.com/labstack/echo"
+)
+
+// 클라이언트나 프록시가 보낸 요청 ID 는 이 형식일 때만 이어서 쓴다
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+func RequestIDMiddleware() echo.MiddlewareFunc {
+	// 요청마다 ID 를 정해 응답 헤더와 context 에 담는다
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(echo.HeaderXRequestID)
+			if !validRequestID.MatchString(id) {
+				id = NewRequestID()
+			}
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			c.SetRequest(c.Request().WithContext(WithRequestID(c.Request().Context(), id)))
+			return next(c)
+		}
+	}
+}
+
+func UserMiddleware(userID func(c echo.Context) string) echo.MiddlewareFunc {
+	// 인증한 회원의 ID 를 context 에 담는다: JWT 미들웨어 뒤에 둔다
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if id := userID(c); id != "" {
+				c.SetRequest(c.Request().WithContext(WithUserID(c.Request().Context(), id)))
+			}
+			return next(c)
+		}
+	}
+}
+
+func RequestLogger(userID func(c echo.Context) string) echo.MiddlewareFunc {
+	// 요청마다 한 줄씩 기록하고, 패닉은 스택과 함께 기록한 뒤 500 으로 응답한다
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			start := time.Now()
+			req := c.Request()
+			res := c.Response()
+
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						slog.ErrorContext(c.Request().Context(), "panic",
+							"panic", fmt.Sprint(r),
+							"stack", string(debug.Stack()))
+						err = fmt.Errorf("panic: %v", r)
+					}
+				}()
+				err = next(c)
+			}()
+			if err != nil {
+				c.Error(err)
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case res.Status >= 500:
+				level = slog.LevelError
+			case res.Status >= 400:
+				level = slog.LevelWarn
+			}
+
+			attrs := []slog.Attr{
+				slog.String("method", req.Method),
+				slog.String("route", c.Path()),
+				slog.String("uri", RedactRequestURI(c)),
+				slog.Int("status", res.Status),
+				slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+				slog.Int64("bytes_out", res.Size),
+				slog.String("remote_ip", c.RealIP()),
+				slog.String("user_agent", req.UserAgent()),
+			}
+			// 라우트 미들웨어에서 인증한 경우에도 회원 ID 를 남긴다
+			if UserID(c.Request().Context()) == "" {
+				if id := userID(c); id != "" {
+					attrs = append(attrs, slog.String("user_id", id))
+				}
+			}
+			if err != nil {
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+			slog.LogAttrs(c.Request().Context(), level, "request", attrs...)
+			return nil
+		}
+	}
+}
+
+func RedactRequestURI(c echo.Context) string {
+	// 쿼리 값과 함께 이름이 민감한 경로 변수(/unsubscribe/:token 등)의 값도 가린다
+	u, err := url.Parse(RedactURL(c.Request().RequestURI))
+	if err != nil {
+		return Redacted
+	}
+	sensitive := false
+	for _, name := range c.ParamNames() {
+		sensitive = sensitive || IsSensitive(name)
+	}
+	if !sensitive {
+		return u.String()
+	}
+
+	// 라우트와 요청 경로는 / 로 나눈 순서가 같다
+	segments := strings.Split(u.EscapedPath(), "/")
+	for i, part := range strings.Split(c.Path(), "/") {
+		if i < len(segments) && strings.HasPrefix(part, ":") && IsSensitive(part[1:]) {
+			segments[i] = Redacted
+		}
+	}
+	uri := strings.Join(segments, "/")
+	if u.RawQuery != "" {
+		uri += "?" + u.RawQuery
+	}
+	return uri
+}
+
+func NewRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
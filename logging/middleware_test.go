@@ -0,0 +1,36 @@
+package logging
+
+import (
+	// Default package
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	// Third Party package
+	"github.com/labstack/echo"
+)
+
+func TestRedactRequestURI(t *testing.T) {
+	e := echo.New()
+	var got string
+	capture := func(c echo.Context) error {
+		got = RedactRequestURI(c)
+		return nil
+	}
+	e.GET("/unsubscribe/:token", capture)
+	e.GET("/newsletter/confirm/:token", capture)
+	e.GET("/private/*", capture)
+	e.GET("/story/view/:story_id", capture)
+
+	cases := map[string]string{
+		"/unsubscribe/abc.def":                    "/unsubscribe/" + Redacted,
+		"/newsletter/confirm/abc?utm=mail":        "/newsletter/confirm/" + Redacted + "?utm=mail",
+		"/private/a/b.pdf?expires=1&signature=xy": "/private/a/b.pdf?expires=1&signature=%5BREDACTED%5D",
+		"/story/view/5f1?token=abc":               "/story/view/5f1?token=%5BREDACTED%5D",
+	}
+	for uri, want := range cases {
+		e.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, uri, nil))
+		if got != want {
+			t.Errorf("RedactRequestURI(%s) = %s, want %s", uri, got, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	// Default package
+	"sync"
+	"time"
+	"strconv"
+	"net/http"
+	"crypto/subtle"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// 지표 이름의 접두어
+const Namespace = "stmore"
+
+// 서버의 모든 지표를 담는 레지스트리: 기본 레지스트리와 섞이지 않도록 따로 만든다
+var Registry = prometheus.NewRegistry()
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "http_requests_total",
+		Help:      "라우트별 HTTP 요청 수",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "라우트별 HTTP 요청 처리 시간",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	MongoDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Name:      "mongo_operation_duration_seconds",
+		Help:      "컬렉션별 MongoDB 작업 시간",
+		Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"collection", "operation"})
+
+	MongoErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "mongo_operation_errors_total",
+		Help:      "컬렉션별 MongoDB 작업 실패 수 (찾는 문서가 없는 경우는 제외)",
+	}, []string{"collection", "operation"})
+
+	MailSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "mail_sent_total",
+		Help:      "종류별 메일 발송 결과",
+	}, []string{"kind", "result"})
+
+	UploadBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "upload_bytes_total",
+		Help:      "종류별 업로드된 파일 크기",
+	}, []string{"kind"})
+
+	BackgroundJobs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "background_jobs",
+		Help:      "진행 중인 백그라운드 작업 수",
+	}, []string{"job"})
+
+	EventSubscribers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "event_subscribers",
+		Help:      "실시간 이벤트 스트림 연결 수",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		MongoDuration,
+		MongoErrors,
+		MailSent,
+		UploadBytes,
+		BackgroundJobs,
+		EventSubscribers,
+	)
+}
+
+func RegisterQueue(queue string, depth func() float64) {
+	// 대기열 길이: 지표를 수집할 때마다 depth 를 호출한다
+	Registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace:   Namespace,
+		Name:        "queue_depth",
+		Help:        "대기열에 쌓인 작업 수",
+		ConstLabels: prometheus.Labels{"queue": queue},
+	}, depth))
+}
+
+func ObserveMongo(collection string, operation string, start time.Time, err error) {
+	MongoDuration.WithLabelValues(collection, operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		MongoErrors.WithLabelValues(collection, operation).Inc()
+	}
+}
+
+func ObserveMail(kind string, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	MailSent.WithLabelValues(kind, result).Inc()
+}
+
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+func TokenAuth(token string) echo.MiddlewareFunc {
+	// Authorization: Bearer <token> 헤더를 요구한다: 토큰이 비어 있으면 검사하지 않는다 (개발 환경)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if token == "" {
+				return next(c)
+			}
+			auth := c.Request().Header.Get(echo.HeaderAuthorization)
+			if subtle.ConstantTimeCompare([]byte(auth), []byte("Bearer "+token)) != 1 {
+				return echo.ErrUnauthorized
+			}
+			return next(c)
+		}
+	}
+}
+
+func Middleware() echo.MiddlewareFunc {
+	// 라우트 경로(/story/view/:story_id)별로 요청 수와 처리 시간을 기록한다
+	// 등록된 라우트는 서버가 요청을 받기 전에 모두 정해지므로 첫 요청에서 한 번만 모은다
+	var (
+		once   sync.Once
+		routes map[string]bool
+	)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			once.Do(func() {
+				routes = map[string]bool{}
+				for _, r := range c.Echo().Routes() {
+					routes[r.Path] = true
+				}
+			})
+
+			start := time.Now()
+			err = next(c)
+
+			// 에러를 처리하기 전이라면 상태 코드를 에러에서 구한다
+			status := c.Response().Status
+			if err != nil {
+				status = http.StatusInternalServerError
+				if he, ok := err.(*echo.HTTPError); ok {
+					status = he.Code
+				}
+			}
+
+			// 라우트가 없는 요청은 경로 대신 요청 주소가 담기므로 하나로 묶어 레이블 수가 늘어나지 않게 한다
+			route := c.Path()
+			if !routes[route] {
+				route = "unmatched"
+			}
+
+			method := c.Request().Method
+			HTTPRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+			HTTPDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+			return
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package model
+
+import "github.com/globalsign/mgo/bson"
+
+type (
+	Anthology struct {
+		ID           bson.ObjectId      `json:"id" bson:"_id,omitempty"`
+		Title        string             `json:"title" bson:"title"`
+		Year         int                `json:"year" bson:"year"`
+		Preface      string             `json:"preface" bson:"preface"`
+		Colophon     string             `json:"colophon" bson:"colophon"`
+		Sections     []AnthologySection `json:"sections" bson:"sections"`
+		DateCreated  string             `json:"date_created" bson:"date_created"`
+		DateModified string             `json:"date_modified" bson:"date_modified"`
+	}
+
+	AnthologySection struct {
+		Title    string          `json:"title" bson:"title"`
+		StoryIDs []bson.ObjectId `json:"story_ids" bson:"story_ids"`
+	}
+)
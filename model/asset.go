@@ -0,0 +1,30 @@
+package model
+
+import (
+	// Default package
+	"time"
+	// Third Party package
+	"github.com/globalsign/mgo/bson"
+)
+
+type (
+	// 저장소에 올린 파일과 그 파일을 쓰는 글, 미디어
+	Asset struct {
+		ID           bson.ObjectId   `json:"id" bson:"_id,omitempty"`
+		Key          string          `json:"key" bson:"key"`
+		OwnerID      bson.ObjectId   `json:"owner_id,omitempty" bson:"owner_id,omitempty"`
+		Size         int64           `json:"size" bson:"size"`
+		Refs         []bson.ObjectId `json:"refs" bson:"refs"`                                       // 참조하는 스토리, 게시글, 공지사항, 미디어 ID
+		DateCreated  time.Time       `json:"date_created" bson:"date_created"`
+		DateOrphaned *time.Time      `json:"date_orphaned,omitempty" bson:"date_orphaned,omitempty"` // 참조가 모두 사라진 시각
+	}
+
+	// 정리 대상 파일 리포트
+	AssetReport struct {
+		DryRun  bool     `json:"dry_run"`
+		Count   int      `json:"count"`
+		Size    int64    `json:"size"`
+		Assets  []*Asset `json:"assets"`
+		Blocked string   `json:"blocked,omitempty"` // 정리를 멈춘 이유: 미리 보기에서만 채운다
+	}
+)
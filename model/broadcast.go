@@ -0,0 +1,37 @@
+package model
+
+import (
+	// Default package
+	"time"
+	// Third Party package
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	BroadcastPending     = "pending"     // 발송 대기
+	BroadcastSending     = "sending"     // 발송 중
+	BroadcastDone        = "done"        // 발송 완료
+	BroadcastInterrupted = "interrupted" // 서버 종료로 중단: 남은 메일은 outbox 에 넘겼다
+)
+
+type (
+	Broadcast struct {
+		ID           bson.ObjectId      `json:"id" bson:"_id,omitempty"`
+		NoticeID     bson.ObjectId      `json:"notice_id" bson:"notice_id"`
+		Role         string             `json:"role" bson:"role"`
+		Status       string             `json:"status" bson:"status"`
+		Total        int                `json:"total" bson:"total"`
+		Sent         int                `json:"sent" bson:"sent"`
+		Failed       int                `json:"failed" bson:"failed"`
+		Skipped      int                `json:"skipped" bson:"skipped"`
+		Queued       int                `json:"queued" bson:"queued"` // 발송을 중단하며 outbox 에 넘긴 메일 수
+		Failures     []BroadcastFailure `json:"failures" bson:"failures"`
+		DateCreated  time.Time          `json:"date_created" bson:"date_created"`
+		DateFinished *time.Time         `json:"date_finished,omitempty" bson:"date_finished,omitempty"`
+	}
+
+	BroadcastFailure struct {
+		Email string `json:"email" bson:"email"`
+		Error string `json:"error" bson:"error"`
+	}
+)
@@ -0,0 +1,34 @@
+package model
+
+import (
+	// Default package
+	"time"
+	// Third Party package
+	"github.com/globalsign/mgo/bson"
+)
+
+type (
+	// 필진이 본문에 넣기 위해 올린 이미지
+	Media struct {
+		ID          bson.ObjectId    `json:"id" bson:"_id,omitempty"`
+		OwnerID     bson.ObjectId    `json:"owner_id" bson:"owner_id"`
+		FileName    string           `json:"file_name" bson:"file_name"` // 업로드한 파일명
+		Content     ThumbnailVariant `json:"content" bson:"content"`     // 본문에 넣을 크기
+		Card        ThumbnailVariant `json:"card" bson:"card"`           // 라이브러리 목록용 작은 크기
+		Size        int64            `json:"size" bson:"size"`           // 저장한 파일 크기의 합: 용량 제한에 사용
+		Alt         string           `json:"alt" bson:"alt"`             // 대체 텍스트
+		Caption     string           `json:"caption" bson:"caption"`     // 설명
+		Markup      string           `json:"markup" bson:"-"`            // 본문에 붙여 넣을 HTML
+		DateCreated time.Time        `json:"date_created" bson:"date_created"`
+	}
+
+	// 회원별 미디어 사용량
+	MediaUsage struct {
+		UserID   bson.ObjectId `json:"user_id" bson:"_id"`
+		Email    string        `json:"email" bson:"email"`
+		Nickname string        `json:"nickname" bson:"nickname"`
+		Count    int           `json:"count" bson:"count"`
+		Size     int64         `json:"size" bson:"size"`
+		Quota    int64         `json:"quota" bson:"quota"`
+	}
+)
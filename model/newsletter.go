@@ -0,0 +1,34 @@
+package model
+
+import (
+	// Default package
+	"time"
+	// Third Party package
+	"github.com/globalsign/mgo/bson"
+)
+
+type (
+	Subscriber struct {
+		ID              bson.ObjectId `json:"id" bson:"_id,omitempty"`
+		Email           string        `json:"email" bson:"email"`
+		Locale          string        `json:"locale" bson:"locale"`
+		IsConfirmed     bool          `json:"is_confirmed" bson:"is_confirmed"`
+		DateCreated     time.Time     `json:"date_created" bson:"date_created"`
+		DateConfirmed   *time.Time    `json:"date_confirmed,omitempty" bson:"date_confirmed,omitempty"`
+		DateConfirmSent *time.Time    `json:"-" bson:"date_confirm_sent,omitempty"` // 마지막으로 확인 메일을 보낸 시각
+	}
+
+	NewsletterIssue struct {
+		ID           bson.ObjectId   `json:"id" bson:"_id,omitempty"`
+		Subject      string          `json:"subject" bson:"subject"`
+		Intro        string          `json:"intro" bson:"intro"`
+		StoryIDs     []bson.ObjectId `json:"story_ids" bson:"story_ids"`
+		Status       string          `json:"status" bson:"status"`
+		Total        int             `json:"total" bson:"total"`
+		Sent         int             `json:"sent" bson:"sent"`
+		Failed       int             `json:"failed" bson:"failed"`
+		Queued       int             `json:"queued" bson:"queued"` // 발송을 중단하며 outbox 에 넘긴 메일 수
+		DateCreated  time.Time       `json:"date_created" bson:"date_created"`
+		DateFinished *time.Time      `json:"date_finished,omitempty" bson:"date_finished,omitempty"`
+	}
+)
@@ -0,0 +1,35 @@
+package model
+
+import (
+	// Default package
+	"time"
+	// Third Party package
+	"github.com/globalsign/mgo/bson"
+)
+
+// 알림 종류
+const (
+	NotificationStoryPublished   = "story_published"   // 스토리 발행 승인
+	NotificationStoryUnpublished = "story_unpublished" // 스토리 발행 취소
+	NotificationRoleChanged      = "role_changed"      // 권한 변경
+	NotificationNotice           = "notice"            // 새 공지사항
+)
+
+var NotificationTypes = []string{
+	NotificationStoryPublished,
+	NotificationStoryUnpublished,
+	NotificationRoleChanged,
+	NotificationNotice,
+}
+
+type (
+	Notification struct {
+		ID          bson.ObjectId `json:"id" bson:"_id,omitempty"`
+		UserID      bson.ObjectId `json:"user_id" bson:"user_id"`
+		Type        string        `json:"type" bson:"type"`
+		Message     string        `json:"message" bson:"message"`
+		TargetID    bson.ObjectId `json:"target_id,omitempty" bson:"target_id,omitempty"`
+		IsRead      bool          `json:"is_read" bson:"is_read"`
+		DateCreated time.Time     `json:"date_created" bson:"date_created"`
+	}
+)
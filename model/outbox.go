@@ -0,0 +1,44 @@
+package model
+
+import (
+	// Default package
+	"time"
+	// Third Party package
+	"github.com/globalsign/mgo/bson"
+)
+
+const (
+	OutboxPending = "pending" // 발송 대기: 실패한 메일도 다음 시도 시각까지 대기
+	OutboxSending = "sending" // 워커가 발송 중
+	OutboxSent    = "sent"    // 발송 완료
+	OutboxDead    = "dead"    // 재시도 횟수를 모두 써서 포기한 메일
+)
+
+// 메일 종류
+const (
+	OutboxActivation        = "activation"
+	OutboxResetPassword     = "reset_password"
+	OutboxNewsletterConfirm = "newsletter_confirm"
+	OutboxNotice            = "notice"     // 서버 종료로 중단한 공지사항 메일
+	OutboxNewsletter        = "newsletter" // 서버 종료로 중단한 소식지
+)
+
+type (
+	OutboxMessage struct {
+		ID          bson.ObjectId `json:"id" bson:"_id,omitempty"`
+		Kind        string        `json:"kind" bson:"kind"`
+		To          string        `json:"to" bson:"to"`
+		Subject     string        `json:"subject" bson:"subject"`
+		Body        string        `json:"-" bson:"body"` // 렌더링된 본문: 임시 패스워드가 들어 있을 수 있으므로 응답에는 넣지 않는다
+		Text        string        `json:"-" bson:"text"` // 텍스트 본문
+		Unsubscribe string        `json:"-" bson:"unsubscribe,omitempty"` // List-Unsubscribe 주소
+		Status      string        `json:"status" bson:"status"`
+		Attempts    int           `json:"attempts" bson:"attempts"`
+		LastError   string        `json:"last_error,omitempty" bson:"last_error,omitempty"`
+		NextAttempt time.Time     `json:"next_attempt" bson:"next_attempt"`
+		LockedUntil *time.Time    `json:"-" bson:"locked_until,omitempty"`
+		DateCreated time.Time     `json:"date_created" bson:"date_created"`
+		DateSent    *time.Time    `json:"date_sent,omitempty" bson:"date_sent,omitempty"`
+		RequestID   string        `json:"request_id,omitempty" bson:"request_id,omitempty"` // 메일을 만든 요청: 로그를 이어서 찾을 때 사용
+	}
+)
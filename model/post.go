@@ -1,6 +1,11 @@
 package model
 
-import "github.com/globalsign/mgo/bson"
+import (
+	// Default package
+	"time"
+	// Third Party package
+	"github.com/globalsign/mgo/bson"
+)
 
 type (
 	Post struct {
@@ -14,5 +19,31 @@ type (
 		Content        string        `json:"content" bson:"content"`
 		IsPublished    bool          `json:"is_published" bson:"is_published"`
 		Category       string           `json:"category" bson:"category"`
+		Series         string        `json:"series" bson:"series"`
+		IsPinned       bool          `json:"is_pinned" bson:"is_pinned,omitempty"`
+		PinOrder       int           `json:"pin_order" bson:"pin_order,omitempty"`
+		ExpiresAt      *time.Time    `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+		Thumbnails     *Thumbnails   `json:"thumbnails,omitempty" bson:"thumbnails,omitempty"`
+		IsAuthor       bool          `json:"is_author,omitempty" bson:"-"` // 로그인한 회원이 쓴 글인지 여부: 선택 인증 라우트에서만 채운다
+	}
+
+	// 크기별 썸네일: Thumbnail 에는 상세 페이지용(hero) 주소가 들어 있다
+	Thumbnails struct {
+		Card ThumbnailVariant `json:"card" bson:"card"` // 목록 카드
+		Hero ThumbnailVariant `json:"hero" bson:"hero"` // 상세 페이지 상단
+		OG   ThumbnailVariant `json:"og" bson:"og"`     // Open Graph 공유 이미지
+	}
+
+	ThumbnailVariant struct {
+		URL    string `json:"url" bson:"url"`                       // JPEG 또는 PNG
+		WebP   string `json:"webp,omitempty" bson:"webp,omitempty"` // 같은 크기의 WebP
+		Width  int    `json:"width" bson:"width"`
+		Height int    `json:"height" bson:"height"`
+	}
+
+	// 고정 공지와 일반 공지를 나누어 담는 목록 응답
+	NoticeList struct {
+		Pinned  []*Post `json:"pinned"`
+		Notices []*Post `json:"notices"`
 	}
 )
@@ -4,13 +4,37 @@ import "github.com/globalsign/mgo/bson"
 
 type (
 	User struct {
-		ID       bson.ObjectId `json:"id" bson:"_id,omitempty"`
-		Email    string        `json:"email" bson:"email,omitempty"`
-		Nickname string        `json:"nickname" bson:"nickname,omitempty"`
-		Password string        `json:"password" bson:"password,omitempty"`
-		Token    string        `json:"token,omitempty" bson:"-"`
-		IsActive bool          `json:"is_active" bson:"is_active"`
-		IsAdmin  bool          `json:"is_admin" bson:"is_admin"`
-		IsStaff  bool          `json:"is_staff" bson:"is_staff"`
+		ID                 bson.ObjectId `json:"id" bson:"_id,omitempty"`
+		Email              string        `json:"email" bson:"email,omitempty"`
+		Nickname           string        `json:"nickname" bson:"nickname,omitempty"`
+		Password           string        `json:"password" bson:"password,omitempty"`
+		Token              string        `json:"token,omitempty" bson:"-"`
+		IsActive           bool          `json:"is_active" bson:"is_active"`
+		IsAdmin            bool          `json:"is_admin" bson:"is_admin"`
+		IsStaff            bool          `json:"is_staff" bson:"is_staff"`
+		IsBanned           bool          `json:"is_banned" bson:"is_banned"` // 이용 정지: 로그인과 인증이 필요한 요청을 거부한다
+		UnsubscribedNotice bool          `json:"unsubscribed_notice" bson:"unsubscribed_notice"`
+		MutedNotifications []string      `json:"muted_notifications" bson:"muted_notifications,omitempty"`
+		Locale             string        `json:"locale" bson:"locale,omitempty"`
+		MediaQuota         int64         `json:"media_quota,omitempty" bson:"media_quota,omitempty"` // 0 이면 기본 용량: 관리자만 바꾼다
+	}
+
+	// 회원 가입 요청: 권한과 상태는 받지 않는다
+	SignUp struct {
+		Email    string `json:"email"`
+		Nickname string `json:"nickname"`
+		Password string `json:"password"`
+		Locale   string `json:"locale"`
 	}
 )
+
+func (s *SignUp) User() *User {
+	// 가입 요청으로 만든 회원: 권한이 없고 활성화되지 않은 상태
+	return &User{
+		ID:       bson.NewObjectId(),
+		Email:    s.Email,
+		Nickname: s.Nickname,
+		Password: s.Password,
+		Locale:   s.Locale,
+	}
+}
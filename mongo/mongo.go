@@ -0,0 +1,255 @@
+package mongo
+
+import (
+	// Default package
+	"time"
+	"context"
+	// Third Party package
+	"github.com/globalsign/mgo"
+	"go.opentelemetry.io/otel/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	// User package
+	"github.com/backend/metrics"
+	"github.com/backend/tracing"
+)
+
+// 작업 시간을 컬렉션별로 기록하는 MongoDB 세션과 컬렉션
+// 핸들러와 저장소는 mgo 세션을 직접 복제하는 대신 Clone 으로 받은 세션의 C(name) 을 쓴다
+type (
+	Session struct {
+		*mgo.Session
+		ctx      context.Context // 요청의 context: 작업마다 이 요청의 스팬 아래에 스팬을 만든다
+		database string
+	}
+
+	Collection struct {
+		*mgo.Collection
+		ctx      context.Context
+		database string
+	}
+
+	Query struct {
+		*mgo.Query
+		c         *Collection
+		operation string
+	}
+
+	Iter struct {
+		*mgo.Iter
+		done func(err error)
+	}
+
+	Pipe struct {
+		*mgo.Pipe
+		c *Collection
+	}
+
+	Bulk struct {
+		*mgo.Bulk
+		c *Collection
+	}
+)
+
+func Clone(s *mgo.Session, ctx context.Context, database string) *Session {
+	// 요청이나 작업마다 세션을 복제한다: 다 쓰면 Close 를 호출해야 한다
+	return &Session{s.Clone(), ctx, database}
+}
+
+func (db *Session) WithContext(ctx context.Context) *Session {
+	// 같은 세션으로 다른 요청이나 작업의 스팬 아래에 기록한다
+	return &Session{db.Session, ctx, db.database}
+}
+
+func (db *Session) C(name string) *Collection {
+	return &Collection{db.DB(db.database).C(name), db.ctx, db.database}
+}
+
+func (c *Collection) start(operation string) (done func(err error)) {
+	// 작업 시간과 실패를 기록한다: 요청이나 백그라운드 작업의 스팬이 있을 때만 스팬을 만든다
+	// 대기열 길이 확인처럼 주기적으로 실행하는 조회가 따로 추적을 만들지 않도록 한다
+	start := time.Now()
+	var span trace.Span
+	if trace.SpanContextFromContext(c.ctx).IsValid() {
+		_, span = tracing.Start(c.ctx, operation+" "+c.Name,
+			semconv.DBSystemNameMongoDB,
+			semconv.DBNamespace(c.database),
+			semconv.DBCollectionName(c.Name),
+			semconv.DBOperationName(operation))
+	}
+	return func(err error) {
+		// 찾는 문서가 없는 것은 실패로 세지 않는다
+		if err == mgo.ErrNotFound {
+			err = nil
+		}
+		metrics.ObserveMongo(c.Name, operation, start, err)
+		if span != nil {
+			tracing.End(span, err)
+		}
+	}
+}
+
+func (c *Collection) Find(query interface{}) *Query {
+	return &Query{c.Collection.Find(query), c, "find"}
+}
+
+func (c *Collection) FindId(id interface{}) *Query {
+	return &Query{c.Collection.FindId(id), c, "find"}
+}
+
+func (c *Collection) Pipe(pipeline interface{}) *Pipe {
+	return &Pipe{c.Collection.Pipe(pipeline), c}
+}
+
+func (c *Collection) Bulk() *Bulk {
+	return &Bulk{c.Collection.Bulk(), c}
+}
+
+func (c *Collection) Insert(docs ...interface{}) (err error) {
+	done := c.start("insert")
+	defer func() { done(err) }()
+	return c.Collection.Insert(docs...)
+}
+
+func (c *Collection) Update(selector interface{}, update interface{}) (err error) {
+	done := c.start("update")
+	defer func() { done(err) }()
+	return c.Collection.Update(selector, update)
+}
+
+func (c *Collection) UpdateId(id interface{}, update interface{}) (err error) {
+	done := c.start("update")
+	defer func() { done(err) }()
+	return c.Collection.UpdateId(id, update)
+}
+
+func (c *Collection) UpdateAll(selector interface{}, update interface{}) (info *mgo.ChangeInfo, err error) {
+	done := c.start("update_all")
+	defer func() { done(err) }()
+	return c.Collection.UpdateAll(selector, update)
+}
+
+func (c *Collection) Upsert(selector interface{}, update interface{}) (info *mgo.ChangeInfo, err error) {
+	done := c.start("upsert")
+	defer func() { done(err) }()
+	return c.Collection.Upsert(selector, update)
+}
+
+func (c *Collection) UpsertId(id interface{}, update interface{}) (info *mgo.ChangeInfo, err error) {
+	done := c.start("upsert")
+	defer func() { done(err) }()
+	return c.Collection.UpsertId(id, update)
+}
+
+func (c *Collection) Remove(selector interface{}) (err error) {
+	done := c.start("remove")
+	defer func() { done(err) }()
+	return c.Collection.Remove(selector)
+}
+
+func (c *Collection) RemoveId(id interface{}) (err error) {
+	done := c.start("remove")
+	defer func() { done(err) }()
+	return c.Collection.RemoveId(id)
+}
+
+func (c *Collection) RemoveAll(selector interface{}) (info *mgo.ChangeInfo, err error) {
+	done := c.start("remove_all")
+	defer func() { done(err) }()
+	return c.Collection.RemoveAll(selector)
+}
+
+func (c *Collection) Count() (n int, err error) {
+	done := c.start("count")
+	defer func() { done(err) }()
+	return c.Collection.Count()
+}
+
+func (c *Collection) EnsureIndex(index mgo.Index) (err error) {
+	done := c.start("create_index")
+	defer func() { done(err) }()
+	return c.Collection.EnsureIndex(index)
+}
+
+// 조건을 붙이는 메소드는 같은 Query 를 돌려주고, 결과를 읽는 메소드에서 시간을 잰다
+func (q *Query) Select(selector interface{}) *Query {
+	q.Query.Select(selector)
+	return q
+}
+
+func (q *Query) Sort(fields ...string) *Query {
+	q.Query.Sort(fields...)
+	return q
+}
+
+func (q *Query) Skip(n int) *Query {
+	q.Query.Skip(n)
+	return q
+}
+
+func (q *Query) Limit(n int) *Query {
+	q.Query.Limit(n)
+	return q
+}
+
+func (q *Query) One(result interface{}) (err error) {
+	done := q.c.start(q.operation)
+	defer func() { done(err) }()
+	return q.Query.One(result)
+}
+
+func (q *Query) All(result interface{}) (err error) {
+	done := q.c.start(q.operation)
+	defer func() { done(err) }()
+	return q.Query.All(result)
+}
+
+func (q *Query) Count() (n int, err error) {
+	done := q.c.start("count")
+	defer func() { done(err) }()
+	return q.Query.Count()
+}
+
+func (q *Query) Distinct(key string, result interface{}) (err error) {
+	done := q.c.start("distinct")
+	defer func() { done(err) }()
+	return q.Query.Distinct(key, result)
+}
+
+func (q *Query) Apply(change mgo.Change, result interface{}) (info *mgo.ChangeInfo, err error) {
+	done := q.c.start("find_and_modify")
+	defer func() { done(err) }()
+	return q.Query.Apply(change, result)
+}
+
+func (q *Query) Iter() *Iter {
+	return &Iter{q.Query.Iter(), q.c.start(q.operation)}
+}
+
+func (it *Iter) Close() (err error) {
+	// 커서를 연 뒤 닫을 때까지의 시간
+	err = it.Iter.Close()
+	it.done(err)
+	return
+}
+
+func (p *Pipe) All(result interface{}) (err error) {
+	done := p.c.start("aggregate")
+	defer func() { done(err) }()
+	return p.Pipe.All(result)
+}
+
+func (p *Pipe) One(result interface{}) (err error) {
+	done := p.c.start("aggregate")
+	defer func() { done(err) }()
+	return p.Pipe.One(result)
+}
+
+func (p *Pipe) Iter() *Iter {
+	return &Iter{p.Pipe.Iter(), p.c.start("aggregate")}
+}
+
+func (b *Bulk) Run() (result *mgo.BulkResult, err error) {
+	done := b.c.start("bulk")
+	defer func() { done(err) }()
+	return b.Bulk.Run()
+}
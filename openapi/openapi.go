@@ -0,0 +1,629 @@
+package openapi
+
+import (
+	// Default package
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	"errors"
+	"reflect"
+	"strings"
+	"net/http"
+	"encoding/json"
+	// Third Party package
+	"github.com/labstack/echo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/auth"
+)
+
+// OpenAPI 3.0 문서: 프론트엔드가 쓰는 항목만 정의한다
+type (
+	Document struct {
+		OpenAPI    string                          `json:"openapi"`
+		Info       Info                            `json:"info"`
+		Tags       []Tag                           `json:"tags,omitempty"`
+		Paths      map[string]map[string]*Operation `json:"paths"`
+		Components Components                      `json:"components"`
+	}
+
+	Info struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description,omitempty"`
+	}
+
+	Tag struct {
+		Name        string `json:"name"`
+		Description string `json:"description,omitempty"`
+	}
+
+	Components struct {
+		Schemas         map[string]*Schema         `json:"schemas"`
+		Responses       map[string]*Response       `json:"responses"`
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes"`
+	}
+
+	Operation struct {
+		Tags        []string              `json:"tags,omitempty"`
+		Summary     string                `json:"summary,omitempty"`
+		Description string                `json:"description,omitempty"`
+		OperationID string                `json:"operationId,omitempty"`
+		Parameters  []*Parameter          `json:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty"`
+		Responses   map[string]*Response  `json:"responses"`
+		Security    []map[string][]string `json:"security"`
+	}
+
+	Parameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"` // path, query, header
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Schema      *Schema `json:"schema"`
+	}
+
+	RequestBody struct {
+		Required bool                  `json:"required,omitempty"`
+		Content  map[string]*MediaType `json:"content"`
+	}
+
+	Response struct {
+		Ref         string                `json:"$ref,omitempty"`
+		Description string                `json:"description,omitempty"`
+		Headers     map[string]*Header    `json:"headers,omitempty"`
+		Content     map[string]*MediaType `json:"content,omitempty"`
+	}
+
+	Header struct {
+		Description string  `json:"description,omitempty"`
+		Schema      *Schema `json:"schema"`
+	}
+
+	MediaType struct {
+		Schema *Schema `json:"schema"`
+	}
+
+	Schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Pattern              string             `json:"pattern,omitempty"`
+		Description          string             `json:"description,omitempty"`
+		Enum                 []string           `json:"enum,omitempty"`
+		Default              interface{}        `json:"default,omitempty"`
+		Nullable             bool               `json:"nullable,omitempty"`
+		Items                *Schema            `json:"items,omitempty"`
+		Properties           map[string]*Schema `json:"properties,omitempty"`
+		AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+	}
+
+	SecurityScheme struct {
+		Type         string `json:"type"`
+		Scheme       string `json:"scheme,omitempty"`
+		BearerFormat string `json:"bearerFormat,omitempty"`
+		In           string `json:"in,omitempty"`
+		Name         string `json:"name,omitempty"`
+		Description  string `json:"description,omitempty"`
+	}
+)
+
+// 보안 방식 이름
+const (
+	bearerAuth  = "bearerAuth"
+	queryToken  = "queryToken"
+	metricsAuth = "metricsToken"
+)
+
+// 라우트 하나의 문서: server.go 의 라우트와 같은 경로(/story/view/:story_id)로 적는다
+type Route struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	Options []Option
+}
+
+// 요청 파라미터, 본문, 응답 등을 덧붙이는 함수
+type Option func(r *Route, op *Operation, doc *Document)
+
+func Op(method string, path string, tag string, summary string, options ...Option) Route {
+	return Route{Method: method, Path: path, Tag: tag, Summary: summary, Options: options}
+}
+
+func New(info Info, tags []Tag) *Document {
+	return &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Tags:    tags,
+		Paths:   map[string]map[string]*Operation{},
+		Components: Components{
+			Schemas: map[string]*Schema{
+				"Error": Object(
+					Req("message", String("에러 메시지: 대부분 한국어 문장이다")),
+				),
+			},
+			Responses: map[string]*Response{
+				"BadRequest":   errorResponse("요청 값이 올바르지 않습니다"),
+				"Unauthorized": errorResponse("토큰이 없거나 올바르지 않거나, 권한이 없습니다"),
+				"Forbidden":    errorResponse("서명이 올바르지 않거나 만료되었습니다"),
+				"NotFound":     errorResponse("대상을 찾을 수 없습니다"),
+				"TooLarge":     errorResponse("파일이 너무 크거나 용량을 넘었습니다"),
+				"ServerError":  errorResponse("서버 오류"),
+			},
+			SecuritySchemes: map[string]*SecurityScheme{
+				bearerAuth: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+					Description:  "로그인(POST /sign-in/)으로 받은 토큰",
+				},
+				queryToken: {
+					Type:        "apiKey",
+					In:          "query",
+					Name:        "token",
+					Description: "EventSource 는 헤더를 보낼 수 없으므로 로그인 토큰을 token 쿼리로 보낸다",
+				},
+				metricsAuth: {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "설정의 metrics.token",
+				},
+			},
+		},
+	}
+}
+
+func (doc *Document) Add(routes ...Route) {
+	for i := range routes {
+		r := &routes[i]
+		op := &Operation{
+			Tags:        []string{r.Tag},
+			Summary:     r.Summary,
+			OperationID: operationID(r.Method, r.Path),
+			Responses:   map[string]*Response{},
+			Security:    []map[string][]string{},
+		}
+
+		// 경로 파라미터
+		for _, segment := range strings.Split(r.Path, "/") {
+			switch {
+			case strings.HasPrefix(segment, ":"):
+				name := segment[1:]
+				schema := String("")
+				if strings.HasSuffix(name, "_id") {
+					schema = ObjectID()
+				}
+				op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "path", Required: true, Schema: schema})
+			case segment == "*":
+				op.Parameters = append(op.Parameters, &Parameter{Name: "path", In: "path", Required: true, Schema: String("파일 경로")})
+			}
+		}
+
+		if strings.Contains(r.Path, "/:") {
+			op.Responses["404"] = ref("NotFound")
+		}
+		op.Responses["500"] = ref("ServerError")
+
+		for _, option := range r.Options {
+			option(r, op, doc)
+		}
+
+		path := Path(r.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*Operation{}
+		}
+		doc.Paths[path][strings.ToLower(r.Method)] = op
+	}
+}
+
+func (doc *Document) Secure(routes []auth.Route) {
+	// 라우트 표의 인증 방식을 문서에 적는다
+	for _, r := range routes {
+		op := doc.Paths[Path(r.Path)][strings.ToLower(r.Method)]
+		if op == nil || r.Mode == auth.Public {
+			continue
+		}
+
+		scheme := bearerAuth
+		if strings.HasPrefix(r.TokenLookup, "query:") {
+			scheme = queryToken
+		}
+		op.Security = []map[string][]string{{scheme: {}}}
+		op.Responses["401"] = ref("Unauthorized")
+
+		note := ""
+		switch r.Mode {
+		case auth.Optional:
+			// 토큰 없이도 호출할 수 있다
+			op.Security = append(op.Security, map[string][]string{})
+			note = "토큰을 보내면 로그인한 회원에 맞춘 값을 함께 돌려준다."
+		case auth.Staff:
+			note = "스태프나 관리자만 사용할 수 있습니다."
+		case auth.Admin:
+			note = "관리자만 사용할 수 있습니다."
+		}
+		if r.Mode != auth.Optional {
+			// 토큰이 없으면 JWT 미들웨어가 400 을 돌려준다
+			op.Responses["400"] = ref("BadRequest")
+		}
+		if note != "" {
+			op.Description = strings.TrimSpace(note + " " + op.Description)
+		}
+	}
+}
+
+func Path(echoPath string) string {
+	// /story/view/:story_id → /story/view/{story_id}, /assets/* → /assets/{path}
+	segments := strings.Split(echoPath, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			segments[i] = "{" + segment[1:] + "}"
+		case segment == "*":
+			segments[i] = "{path}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func operationID(method string, path string) string {
+	// GET /story/view/:story_id → get_story_view_story_id
+	id := strings.ToLower(method)
+	for _, segment := range strings.Split(path, "/") {
+		segment = strings.Trim(segment, ":*")
+		if segment != "" {
+			id += "_" + strings.Replace(segment, "-", "_", -1)
+		}
+	}
+	return id
+}
+
+func Verify(doc *Document, routes []*echo.Route) error {
+	// 등록된 라우트가 모두 문서에 있는지 확인한다
+	registered := map[string]bool{}
+	for _, r := range routes {
+		registered[r.Method+" "+r.Path] = true
+	}
+
+	var missing []string
+	for _, r := range routes {
+		// 미들웨어가 있는 그룹에 Echo 가 등록하는 404 라우트
+		if strings.HasPrefix(r.Name, "github.com/labstack/echo.(*Group).Use") {
+			continue
+		}
+		// Static 이 같은 핸들러로 함께 등록하는 디렉터리 경로 (/assets/ 와 /assets/*)
+		if strings.HasSuffix(r.Path, "/") && registered[r.Method+" "+r.Path+"*"] {
+			continue
+		}
+		if doc.Paths[Path(r.Path)][strings.ToLower(r.Method)] == nil {
+			missing = append(missing, r.Method+" "+r.Path)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return errors.New("API 문서에 없는 라우트가 있습니다:\n  " + strings.Join(missing, "\n  "))
+	}
+	return nil
+}
+
+func JSONHandler(doc *Document) echo.HandlerFunc {
+	// 라우트를 모두 등록한 뒤 인증 방식까지 적은 문서를 첫 요청에서 한 번만 만든다
+	var (
+		once sync.Once
+		b    []byte
+		err  error
+	)
+	return func(c echo.Context) error {
+		once.Do(func() {
+			b, err = json.Marshal(doc)
+		})
+		if err != nil {
+			return err
+		}
+		return c.JSONBlob(http.StatusOK, b)
+	}
+}
+
+func DocsHandler(specURL string) echo.HandlerFunc {
+	// Swagger UI: 정적 파일은 CDN 에서 받는다
+	page := fmt.Sprintf(docsPage, specURL)
+	return func(c echo.Context) error {
+		return c.HTML(http.StatusOK, page)
+	}
+}
+
+const docsPage = `<!DOCTYPE html>
+<html lang="ko">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>섬띵모어 API</title>
+<link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui"></div>
+<script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+<script>
+window.ui = SwaggerUIBundle({url: %q, dom_id: "#swagger-ui", persistAuthorization: true});
+</script>
+</body>
+</html>
+`
+
+//-----------
+// Options
+//-----------
+
+func Describe(description string) Option {
+	return func(r *Route, op *Operation, doc *Document) {
+		if op.Description != "" {
+			description = op.Description + " " + description
+		}
+		op.Description = description
+	}
+}
+
+func Query(name string, schema *Schema) Option {
+	return func(r *Route, op *Operation, doc *Document) {
+		op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "query", Schema: schema})
+	}
+}
+
+func Paging(defaultLimit int) Option {
+	// page, limit 쿼리: 0 이거나 없으면 기본값
+	return func(r *Route, op *Operation, doc *Document) {
+		page := Integer("페이지 번호")
+		page.Default = 1
+		limit := Integer("페이지 크기")
+		limit.Default = defaultLimit
+		op.Parameters = append(op.Parameters,
+			&Parameter{Name: "page", In: "query", Schema: page},
+			&Parameter{Name: "limit", In: "query", Schema: limit})
+	}
+}
+
+func MetricsToken() Option {
+	// metrics.token 으로 보호하는 지표: 토큰이 비어 있으면 인증하지 않는다
+	return func(r *Route, op *Operation, doc *Document) {
+		op.Security = []map[string][]string{{metricsAuth: {}}, {}}
+		op.Responses["401"] = ref("Unauthorized")
+	}
+}
+
+func HeaderParam(name string, schema *Schema) Option {
+	return func(r *Route, op *Operation, doc *Document) {
+		op.Parameters = append(op.Parameters, &Parameter{Name: name, In: "header", Schema: schema})
+	}
+}
+
+func JSONBody(schema *Schema) Option {
+	// c.Bind 로 읽는 본문: JSON 과 form 을 모두 받는다
+	return func(r *Route, op *Operation, doc *Document) {
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			echo.MIMEApplicationJSON: {Schema: schema},
+			echo.MIMEApplicationForm: {Schema: schema},
+		}}
+		op.Responses["400"] = ref("BadRequest")
+	}
+}
+
+func FormBody(schema *Schema) Option {
+	// c.FormValue 로 읽는 본문
+	return func(r *Route, op *Operation, doc *Document) {
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			echo.MIMEApplicationForm: {Schema: schema},
+			echo.MIMEMultipartForm:   {Schema: schema},
+		}}
+		op.Responses["400"] = ref("BadRequest")
+	}
+}
+
+func MultipartBody(schema *Schema) Option {
+	// 파일을 첨부하는 본문
+	return func(r *Route, op *Operation, doc *Document) {
+		op.RequestBody = &RequestBody{Required: true, Content: map[string]*MediaType{
+			echo.MIMEMultipartForm: {Schema: schema},
+		}}
+		op.Responses["400"] = ref("BadRequest")
+		op.Responses["413"] = ref("TooLarge")
+	}
+}
+
+func Returns(code int, description string, schema *Schema) Option {
+	// JSON 응답
+	return func(r *Route, op *Operation, doc *Document) {
+		op.Responses[fmt.Sprint(code)] = &Response{
+			Description: description,
+			Content:     map[string]*MediaType{echo.MIMEApplicationJSON: {Schema: schema}},
+		}
+	}
+}
+
+func ReturnsContent(code int, description string, contentType string, schema *Schema) Option {
+	// 텍스트, 파일 등 JSON 이 아닌 응답
+	return func(r *Route, op *Operation, doc *Document) {
+		op.Responses[fmt.Sprint(code)] = &Response{
+			Description: description,
+			Content:     map[string]*MediaType{contentType: {Schema: schema}},
+		}
+	}
+}
+
+func ReturnsNothing(code int, description string) Option {
+	return func(r *Route, op *Operation, doc *Document) {
+		op.Responses[fmt.Sprint(code)] = &Response{Description: description}
+	}
+}
+
+func Redirects(code int, description string) Option {
+	return func(r *Route, op *Operation, doc *Document) {
+		op.Responses[fmt.Sprint(code)] = &Response{
+			Description: description,
+			Headers:     map[string]*Header{"Location": {Schema: String("이동할 주소")}},
+		}
+	}
+}
+
+func Errors(codes ...int) Option {
+	// 핸들러가 직접 돌려주는 에러 응답
+	names := map[int]string{
+		http.StatusBadRequest:            "BadRequest",
+		http.StatusUnauthorized:          "Unauthorized",
+		http.StatusForbidden:             "Forbidden",
+		http.StatusNotFound:              "NotFound",
+		http.StatusRequestEntityTooLarge: "TooLarge",
+	}
+	return func(r *Route, op *Operation, doc *Document) {
+		for _, code := range codes {
+			if name, ok := names[code]; ok {
+				op.Responses[fmt.Sprint(code)] = ref(name)
+			} else {
+				op.Responses[fmt.Sprint(code)] = errorResponse(http.StatusText(code))
+			}
+		}
+	}
+}
+
+func ref(name string) *Response {
+	return &Response{Ref: "#/components/responses/" + name}
+}
+
+func errorResponse(description string) *Response {
+	return &Response{
+		Description: description,
+		Content: map[string]*MediaType{
+			echo.MIMEApplicationJSON: {Schema: &Schema{Ref: "#/components/schemas/Error"}},
+		},
+	}
+}
+
+//-----------
+// Schemas
+//-----------
+
+// 객체 스키마의 속성 하나
+type Property struct {
+	Name     string
+	Schema   *Schema
+	Required bool
+}
+
+func Req(name string, schema *Schema) Property {
+	return Property{Name: name, Schema: schema, Required: true}
+}
+
+func Opt(name string, schema *Schema) Property {
+	return Property{Name: name, Schema: schema}
+}
+
+func Object(properties ...Property) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for _, p := range properties {
+		s.Properties[p.Name] = p.Schema
+		if p.Required {
+			s.Required = append(s.Required, p.Name)
+		}
+	}
+	return s
+}
+
+func String(description string) *Schema {
+	return &Schema{Type: "string", Description: description}
+}
+
+func Enum(description string, values ...string) *Schema {
+	return &Schema{Type: "string", Description: description, Enum: values}
+}
+
+func Integer(description string) *Schema {
+	return &Schema{Type: "integer", Description: description}
+}
+
+func Boolean(description string) *Schema {
+	return &Schema{Type: "boolean", Description: description}
+}
+
+func Binary(description string) *Schema {
+	return &Schema{Type: "string", Format: "binary", Description: description}
+}
+
+func DateTime(description string) *Schema {
+	return &Schema{Type: "string", Format: "date-time", Description: description}
+}
+
+func ObjectID() *Schema {
+	return &Schema{Type: "string", Pattern: "^[0-9a-f]{24}$", Description: "MongoDB ObjectId"}
+}
+
+func ArrayOf(items *Schema) *Schema {
+	return &Schema{Type: "array", Items: items}
+}
+
+func MapOf(values *Schema) *Schema {
+	return &Schema{Type: "object", AdditionalProperties: values}
+}
+
+func Model(doc *Document, v interface{}) *Schema {
+	// 모델 구조체의 json 태그로 스키마를 만들어 components 에 등록하고 참조를 돌려준다
+	return modelSchema(doc, reflect.TypeOf(v))
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIDType = reflect.TypeOf(bson.ObjectId(""))
+)
+
+func modelSchema(doc *Document, t reflect.Type) *Schema {
+	switch t {
+	case timeType:
+		return DateTime("")
+	case objectIDType:
+		return ObjectID()
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		s := modelSchema(doc, t.Elem())
+		if s.Ref != "" {
+			return s
+		}
+		s.Nullable = true
+		return s
+	case reflect.Slice, reflect.Array:
+		return ArrayOf(modelSchema(doc, t.Elem()))
+	case reflect.Map:
+		return MapOf(modelSchema(doc, t.Elem()))
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			// 자기 자신을 참조하는 구조체에 대비해 먼저 자리를 잡는다
+			s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+			doc.Components.Schemas[name] = s
+			for i := 0; i < t.NumField(); i++ {
+				field := t.Field(i)
+				tag := strings.Split(field.Tag.Get("json"), ",")[0]
+				if tag == "-" || field.PkgPath != "" {
+					continue
+				}
+				if tag == "" {
+					tag = field.Name
+				}
+				s.Properties[tag] = modelSchema(doc, field.Type)
+			}
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
@@ -0,0 +1,30 @@
+package openapi_test
+
+import (
+	// Default package
+	"testing"
+	// Third Party package
+	"github.com/labstack/echo"
+	// User package
+	"github.com/backend/auth"
+	"github.com/backend/handler"
+	"github.com/backend/openapi"
+	"github.com/backend/storage"
+)
+
+func TestSpecCoversRoutes(t *testing.T) {
+	// 서버와 같은 라우트 표를 만든다: 로컬 저장소와 API 서버의 /metrics 처럼 설정에 따라 생기는 라우트도 포함한다
+	e := echo.New()
+	h := &handler.Handler{Storage: storage.NewLocal(storage.Config{Dir: t.TempDir()})}
+	r := auth.New(e, []byte("test"), nil)
+	spec := openapi.Spec()
+	h.Routes(r, handler.RouteOptions{Spec: spec, Metrics: true})
+
+	if err := r.Verify(e.Routes()); err != nil {
+		t.Fatal(err)
+	}
+	spec.Secure(r.Routes())
+	if err := openapi.Verify(spec, e.Routes()); err != nil {
+		t.Fatal(err)
+	}
+}
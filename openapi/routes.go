@@ -0,0 +1,416 @@
+package openapi
+
+import (
+	// Default package
+	"net/http"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/importer"
+	"github.com/backend/utility"
+)
+
+// API 문서 정보
+var info = Info{
+	Title:   "섬띵모어 API",
+	Version: "1.0.0",
+	Description: "섬띵모어 웹진 백엔드 API. " +
+		"인증이 필요한 라우트는 POST /sign-in/ 으로 받은 토큰을 Authorization: Bearer 헤더로 보낸다. " +
+		"에러는 {\"message\": \"...\"} 형식의 JSON 으로 돌려준다.",
+}
+
+// server.go 의 라우트 묶음(// Route: X)과 같은 순서
+var tags = []Tag{
+	{Name: "Static", Description: "정적 파일"},
+	{Name: "Health", Description: "상태 확인"},
+	{Name: "Metrics", Description: "Prometheus 지표"},
+	{Name: "Docs", Description: "API 문서"},
+	{Name: "User", Description: "회원"},
+	{Name: "Admin", Description: "회원 관리"},
+	{Name: "Outbox", Description: "메일 발송 대기열"},
+	{Name: "Media", Description: "미디어 라이브러리"},
+	{Name: "Asset", Description: "저장소 파일 정리"},
+	{Name: "Author", Description: "필진"},
+	{Name: "Notification", Description: "알림"},
+	{Name: "Story", Description: "스토리"},
+	{Name: "Board", Description: "자유게시판"},
+	{Name: "Notice", Description: "공지사항"},
+	{Name: "Export", Description: "EPUB 내보내기"},
+	{Name: "Newsletter", Description: "소식지"},
+	{Name: "Import", Description: "글 가져오기"},
+	{Name: "Anthology", Description: "문집"},
+	{Name: "Events", Description: "실시간 이벤트 (Server-Sent Events)"},
+}
+
+func Spec() *Document {
+	// 서버의 모든 라우트를 문서로 만든다: 라우트를 추가하면 여기에도 추가해야 서버가 시작된다
+	doc := New(info, tags)
+
+	var (
+		user         = Model(doc, model.User{})
+		post         = Model(doc, model.Post{})
+		noticeList   = Model(doc, model.NoticeList{})
+		media        = Model(doc, model.Media{})
+		mediaUsage   = Model(doc, model.MediaUsage{})
+		anthology    = Model(doc, model.Anthology{})
+		assetReport  = Model(doc, model.AssetReport{})
+		broadcast    = Model(doc, model.Broadcast{})
+		issue        = Model(doc, model.NewsletterIssue{})
+		notification = Model(doc, model.Notification{})
+		outbox       = Model(doc, model.OutboxMessage{})
+		report       = Model(doc, importer.Report{})
+	)
+
+	var (
+		text     = String("")
+		count    = String("갯수 (예: 42)")
+		token    = String("JWT: 이후 요청의 Authorization: Bearer 헤더에 사용")
+		epub     = Binary("EPUB 파일")
+		locale   = Enum("메일 언어", utility.MailLocales...)
+		storyIDs = ArrayOf(ObjectID())
+		status   = Object(Req("status", Enum("", "ok", "unavailable")), Opt("checks", MapOf(String("확인 결과: ok 또는 에러 메시지"))))
+	)
+
+	// 알림 종류별 수신 여부
+	preference := Object()
+	for _, notificationType := range model.NotificationTypes {
+		preference.Properties[notificationType] = Boolean("")
+	}
+
+	// 글 본문은 FormValue 로 읽으므로 form 으로 보낸다: 스토리는 썸네일을 첨부하므로 multipart
+	storyForm := Object(
+		Req("title", String("제목")),
+		Req("content", String("본문 HTML")),
+		Opt("date_created", String("작성일자")),
+		Opt("category", String("카테고리")),
+		Opt("series", String("연재 이름")),
+		Opt("thumbnail", Binary("썸네일 이미지 (JPEG, PNG, GIF, WebP, 최대 10MB)")),
+	)
+	storyPatchForm := Object(
+		Req("title", String("제목")),
+		Req("content", String("본문 HTML")),
+		Opt("date_modified", String("수정일자")),
+		Opt("is_published", Boolean("발행 여부")),
+		Opt("category", String("카테고리")),
+		Opt("series", String("연재 이름")),
+		Opt("thumbnail", Binary("새 썸네일 이미지: 보내지 않으면 기존 썸네일 유지")),
+	)
+	boardForm := func(date string) *Schema {
+		return Object(Req("title", String("제목")), Req("content", String("본문 HTML")), Opt(date, String("")))
+	}
+	noticeOptions := []Property{
+		Opt("is_pinned", Boolean("상단 고정 여부")),
+		Opt("pin_order", Integer("고정 순서: 작을수록 위에 표시")),
+		Opt("expires_at", DateTime("게시 종료 일시 (RFC 3339): 비어 있으면 기한 없음")),
+	}
+	noticeForm := Object(append([]Property{
+		Req("title", String("제목")),
+		Req("content", String("본문 HTML")),
+		Opt("date_created", String("작성일자")),
+		Opt("send_email", Boolean("회원에게 메일로 보낼지 여부")),
+		Opt("email_role", Enum("메일 수신 대상 (기본값: all)", "all", "staff", "admin")),
+	}, noticeOptions...)...)
+	noticePatchForm := Object(
+		Req("title", String("제목")),
+		Req("content", String("본문 HTML")),
+		Opt("date_modified", String("수정일자")),
+		Opt("is_pinned", Boolean("상단 고정 여부: 보내지 않으면 그대로")),
+		Opt("pin_order", Integer("고정 순서: 보내지 않으면 그대로")),
+		Opt("expires_at", DateTime("게시 종료 일시 (RFC 3339): 보내지 않으면 그대로, 비어 있으면 기한 없음")),
+	)
+	anthologyBody := Object(
+		Req("title", String("제목")),
+		Req("year", Integer("발행 연도")),
+		Opt("preface", String("서문 HTML")),
+		Opt("colophon", String("판권 HTML")),
+		Req("sections", ArrayOf(Object(Req("title", String("장 제목")), Req("story_ids", storyIDs)))),
+	)
+
+	doc.Add(
+		// Static
+		Op("GET", "/assets/*", "Static", "정적 파일",
+			Describe("로컬 저장소를 쓸 때만 등록된다."),
+			ReturnsContent(http.StatusOK, "파일", "application/octet-stream", Binary("")),
+			Errors(http.StatusNotFound)),
+		Op("GET", "/private/*", "Static", "서명된 주소로 비공개 파일 내려받기",
+			Describe("로컬 저장소를 쓸 때만 등록된다."),
+			Query("expires", Integer("만료 시각 (Unix 초)")),
+			Query("signature", String("서명")),
+			ReturnsContent(http.StatusOK, "파일", "application/octet-stream", Binary("")),
+			Errors(http.StatusForbidden, http.StatusNotFound)),
+
+		// Index, Health
+		Op("GET", "/", "Health", "서버 이름",
+			ReturnsContent(http.StatusOK, "서버 이름", "text/plain", text)),
+		Op("GET", "/healthz", "Health", "프로세스 동작 확인",
+			Returns(http.StatusOK, "동작 중", status)),
+		Op("GET", "/readyz", "Health", "데이터베이스, 저장소, 메일 서버 연결 확인",
+			Returns(http.StatusOK, "요청을 받을 수 있음", status),
+			Returns(http.StatusServiceUnavailable, "연결하지 못한 곳이 있음", status)),
+
+		// Metrics
+		Op("GET", "/metrics", "Metrics", "Prometheus 지표",
+			MetricsToken(),
+			Describe("metrics.addr 를 정하지 않았을 때만 API 서버에 등록된다. metrics.token 이 비어 있으면 인증하지 않는다."),
+			ReturnsContent(http.StatusOK, "Prometheus 텍스트 형식", "text/plain", text)),
+
+		// Docs
+		Op("GET", "/openapi.json", "Docs", "OpenAPI 문서",
+			Returns(http.StatusOK, "이 문서", &Schema{Type: "object"})),
+		Op("GET", "/docs/", "Docs", "API 문서 화면",
+			ReturnsContent(http.StatusOK, "Swagger UI", "text/html", text)),
+
+		// User
+		Op("POST", "/sign-up/", "User", "회원 가입",
+			Describe("활성화 메일을 보낸다. 메일의 링크로 활성화해야 로그인할 수 있다. 권한과 활성화 여부는 받지 않는다."),
+			JSONBody(Object(Req("email", String("")), Req("nickname", String("")), Req("password", String("")), Opt("locale", locale))),
+			Returns(http.StatusCreated, "가입한 회원", user)),
+		Op("POST", "/admin/", "User", "관리자 회원 가입",
+			JSONBody(Object(Req("email", String("")), Req("nickname", String("")), Req("password", String("")), Opt("locale", locale))),
+			Returns(http.StatusCreated, "가입한 회원", user)),
+		Op("GET", "/activate/:user_email", "User", "이메일 회원 활성화",
+			Redirects(http.StatusMovedPermanently, "활성화 후 사이트로 이동")),
+		Op("POST", "/sign-in/", "User", "로그인",
+			JSONBody(Object(Req("email", String("")), Req("password", String("")))),
+			Returns(http.StatusOK, "로그인 토큰", token),
+			Errors(http.StatusUnauthorized)),
+		Op("PATCH", "/patch/", "User", "비밀번호 수정",
+			JSONBody(Object(Req("password", String("새 비밀번호")))),
+			ReturnsNothing(http.StatusOK, "수정됨")),
+		Op("PATCH", "/nickname/", "User", "닉네임 수정",
+			JSONBody(Object(Req("nickname", String("새 닉네임")))),
+			Returns(http.StatusOK, "새 닉네임이 담긴 로그인 토큰", token),
+			Errors(http.StatusNotFound)),
+		Op("DELETE", "/destroy/", "User", "회원 탈퇴",
+			JSONBody(Object(Req("password", String("현재 비밀번호")))),
+			ReturnsNothing(http.StatusNoContent, "탈퇴함")),
+		Op("GET", "/reset/:user_email", "User", "비밀번호 초기화",
+			Describe("임시 비밀번호를 메일로 보낸다."),
+			ReturnsNothing(http.StatusOK, "메일 발송 예약됨")),
+		Op("PATCH", "/subscription/", "User", "공지사항 메일 수신 여부 변경",
+			FormBody(Object(Req("unsubscribed_notice", Boolean("true 이면 수신 거부")))),
+			ReturnsNothing(http.StatusOK, "변경됨")),
+		Op("GET", "/unsubscribe/:token", "User", "공지사항 메일 수신 거부 확인 화면",
+			Describe("공지사항 메일에 들어 있는 서명된 링크. 수신 거부 버튼만 보여주고 아무것도 바꾸지 않는다."),
+			ReturnsContent(http.StatusOK, "확인 화면", "text/html", text)),
+		Op("POST", "/unsubscribe/:token", "User", "공지사항 메일 수신 거부",
+			Describe("확인 화면의 버튼과 메일 프로그램의 원클릭 수신 거부(RFC 8058, List-Unsubscribe-Post)가 보낸다."),
+			ReturnsContent(http.StatusOK, "안내 문구", "text/plain", text)),
+		Op("PATCH", "/locale/", "User", "메일 언어 변경",
+			FormBody(Object(Req("locale", locale))),
+			ReturnsNothing(http.StatusOK, "변경됨")),
+
+		// Admin
+		Op("GET", "/users/", "Admin", "전체 유저 리스트",
+			Returns(http.StatusOK, "회원 목록", ArrayOf(user))),
+		Op("PATCH", "/users/:user_email", "Admin", "유저 권한 변경",
+			JSONBody(Object(Req("is_admin", Boolean("")), Req("is_staff", Boolean("")))),
+			ReturnsNothing(http.StatusOK, "변경됨")),
+		Op("DELETE", "/users/:user_email", "Admin", "유저 강제 탈퇴",
+			ReturnsNothing(http.StatusNoContent, "탈퇴시킴")),
+		Op("PATCH", "/users/ban/:user_email", "Admin", "유저 이용 정지",
+			Describe("정지한 회원은 로그인할 수 없고, 이미 발급받은 토큰으로 보낸 요청도 403 으로 거부된다."),
+			FormBody(Object(Req("is_banned", Boolean("true 이면 정지, false 이면 해제")))),
+			ReturnsNothing(http.StatusOK, "변경됨"),
+			Errors(http.StatusBadRequest)),
+
+		// Outbox
+		Op("GET", "/outbox/", "Outbox", "발송 실패 메일 목록",
+			Query("status", Enum("발송 상태: 비어 있으면 포기한 메일과 재시도를 기다리는 메일", model.OutboxPending, model.OutboxSending, model.OutboxSent, model.OutboxDead)),
+			Paging(20),
+			Returns(http.StatusOK, "메일 목록", ArrayOf(outbox))),
+		Op("PATCH", "/outbox/resend/:message_id", "Outbox", "메일 다시 보내기",
+			ReturnsNothing(http.StatusOK, "발송 대기열에 다시 넣음")),
+
+		// Media
+		Op("POST", "/media/", "Media", "미디어 업로드",
+			MultipartBody(Object(
+				Req("file", Binary("이미지 파일")),
+				Opt("alt", String("대체 텍스트")),
+				Opt("caption", String("설명")))),
+			Returns(http.StatusCreated, "업로드한 미디어", media),
+			Errors(http.StatusNotFound)),
+		Op("GET", "/media/", "Media", "내 미디어 목록",
+			Paging(30),
+			Returns(http.StatusOK, "미디어 목록", ArrayOf(media))),
+		Op("PATCH", "/media/:media_id", "Media", "미디어 대체 텍스트, 설명 수정",
+			FormBody(Object(Opt("alt", String("대체 텍스트")), Opt("caption", String("설명")))),
+			Returns(http.StatusOK, "수정한 미디어", media)),
+		Op("DELETE", "/media/:media_id", "Media", "미디어 삭제",
+			ReturnsNothing(http.StatusOK, "삭제됨")),
+		Op("GET", "/media/usage/", "Media", "회원별 미디어 사용량",
+			Returns(http.StatusOK, "사용량 목록", ArrayOf(mediaUsage))),
+		Op("PATCH", "/media/quota/:user_email", "Media", "회원 미디어 용량 변경",
+			FormBody(Object(Req("quota_mb", Integer("용량 (MB): 0 이면 기본 용량, 최대 102400")))),
+			ReturnsNothing(http.StatusOK, "변경됨")),
+
+		// Asset
+		Op("GET", "/gc/assets/", "Asset", "정리 대상 파일 미리 보기",
+			Returns(http.StatusOK, "정리 대상", assetReport)),
+		Op("POST", "/gc/assets/", "Asset", "정리 대상 파일 지금 삭제",
+			Describe("파일 참조를 모두 기록하지 못했다면(저장소 주소가 아닌 파일 주소가 있는 등) 지우지 않고 409 를 돌려준다."),
+			Returns(http.StatusOK, "삭제 결과", assetReport),
+			Errors(http.StatusConflict)),
+
+		// Author
+		Op("GET", "/authors/", "Author", "필진 리스트",
+			Returns(http.StatusOK, "필진 목록", ArrayOf(user))),
+		Op("GET", "/authors/:author_id", "Author", "필진 스토리 리스트",
+			Paging(15),
+			Returns(http.StatusOK, "발행된 스토리 목록", ArrayOf(post))),
+		Op("GET", "/authors/count/:author_id", "Author", "필진 스토리 갯수",
+			ReturnsContent(http.StatusOK, "발행된 스토리 갯수", "text/plain", count)),
+
+		// Notification
+		Op("GET", "/notifications/", "Notification", "알림 목록",
+			Paging(20),
+			Returns(http.StatusOK, "최근 알림부터", ArrayOf(notification))),
+		Op("GET", "/notifications/unread/", "Notification", "읽지 않은 알림 갯수",
+			ReturnsContent(http.StatusOK, "갯수", "text/plain", count)),
+		Op("PATCH", "/notifications/read/", "Notification", "알림 모두 읽음",
+			ReturnsNothing(http.StatusOK, "변경됨")),
+		Op("PATCH", "/notifications/read/:notification_id", "Notification", "알림 읽음",
+			ReturnsNothing(http.StatusOK, "변경됨")),
+		Op("GET", "/notifications/preferences/", "Notification", "알림 설정 보기",
+			Returns(http.StatusOK, "종류별 수신 여부", preference),
+			Errors(http.StatusNotFound)),
+		Op("PATCH", "/notifications/preferences/", "Notification", "알림 설정 변경",
+			Describe("보낸 종류만 바꾼다: true 는 켜고 false 는 끈다. 보내지 않은 종류는 그대로 둔다."),
+			FormBody(preference),
+			Returns(http.StatusOK, "종류별 수신 여부", preference)),
+
+		// Story
+		Op("POST", "/story/", "Story", "스토리 생성",
+			MultipartBody(storyForm),
+			Returns(http.StatusCreated, "생성한 스토리", post)),
+		Op("GET", "/story/", "Story", "스토리 리스트",
+			Paging(15),
+			Returns(http.StatusOK, "발행되지 않은 스토리를 포함한 목록", ArrayOf(post))),
+		Op("GET", "/story/client/", "Story", "클라이언트 스토리 리스트",
+			Paging(4),
+			Returns(http.StatusOK, "발행된 스토리 목록", ArrayOf(post))),
+		Op("GET", "/story/count/", "Story", "스토리 총 갯수",
+			ReturnsContent(http.StatusOK, "갯수", "text/plain", count)),
+		Op("GET", "/story/view/:story_id", "Story", "스토리 디테일",
+			Returns(http.StatusOK, "스토리", post)),
+		Op("PATCH", "/story/:story_id", "Story", "스토리 수정",
+			MultipartBody(storyPatchForm),
+			Returns(http.StatusOK, "수정한 스토리", post)),
+		Op("PATCH", "/story/publish/:story_id", "Story", "스토리 발행 상태 변경",
+			FormBody(Object(Req("is_published", Boolean("")), Opt("date_modified", String("수정일자")))),
+			Returns(http.StatusOK, "변경한 스토리", post)),
+		Op("DELETE", "/story/:story_id", "Story", "스토리 삭제",
+			ReturnsNothing(http.StatusNoContent, "삭제됨")),
+
+		// Board
+		Op("POST", "/board/", "Board", "자유게시판 글 생성",
+			FormBody(boardForm("date_created")),
+			Returns(http.StatusCreated, "생성한 글", post)),
+		Op("GET", "/board/list/", "Board", "자유게시판 글 목록",
+			Paging(15),
+			Returns(http.StatusOK, "글 목록", ArrayOf(post))),
+		Op("GET", "/board/count/", "Board", "자유게시판 글 갯수",
+			ReturnsContent(http.StatusOK, "갯수", "text/plain", count)),
+		Op("GET", "/board/view/:board_id", "Board", "자유게시판 글 보기",
+			Returns(http.StatusOK, "글", post)),
+		Op("PATCH", "/board/:board_id", "Board", "자유게시판 글 수정",
+			FormBody(boardForm("date_modified")),
+			Returns(http.StatusOK, "수정한 글", post)),
+		Op("DELETE", "/board/:board_id", "Board", "자유게시판 글 삭제",
+			ReturnsNothing(http.StatusNoContent, "삭제됨")),
+
+		// Notice
+		Op("POST", "/notice/", "Notice", "공지사항 글 생성",
+			FormBody(noticeForm),
+			Returns(http.StatusCreated, "생성한 공지사항", post)),
+		Op("GET", "/notice/list/", "Notice", "공지사항 글 목록",
+			Describe("게시 기간이 끝난 공지사항은 빠진다."),
+			Paging(20),
+			Returns(http.StatusOK, "고정 공지와 일반 공지", noticeList)),
+		Op("GET", "/notice/admin/list/", "Notice", "관리자용 공지사항 글 목록",
+			Describe("게시 기간이 끝난 공지사항도 포함한다."),
+			Paging(20),
+			Returns(http.StatusOK, "고정 공지와 일반 공지", noticeList)),
+		Op("GET", "/notice/count/", "Notice", "공지사항 글 갯수",
+			ReturnsContent(http.StatusOK, "갯수", "text/plain", count)),
+		Op("GET", "/notice/view/:notice_id", "Notice", "공지사항 글 보기",
+			Returns(http.StatusOK, "공지사항", post)),
+		Op("PATCH", "/notice/:notice_id", "Notice", "공지사항 글 수정",
+			FormBody(noticePatchForm),
+			Returns(http.StatusOK, "수정한 공지사항", post)),
+		Op("DELETE", "/notice/:notice_id", "Notice", "공지사항 글 삭제",
+			ReturnsNothing(http.StatusNoContent, "삭제됨")),
+		Op("GET", "/notice/broadcast/:notice_id", "Notice", "공지사항 메일 발송 리포트",
+			Returns(http.StatusOK, "발송 기록", ArrayOf(broadcast))),
+
+		// Export
+		Op("GET", "/export/story/:story_id", "Export", "스토리 EPUB 내보내기",
+			ReturnsContent(http.StatusOK, "EPUB", "application/epub+zip", epub)),
+		Op("GET", "/export/series/:author_id/:series", "Export", "연재 EPUB 내보내기",
+			ReturnsContent(http.StatusOK, "EPUB", "application/epub+zip", epub)),
+		Op("GET", "/export/authors/:author_id", "Export", "필진 작품집 EPUB 내보내기",
+			ReturnsContent(http.StatusOK, "EPUB", "application/epub+zip", epub)),
+
+		// Newsletter
+		Op("POST", "/newsletter/subscribe/", "Newsletter", "소식지 구독 신청",
+			Describe("확인 메일의 링크를 눌러야 구독이 시작된다. 이메일 주소는 소문자로 저장하고, 확인 메일은 같은 주소로 15분에 한 번만 보낸다."),
+			FormBody(Object(Req("email", String("")), Opt("locale", locale))),
+			ReturnsNothing(http.StatusAccepted, "확인 메일 발송 예약됨"),
+			ReturnsNothing(http.StatusOK, "이미 구독 중")),
+		Op("GET", "/newsletter/confirm/:token", "Newsletter", "소식지 구독 확인",
+			Redirects(http.StatusFound, "확인 후 사이트로 이동")),
+		Op("GET", "/newsletter/unsubscribe/:token", "Newsletter", "소식지 구독 취소 확인 화면",
+			Describe("소식지에 들어 있는 서명된 링크. 구독 취소 버튼만 보여주고 아무것도 바꾸지 않는다."),
+			ReturnsContent(http.StatusOK, "확인 화면", "text/html", text)),
+		Op("POST", "/newsletter/unsubscribe/:token", "Newsletter", "소식지 구독 취소",
+			Describe("확인 화면의 버튼과 메일 프로그램의 원클릭 구독 취소(RFC 8058, List-Unsubscribe-Post)가 보낸다."),
+			ReturnsContent(http.StatusOK, "안내 문구", "text/plain", text)),
+		Op("POST", "/newsletter/send/", "Newsletter", "소식지 발송",
+			FormBody(Object(
+				Req("subject", String("제목")),
+				Opt("intro", String("머리말")),
+				Opt("limit", Integer("최근 발행된 스토리 수 (기본값: 10)")))),
+			Returns(http.StatusAccepted, "발송을 시작한 소식지", issue)),
+		Op("GET", "/newsletter/", "Newsletter", "소식지 발송 통계",
+			Returns(http.StatusOK, "소식지 목록", ArrayOf(issue))),
+
+		// Import
+		Op("POST", "/import/", "Import", "마크다운, WordPress 글 가져오기",
+			MultipartBody(Object(
+				Req("file", Binary("마크다운 파일, 마크다운 zip 또는 WordPress WXR")),
+				Opt("format", Enum("형식: 비어 있으면 확장자로 추측", importer.FormatMarkdown, importer.FormatWordPress)),
+				Opt("dry_run", Boolean("false 가 아니면 저장하지 않고 결과만 보여준다 (기본값: true)")))),
+			Returns(http.StatusOK, "가져오기 결과", report)),
+
+		// Anthology
+		Op("POST", "/anthology/", "Anthology", "문집 생성",
+			JSONBody(anthologyBody),
+			Returns(http.StatusCreated, "생성한 문집", anthology)),
+		Op("GET", "/anthology/", "Anthology", "문집 목록",
+			Returns(http.StatusOK, "문집 목록", ArrayOf(anthology))),
+		Op("GET", "/anthology/:anthology_id", "Anthology", "문집 디테일",
+			Returns(http.StatusOK, "문집", anthology)),
+		Op("PATCH", "/anthology/:anthology_id", "Anthology", "문집 수정",
+			JSONBody(anthologyBody),
+			Returns(http.StatusOK, "수정한 문집", anthology)),
+		Op("DELETE", "/anthology/:anthology_id", "Anthology", "문집 삭제",
+			ReturnsNothing(http.StatusNoContent, "삭제됨")),
+		Op("GET", "/anthology/pdf/:anthology_id", "Anthology", "문집 인쇄용 PDF 생성",
+			ReturnsContent(http.StatusOK, "PDF", "application/pdf", Binary("PDF 파일")),
+			Errors(http.StatusBadRequest, http.StatusConflict)),
+		Op("GET", "/anthology/epub/:anthology_id", "Anthology", "문집 EPUB 생성",
+			ReturnsContent(http.StatusOK, "EPUB", "application/epub+zip", epub),
+			Errors(http.StatusBadRequest, http.StatusConflict)),
+
+		// Events
+		Op("GET", "/events/", "Events", "실시간 이벤트 스트림",
+			Describe("로그인한 회원의 알림과 공개 이벤트를 보낸다. 연결이 끊기면 마지막으로 받은 이벤트 ID 부터 다시 보낸다."),
+			HeaderParam("Last-Event-ID", String("마지막으로 받은 이벤트 ID")),
+			Query("last_event_id", String("Last-Event-ID 헤더 대신 쓸 수 있다")),
+			ReturnsContent(http.StatusOK, "이벤트 스트림", "text/event-stream", text)),
+		Op("GET", "/events/public/", "Events", "공개 실시간 이벤트 스트림",
+			HeaderParam("Last-Event-ID", String("마지막으로 받은 이벤트 ID")),
+			Query("last_event_id", String("Last-Event-ID 헤더 대신 쓸 수 있다")),
+			ReturnsContent(http.StatusOK, "이벤트 스트림", "text/event-stream", text)),
+	)
+	return doc
+}
@@ -0,0 +1,1284 @@
+package repository
+
+import (
+	// Default package
+	"sort"
+	"sync"
+	"time"
+	"context"
+	// Third Party package
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/model"
+)
+
+// 메모리 저장소: MongoDB 없이 핸들러를 실행할 때 사용한다
+// 저장하고 돌려주는 값은 bson 으로 복사하므로 MongoDB 와 같이 bson 태그를 따르고, 호출한 쪽과 값을 공유하지 않는다
+// 조회 결과가 없으면 MongoDB 저장소와 같이 nil 을 돌려준다
+type (
+	memoryUsers struct {
+		mu    sync.RWMutex
+		users map[bson.ObjectId]*model.User
+	}
+
+	memoryPosts struct {
+		mu    sync.RWMutex
+		posts map[bson.ObjectId]*model.Post
+	}
+
+	memoryNotices struct {
+		memoryPosts
+	}
+
+	memoryNotifications struct {
+		mu            sync.RWMutex
+		notifications map[bson.ObjectId]*model.Notification
+	}
+
+	memorySubscribers struct {
+		mu          sync.RWMutex
+		subscribers map[bson.ObjectId]*model.Subscriber
+	}
+
+	memoryNewsletters struct {
+		mu     sync.RWMutex
+		issues map[bson.ObjectId]*model.NewsletterIssue
+	}
+
+	memoryBroadcasts struct {
+		mu         sync.RWMutex
+		broadcasts map[bson.ObjectId]*model.Broadcast
+	}
+
+	memoryAnthologies struct {
+		mu          sync.RWMutex
+		anthologies map[bson.ObjectId]*model.Anthology
+	}
+
+	memoryMedia struct {
+		mu    sync.RWMutex
+		media map[bson.ObjectId]*model.Media
+	}
+
+	memoryOutbox struct {
+		mu       sync.RWMutex
+		messages map[bson.ObjectId]*model.OutboxMessage
+	}
+
+	memoryAssets struct {
+		mu     sync.RWMutex
+		assets map[bson.ObjectId]*model.Asset
+	}
+
+	memoryDatabase struct{}
+)
+
+func NewMemory() Repositories {
+	return Repositories{
+		Database:      memoryDatabase{},
+		Users:         &memoryUsers{users: map[bson.ObjectId]*model.User{}},
+		Stories:       newMemoryPosts(),
+		Board:         newMemoryPosts(),
+		Notices:       &memoryNotices{memoryPosts{posts: map[bson.ObjectId]*model.Post{}}},
+		Notifications: &memoryNotifications{notifications: map[bson.ObjectId]*model.Notification{}},
+		Subscribers:   &memorySubscribers{subscribers: map[bson.ObjectId]*model.Subscriber{}},
+		Newsletters:   &memoryNewsletters{issues: map[bson.ObjectId]*model.NewsletterIssue{}},
+		Broadcasts:    &memoryBroadcasts{broadcasts: map[bson.ObjectId]*model.Broadcast{}},
+		Anthologies:   &memoryAnthologies{anthologies: map[bson.ObjectId]*model.Anthology{}},
+		Media:         &memoryMedia{media: map[bson.ObjectId]*model.Media{}},
+		Outbox:        &memoryOutbox{messages: map[bson.ObjectId]*model.OutboxMessage{}},
+		Assets:        &memoryAssets{assets: map[bson.ObjectId]*model.Asset{}},
+	}
+}
+
+func (memoryDatabase) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (memoryDatabase) EnsureIndexes(ctx context.Context) error {
+	return nil
+}
+
+func copyDoc(in interface{}, out interface{}) error {
+	data, err := bson.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return bson.Unmarshal(data, out)
+}
+
+func updateDoc(stored interface{}, doc interface{}, fields []string, out interface{}) error {
+	// stored 에 doc 의 fields 를 반영해 out 에 담는다: MongoDB 저장소의 $set, $unset 과 같다
+	set, unset, err := changes(doc, fields)
+	if err != nil {
+		return err
+	}
+	values := bson.M{}
+	if err = copyDoc(stored, values); err != nil {
+		return err
+	}
+	for field, value := range set {
+		values[field] = value
+	}
+	for field := range unset {
+		delete(values, field)
+	}
+	return copyDoc(values, out)
+}
+
+//------
+// Users
+//------
+
+func (r *memoryUsers) duplicate(u *model.User) bool {
+	// MongoDB 의 고유 인덱스와 같이 이메일과 닉네임이 모두 같은 다른 회원이 있는지 확인한다
+	for _, other := range r.users {
+		if other.ID != u.ID && other.Email == u.Email && other.Nickname == u.Nickname {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryUsers) Create(ctx context.Context, u *model.User) error {
+	stored := new(model.User)
+	if err := copyDoc(u, stored); err != nil {
+		return err
+	}
+	if stored.ID == "" {
+		stored.ID = bson.NewObjectId()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[stored.ID]; ok || r.duplicate(stored) {
+		return ErrDuplicate
+	}
+	r.users[stored.ID] = stored
+	return nil
+}
+
+func (r *memoryUsers) find(match func(u *model.User) bool) (*model.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, u := range r.users {
+		if match(u) {
+			return copyUser(u)
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func copyUser(stored *model.User) (*model.User, error) {
+	// 조회 결과에는 패스워드를 담지 않는다
+	u := new(model.User)
+	if err := copyDoc(stored, u); err != nil {
+		return nil, err
+	}
+	u.Password = ""
+	return u, nil
+}
+
+func (r *memoryUsers) Get(ctx context.Context, id bson.ObjectId) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.ID == id })
+}
+
+func (r *memoryUsers) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.Email == email })
+}
+
+func (r *memoryUsers) Authenticate(ctx context.Context, email string, passwordHash string) (*model.User, error) {
+	return r.find(func(u *model.User) bool { return u.Email == email && u.Password == passwordHash })
+}
+
+func (r *memoryUsers) List(ctx context.Context, q UserQuery) ([]*model.User, error) {
+	var ids map[bson.ObjectId]bool
+	if q.IDs != nil {
+		ids = make(map[bson.ObjectId]bool)
+		for _, id := range q.IDs {
+			ids[id] = true
+		}
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var users []*model.User
+	for _, u := range r.users {
+		if (ids != nil && !ids[u.ID]) ||
+			(q.Active && !u.IsActive) ||
+			(q.Staff && !u.IsStaff) ||
+			(q.Admin && !u.IsAdmin) ||
+			(q.Unmuted != "" && contains(u.MutedNotifications, q.Unmuted)) {
+			continue
+		}
+		user, err := copyUser(u)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	// 관리자, 필진, 닉네임 순
+	sort.Slice(users, func(i, j int) bool {
+		a, b := users[i], users[j]
+		if a.IsAdmin != b.IsAdmin {
+			return a.IsAdmin
+		}
+		if a.IsStaff != b.IsStaff {
+			return a.IsStaff
+		}
+		return a.Nickname < b.Nickname
+	})
+	return users, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *memoryUsers) Update(ctx context.Context, u *model.User, fields ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.users[u.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	updated := new(model.User)
+	if err := updateDoc(stored, u, fields, updated); err != nil {
+		return err
+	}
+	if r.duplicate(updated) {
+		return ErrDuplicate
+	}
+	r.users[u.ID] = updated
+	return nil
+}
+
+func (r *memoryUsers) Delete(ctx context.Context, id bson.ObjectId) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.users[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.users, id)
+	return nil
+}
+
+//------
+// Posts
+//------
+
+func newMemoryPosts() *memoryPosts {
+	return &memoryPosts{posts: map[bson.ObjectId]*model.Post{}}
+}
+
+func copyPost(stored *model.Post, summary bool) (*model.Post, error) {
+	p := new(model.Post)
+	if err := copyDoc(stored, p); err != nil {
+		return nil, err
+	}
+	if summary {
+		p.Content = ""
+	}
+	return p, nil
+}
+
+func (r *memoryPosts) Create(ctx context.Context, p *model.Post) error {
+	stored := new(model.Post)
+	if err := copyDoc(p, stored); err != nil {
+		return err
+	}
+	if stored.ID == "" {
+		stored.ID = bson.NewObjectId()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.posts[stored.ID]; ok {
+		return ErrDuplicate
+	}
+	r.posts[stored.ID] = stored
+	return nil
+}
+
+func (r *memoryPosts) Get(ctx context.Context, id bson.ObjectId) (*model.Post, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stored, ok := r.posts[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyPost(stored, false)
+}
+
+func (r *memoryPosts) filter(match func(p *model.Post) bool) []*model.Post {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var posts []*model.Post
+	for _, p := range r.posts {
+		if match(p) {
+			posts = append(posts, p)
+		}
+	}
+	return posts
+}
+
+func (q PostQuery) match(p *model.Post) bool {
+	if q.IDs != nil && !containsID(q.IDs, p.ID) {
+		return false
+	}
+	return (q.AuthorID == "" || p.AuthorID == q.AuthorID) &&
+		(!q.Published || p.IsPublished) &&
+		(q.Series == "" || p.Series == q.Series) &&
+		(q.Title == "" || p.Title == q.Title) &&
+		(q.DateCreated == "" || p.DateCreated == q.DateCreated)
+}
+
+func containsID(ids []bson.ObjectId, id bson.ObjectId) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func page(posts []*model.Post, summary bool, skip int, limit int, less func(a, b *model.Post) bool) ([]*model.Post, error) {
+	// 정렬한 뒤 skip 과 limit 을 적용해 복사한다: 정렬 기준이 같으면 먼저 만든 글(ID 순)이 앞선다
+	sort.Slice(posts, func(i, j int) bool {
+		a, b := posts[i], posts[j]
+		if less(a, b) != less(b, a) {
+			return less(a, b)
+		}
+		return a.ID < b.ID
+	})
+	start, end := window(len(posts), skip, limit)
+	posts = posts[start:end]
+
+	var result []*model.Post
+	for _, stored := range posts {
+		p, err := copyPost(stored, summary)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, p)
+	}
+	return result, nil
+}
+
+func window(n int, skip int, limit int) (start int, end int) {
+	// n 개의 정렬된 결과에 skip 과 limit 을 적용한 범위: limit 이 0 이면 끝까지
+	start, end = skip, n
+	if start < 0 {
+		start = 0
+	} else if start > n {
+		start = n
+	}
+	if limit > 0 && start+limit < n {
+		end = start + limit
+	}
+	return
+}
+
+func newestFirst(a, b *model.Post) bool {
+	return a.DateCreated > b.DateCreated
+}
+
+func (r *memoryPosts) List(ctx context.Context, q PostQuery) ([]*model.Post, error) {
+	less := newestFirst
+	if q.Oldest {
+		less = func(a, b *model.Post) bool { return a.DateCreated < b.DateCreated }
+	}
+	return page(r.filter(q.match), q.Summary, q.Skip, q.Limit, less)
+}
+
+func (r *memoryPosts) Count(ctx context.Context, q PostQuery) (int, error) {
+	return len(r.filter(q.match)), nil
+}
+
+func (r *memoryPosts) Each(ctx context.Context, fn func(p *model.Post) error) error {
+	// 읽는 동안 fn 이 저장소를 바꿀 수 있도록 잠금 없이 호출한다
+	posts, err := page(r.filter(func(p *model.Post) bool { return true }), false, 0, 0, newestFirst)
+	if err != nil {
+		return err
+	}
+	for _, p := range posts {
+		if err = fn(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryPosts) Update(ctx context.Context, p *model.Post, fields ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.posts[p.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	updated := new(model.Post)
+	if err := updateDoc(stored, p, fields, updated); err != nil {
+		return err
+	}
+	r.posts[p.ID] = updated
+	return nil
+}
+
+func (r *memoryPosts) Delete(ctx context.Context, id bson.ObjectId) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.posts[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.posts, id)
+	return nil
+}
+
+//--------
+// Notices
+//--------
+
+func noticeMatch(active bool, pinned bool) func(p *model.Post) bool {
+	now := time.Now()
+	return func(p *model.Post) bool {
+		// 게시 종료 일시가 없거나 아직 지나지 않은 공지
+		return p.IsPinned == pinned && (!active || p.ExpiresAt == nil || p.ExpiresAt.After(now))
+	}
+}
+
+func (r *memoryNotices) ListPinned(ctx context.Context, active bool) ([]*model.Post, error) {
+	return page(r.filter(noticeMatch(active, true)), true, 0, 0, func(a, b *model.Post) bool {
+		if a.PinOrder != b.PinOrder {
+			return a.PinOrder < b.PinOrder
+		}
+		return newestFirst(a, b)
+	})
+}
+
+func (r *memoryNotices) ListUnpinned(ctx context.Context, active bool, skip int, limit int) ([]*model.Post, error) {
+	return page(r.filter(noticeMatch(active, false)), true, skip, limit, newestFirst)
+}
+
+func (r *memoryNotices) CountUnpinned(ctx context.Context, active bool) (int, error) {
+	return len(r.filter(noticeMatch(active, false))), nil
+}
+
+//--------------
+// Notifications
+//--------------
+
+func (r *memoryNotifications) create(n *model.Notification) error {
+	stored := new(model.Notification)
+	if err := copyDoc(n, stored); err != nil {
+		return err
+	}
+	if stored.ID == "" {
+		stored.ID = bson.NewObjectId()
+	}
+	if _, ok := r.notifications[stored.ID]; ok {
+		return ErrDuplicate
+	}
+	r.notifications[stored.ID] = stored
+	return nil
+}
+
+func (r *memoryNotifications) Create(ctx context.Context, n *model.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.create(n)
+}
+
+func (r *memoryNotifications) CreateMany(ctx context.Context, notifications []*model.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range notifications {
+		if err := r.create(n); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryNotifications) List(ctx context.Context, userID bson.ObjectId, skip int, limit int) ([]*model.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var matched []*model.Notification
+	for _, n := range r.notifications {
+		if n.UserID == userID {
+			matched = append(matched, n)
+		}
+	}
+
+	// 생성일자 역순: 같으면 ID 순
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if !a.DateCreated.Equal(b.DateCreated) {
+			return a.DateCreated.After(b.DateCreated)
+		}
+		return a.ID < b.ID
+	})
+	start, end := window(len(matched), skip, limit)
+
+	var notifications []*model.Notification
+	for _, stored := range matched[start:end] {
+		n := new(model.Notification)
+		if err := copyDoc(stored, n); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, nil
+}
+
+func (r *memoryNotifications) CountUnread(ctx context.Context, userID bson.ObjectId) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	count := 0
+	for _, n := range r.notifications {
+		if n.UserID == userID && !n.IsRead {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryNotifications) MarkRead(ctx context.Context, userID bson.ObjectId, id bson.ObjectId) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n, ok := r.notifications[id]
+	if !ok || n.UserID != userID {
+		return ErrNotFound
+	}
+	n.IsRead = true
+	return nil
+}
+
+func (r *memoryNotifications) MarkAllRead(ctx context.Context, userID bson.ObjectId) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, n := range r.notifications {
+		if n.UserID == userID {
+			n.IsRead = true
+		}
+	}
+	return nil
+}
+
+//------------
+// Subscribers
+//------------
+
+func (r *memorySubscribers) GetOrCreate(ctx context.Context, sub *model.Subscriber) (*model.Subscriber, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var stored *model.Subscriber
+	for _, s := range r.subscribers {
+		if s.Email == sub.Email {
+			stored = s
+			break
+		}
+	}
+
+	// MongoDB 저장소의 $setOnInsert 와 같이 처음 만들 때만 sub 의 값을 쓴다
+	if stored == nil {
+		stored = &model.Subscriber{
+			ID:          bson.NewObjectId(),
+			Email:       sub.Email,
+			Locale:      sub.Locale,
+			IsConfirmed: sub.IsConfirmed,
+			DateCreated: sub.DateCreated,
+		}
+		r.subscribers[stored.ID] = stored
+	}
+
+	result := new(model.Subscriber)
+	if err := copyDoc(stored, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *memorySubscribers) MarkConfirmSent(ctx context.Context, id bson.ObjectId, now time.Time, cooldown time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.subscribers[id]
+	if !ok || s.IsConfirmed || (s.DateConfirmSent != nil && !s.DateConfirmSent.Before(now.Add(-cooldown))) {
+		return ErrNotFound
+	}
+	s.DateConfirmSent = &now
+	return nil
+}
+
+func (r *memorySubscribers) Confirm(ctx context.Context, id bson.ObjectId, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s, ok := r.subscribers[id]
+	if !ok || s.IsConfirmed {
+		return ErrNotFound
+	}
+	s.IsConfirmed = true
+	s.DateConfirmed = &now
+	return nil
+}
+
+func (r *memorySubscribers) ListConfirmed(ctx context.Context) ([]*model.Subscriber, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var subscribers []*model.Subscriber
+	for _, stored := range r.subscribers {
+		if !stored.IsConfirmed {
+			continue
+		}
+		s := new(model.Subscriber)
+		if err := copyDoc(stored, s); err != nil {
+			return nil, err
+		}
+		subscribers = append(subscribers, s)
+	}
+	return subscribers, nil
+}
+
+func (r *memorySubscribers) Delete(ctx context.Context, id bson.ObjectId) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.subscribers[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.subscribers, id)
+	return nil
+}
+
+//------------
+// Newsletters
+//------------
+
+func (r *memoryNewsletters) Create(ctx context.Context, issue *model.NewsletterIssue) error {
+	stored := new(model.NewsletterIssue)
+	if err := copyDoc(issue, stored); err != nil {
+		return err
+	}
+	if stored.ID == "" {
+		stored.ID = bson.NewObjectId()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.issues[stored.ID]; ok {
+		return ErrDuplicate
+	}
+	r.issues[stored.ID] = stored
+	return nil
+}
+
+func (r *memoryNewsletters) List(ctx context.Context) ([]*model.NewsletterIssue, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var issues []*model.NewsletterIssue
+	for _, stored := range r.issues {
+		issue := new(model.NewsletterIssue)
+		if err := copyDoc(stored, issue); err != nil {
+			return nil, err
+		}
+		issues = append(issues, issue)
+	}
+
+	// 생성일자 역순: 같으면 ID 순
+	sort.Slice(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if !a.DateCreated.Equal(b.DateCreated) {
+			return a.DateCreated.After(b.DateCreated)
+		}
+		return a.ID < b.ID
+	})
+	return issues, nil
+}
+
+func (r *memoryNewsletters) Update(ctx context.Context, issue *model.NewsletterIssue, fields ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.issues[issue.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	updated := new(model.NewsletterIssue)
+	if err := updateDoc(stored, issue, fields, updated); err != nil {
+		return err
+	}
+	r.issues[issue.ID] = updated
+	return nil
+}
+
+//-----------
+// Broadcasts
+//-----------
+
+func (r *memoryBroadcasts) Create(ctx context.Context, b *model.Broadcast) error {
+	stored := new(model.Broadcast)
+	if err := copyDoc(b, stored); err != nil {
+		return err
+	}
+	if stored.ID == "" {
+		stored.ID = bson.NewObjectId()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.broadcasts[stored.ID]; ok {
+		return ErrDuplicate
+	}
+	r.broadcasts[stored.ID] = stored
+	return nil
+}
+
+func (r *memoryBroadcasts) List(ctx context.Context, noticeID bson.ObjectId) ([]*model.Broadcast, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var broadcasts []*model.Broadcast
+	for _, stored := range r.broadcasts {
+		if stored.NoticeID != noticeID {
+			continue
+		}
+		b := new(model.Broadcast)
+		if err := copyDoc(stored, b); err != nil {
+			return nil, err
+		}
+		broadcasts = append(broadcasts, b)
+	}
+
+	// 생성일자 역순: 같으면 ID 순
+	sort.Slice(broadcasts, func(i, j int) bool {
+		a, b := broadcasts[i], broadcasts[j]
+		if !a.DateCreated.Equal(b.DateCreated) {
+			return a.DateCreated.After(b.DateCreated)
+		}
+		return a.ID < b.ID
+	})
+	return broadcasts, nil
+}
+
+func (r *memoryBroadcasts) Update(ctx context.Context, b *model.Broadcast, fields ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.broadcasts[b.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	updated := new(model.Broadcast)
+	if err := updateDoc(stored, b, fields, updated); err != nil {
+		return err
+	}
+	r.broadcasts[b.ID] = updated
+	return nil
+}
+
+//------------
+// Anthologies
+//------------
+
+func (r *memoryAnthologies) Create(ctx context.Context, a *model.Anthology) error {
+	stored := new(model.Anthology)
+	if err := copyDoc(a, stored); err != nil {
+		return err
+	}
+	if stored.ID == "" {
+		stored.ID = bson.NewObjectId()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.anthologies[stored.ID]; ok {
+		return ErrDuplicate
+	}
+	r.anthologies[stored.ID] = stored
+	return nil
+}
+
+func (r *memoryAnthologies) Get(ctx context.Context, id bson.ObjectId) (*model.Anthology, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	stored, ok := r.anthologies[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	a := new(model.Anthology)
+	if err := copyDoc(stored, a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (r *memoryAnthologies) List(ctx context.Context) ([]*model.Anthology, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var anthologies []*model.Anthology
+	for _, stored := range r.anthologies {
+		a := new(model.Anthology)
+		if err := copyDoc(stored, a); err != nil {
+			return nil, err
+		}
+		a.Preface, a.Colophon = "", ""
+		anthologies = append(anthologies, a)
+	}
+
+	// 발행 연도, 생성일자 역순: 같으면 ID 순
+	sort.Slice(anthologies, func(i, j int) bool {
+		a, b := anthologies[i], anthologies[j]
+		if a.Year != b.Year {
+			return a.Year > b.Year
+		}
+		if a.DateCreated != b.DateCreated {
+			return a.DateCreated > b.DateCreated
+		}
+		return a.ID < b.ID
+	})
+	return anthologies, nil
+}
+
+func (r *memoryAnthologies) Update(ctx context.Context, a *model.Anthology, fields ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.anthologies[a.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	updated := new(model.Anthology)
+	if err := updateDoc(stored, a, fields, updated); err != nil {
+		return err
+	}
+	r.anthologies[a.ID] = updated
+	return nil
+}
+
+func (r *memoryAnthologies) Delete(ctx context.Context, id bson.ObjectId) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.anthologies[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.anthologies, id)
+	return nil
+}
+
+//------
+// Media
+//------
+
+func (r *memoryMedia) Create(ctx context.Context, m *model.Media) error {
+	stored := new(model.Media)
+	if err := copyDoc(m, stored); err != nil {
+		return err
+	}
+	if stored.ID == "" {
+		stored.ID = bson.NewObjectId()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.media[stored.ID]; ok {
+		return ErrDuplicate
+	}
+	r.media[stored.ID] = stored
+	return nil
+}
+
+func (r *memoryMedia) sorted(match func(m *model.Media) bool) []*model.Media {
+	// 생성일자 역순: 같으면 ID 순
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var media []*model.Media
+	for _, m := range r.media {
+		if match(m) {
+			media = append(media, m)
+		}
+	}
+	sort.Slice(media, func(i, j int) bool {
+		a, b := media[i], media[j]
+		if !a.DateCreated.Equal(b.DateCreated) {
+			return a.DateCreated.After(b.DateCreated)
+		}
+		return a.ID < b.ID
+	})
+	return media
+}
+
+func copyMedia(stored *model.Media) (*model.Media, error) {
+	m := new(model.Media)
+	if err := copyDoc(stored, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *memoryMedia) List(ctx context.Context, ownerID bson.ObjectId, skip int, limit int) ([]*model.Media, error) {
+	matched := r.sorted(func(m *model.Media) bool { return m.OwnerID == ownerID })
+	start, end := window(len(matched), skip, limit)
+
+	var media []*model.Media
+	for _, stored := range matched[start:end] {
+		m, err := copyMedia(stored)
+		if err != nil {
+			return nil, err
+		}
+		media = append(media, m)
+	}
+	return media, nil
+}
+
+func (r *memoryMedia) Each(ctx context.Context, fn func(m *model.Media) error) error {
+	// 읽는 동안 fn 이 저장소를 바꿀 수 있도록 잠금 없이 호출한다
+	for _, stored := range r.sorted(func(m *model.Media) bool { return true }) {
+		m, err := copyMedia(stored)
+		if err != nil {
+			return err
+		}
+		if err = fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *memoryMedia) UpdateOwned(ctx context.Context, m *model.Media, fields ...string) (*model.Media, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.media[m.ID]
+	if !ok || stored.OwnerID != m.OwnerID {
+		return nil, ErrNotFound
+	}
+	updated := new(model.Media)
+	if err := updateDoc(stored, m, fields, updated); err != nil {
+		return nil, err
+	}
+	r.media[m.ID] = updated
+	return copyMedia(updated)
+}
+
+func (r *memoryMedia) DeleteOwned(ctx context.Context, ownerID bson.ObjectId, id bson.ObjectId) (*model.Media, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.media[id]
+	if !ok || stored.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	delete(r.media, id)
+	return stored, nil
+}
+
+func (r *memoryMedia) Delete(ctx context.Context, id bson.ObjectId) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.media[id]; !ok {
+		return ErrNotFound
+	}
+	delete(r.media, id)
+	return nil
+}
+
+func (r *memoryMedia) Usage(ctx context.Context, ownerID bson.ObjectId) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var size int64
+	for _, m := range r.media {
+		if m.OwnerID == ownerID {
+			size += m.Size
+		}
+	}
+	return size, nil
+}
+
+func (r *memoryMedia) ListUsage(ctx context.Context) ([]*model.MediaUsage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	owners := make(map[bson.ObjectId]*model.MediaUsage)
+	usages := []*model.MediaUsage{}
+	for _, m := range r.media {
+		usage, ok := owners[m.OwnerID]
+		if !ok {
+			usage = &model.MediaUsage{UserID: m.OwnerID}
+			owners[m.OwnerID] = usage
+			usages = append(usages, usage)
+		}
+		usage.Count++
+		usage.Size += m.Size
+	}
+
+	// 용량 역순: 같으면 회원 ID 순
+	sort.Slice(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if a.Size != b.Size {
+			return a.Size > b.Size
+		}
+		return a.UserID < b.UserID
+	})
+	return usages, nil
+}
+
+//-------
+// Outbox
+//-------
+
+func (r *memoryOutbox) Create(ctx context.Context, m *model.OutboxMessage) error {
+	stored := new(model.OutboxMessage)
+	if err := copyDoc(m, stored); err != nil {
+		return err
+	}
+	if stored.ID == "" {
+		stored.ID = bson.NewObjectId()
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.messages[stored.ID]; ok {
+		return ErrDuplicate
+	}
+	r.messages[stored.ID] = stored
+	return nil
+}
+
+func copyOutboxMessage(stored *model.OutboxMessage) (*model.OutboxMessage, error) {
+	m := new(model.OutboxMessage)
+	if err := copyDoc(stored, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (r *memoryOutbox) List(ctx context.Context, status string, skip int, limit int) ([]*model.OutboxMessage, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var matched []*model.OutboxMessage
+	for _, m := range r.messages {
+		failed := m.Status == model.OutboxDead || (m.Status == model.OutboxPending && m.Attempts > 0)
+		if (status == "" && failed) || (status != "" && m.Status == status) {
+			matched = append(matched, m)
+		}
+	}
+
+	// 생성일자 역순: 같으면 ID 순
+	sort.Slice(matched, func(i, j int) bool {
+		a, b := matched[i], matched[j]
+		if !a.DateCreated.Equal(b.DateCreated) {
+			return a.DateCreated.After(b.DateCreated)
+		}
+		return a.ID < b.ID
+	})
+	start, end := window(len(matched), skip, limit)
+
+	var messages []*model.OutboxMessage
+	for _, stored := range matched[start:end] {
+		m, err := copyOutboxMessage(stored)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, nil
+}
+
+func (r *memoryOutbox) Retry(ctx context.Context, id bson.ObjectId, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m, ok := r.messages[id]
+	if !ok || (m.Status != model.OutboxPending && m.Status != model.OutboxDead) {
+		return ErrNotFound
+	}
+	m.Status, m.Attempts, m.NextAttempt = model.OutboxPending, 0, now
+	return nil
+}
+
+func (r *memoryOutbox) CountUnsent(ctx context.Context) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	count := 0
+	for _, m := range r.messages {
+		if m.Status == model.OutboxPending || m.Status == model.OutboxSending {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryOutbox) Claim(ctx context.Context, now time.Time, lease time.Duration) (*model.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 보낼 차례가 된 메일 중 다음 시도 시각이 가장 이른 메일: 같으면 ID 순
+	var claimed *model.OutboxMessage
+	for _, m := range r.messages {
+		due := (m.Status == model.OutboxPending && !m.NextAttempt.After(now)) ||
+			(m.Status == model.OutboxSending && m.LockedUntil != nil && m.LockedUntil.Before(now))
+		if !due {
+			continue
+		}
+		if claimed == nil || m.NextAttempt.Before(claimed.NextAttempt) ||
+			(m.NextAttempt.Equal(claimed.NextAttempt) && m.ID < claimed.ID) {
+			claimed = m
+		}
+	}
+	if claimed == nil {
+		return nil, ErrNotFound
+	}
+
+	lockedUntil := now.Add(lease)
+	claimed.Status = model.OutboxSending
+	claimed.LockedUntil = &lockedUntil
+	claimed.Attempts++
+	return copyOutboxMessage(claimed)
+}
+
+func (r *memoryOutbox) Update(ctx context.Context, m *model.OutboxMessage, fields ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored, ok := r.messages[m.ID]
+	if !ok {
+		return ErrNotFound
+	}
+	updated := new(model.OutboxMessage)
+	if err := updateDoc(stored, m, fields, updated); err != nil {
+		return err
+	}
+	r.messages[m.ID] = updated
+	return nil
+}
+
+//-------
+// Assets
+//-------
+
+func (r *memoryAssets) byKey(key string) *model.Asset {
+	for _, a := range r.assets {
+		if a.Key == key {
+			return a
+		}
+	}
+	return nil
+}
+
+func (r *memoryAssets) Register(ctx context.Context, a *model.Asset) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// 이미 기록된 파일: 아직 참조가 없다면 유예 기간을 다시 센다
+	if stored := r.byKey(a.Key); stored != nil {
+		if len(stored.Refs) == 0 {
+			stored.DateOrphaned = a.DateOrphaned
+		}
+		return false, nil
+	}
+
+	stored := &model.Asset{
+		ID:           bson.NewObjectId(),
+		Key:          a.Key,
+		OwnerID:      a.OwnerID,
+		Size:         a.Size,
+		Refs:         []bson.ObjectId{},
+		DateCreated:  a.DateCreated,
+		DateOrphaned: a.DateOrphaned,
+	}
+	r.assets[stored.ID] = stored
+	return true, nil
+}
+
+func (r *memoryAssets) Discover(ctx context.Context, key string, size int64, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if stored := r.byKey(key); stored != nil {
+		stored.Size = size
+		return nil
+	}
+
+	id := bson.NewObjectId()
+	r.assets[id] = &model.Asset{
+		ID:           id,
+		Key:          key,
+		Size:         size,
+		Refs:         []bson.ObjectId{},
+		DateCreated:  now,
+		DateOrphaned: &now,
+	}
+	return nil
+}
+
+func (r *memoryAssets) Track(ctx context.Context, refID bson.ObjectId, keys []string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	using := make(map[string]bool)
+	for _, key := range keys {
+		using[key] = true
+	}
+
+	// 더 이상 쓰지 않는 파일의 참조 해제: 참조가 모두 사라지면 지금부터 유예 기간을 센다
+	for _, a := range r.assets {
+		if using[a.Key] || !containsID(a.Refs, refID) {
+			continue
+		}
+		refs := []bson.ObjectId{}
+		for _, id := range a.Refs {
+			if id != refID {
+				refs = append(refs, id)
+			}
+		}
+		a.Refs = refs
+		if len(refs) == 0 && a.DateOrphaned == nil {
+			orphaned := now
+			a.DateOrphaned = &orphaned
+		}
+	}
+
+	// 새로 쓰는 파일 참조: 기록되지 않은 파일이라도 지워지지 않도록 기록을 만든다
+	for _, key := range keys {
+		a := r.byKey(key)
+		if a == nil {
+			a = &model.Asset{ID: bson.NewObjectId(), Key: key, Refs: []bson.ObjectId{}, DateCreated: now}
+			r.assets[a.ID] = a
+		}
+		if !containsID(a.Refs, refID) {
+			a.Refs = append(a.Refs, refID)
+		}
+		a.DateOrphaned = nil
+	}
+	return nil
+}
+
+func (r *memoryAssets) ListOrphaned(ctx context.Context, before time.Time) ([]*model.Asset, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var assets []*model.Asset
+	for _, stored := range r.assets {
+		if len(stored.Refs) > 0 || stored.DateOrphaned == nil || !stored.DateOrphaned.Before(before) {
+			continue
+		}
+		a := new(model.Asset)
+		if err := copyDoc(stored, a); err != nil {
+			return nil, err
+		}
+		assets = append(assets, a)
+	}
+
+	// 참조가 사라진 순: 같으면 ID 순
+	sort.Slice(assets, func(i, j int) bool {
+		a, b := assets[i], assets[j]
+		if !a.DateOrphaned.Equal(*b.DateOrphaned) {
+			return a.DateOrphaned.Before(*b.DateOrphaned)
+		}
+		return a.ID < b.ID
+	})
+	return assets, nil
+}
+
+func (r *memoryAssets) DeleteOrphaned(ctx context.Context, id bson.ObjectId, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.assets[id]
+	if !ok || len(a.Refs) > 0 || a.DateOrphaned == nil || !a.DateOrphaned.Before(before) {
+		return ErrNotFound
+	}
+	delete(r.assets, id)
+	return nil
+}
@@ -0,0 +1,892 @@
+package repository
+
+import (
+	// Default package
+	"fmt"
+	"time"
+	"context"
+	// Third Party package
+	"github.com/globalsign/mgo"
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/model"
+	"github.com/backend/mongo"
+)
+
+// MongoDB 저장소: 작업마다 세션을 복제하고, 작업 시간과 추적은 mongo 패키지가 기록한다
+type (
+	mongoRepository struct {
+		db       *mgo.Session
+		database string
+		name     string
+	}
+
+	mongoUsers struct {
+		mongoRepository
+	}
+
+	mongoPosts struct {
+		mongoRepository
+	}
+
+	mongoNotices struct {
+		mongoPosts
+	}
+
+	mongoNotifications struct {
+		mongoRepository
+	}
+
+	mongoSubscribers struct {
+		mongoRepository
+	}
+
+	mongoNewsletters struct {
+		mongoRepository
+	}
+
+	mongoBroadcasts struct {
+		mongoRepository
+	}
+
+	mongoAnthologies struct {
+		mongoRepository
+	}
+
+	mongoMedia struct {
+		mongoRepository
+	}
+
+	mongoOutbox struct {
+		mongoRepository
+	}
+
+	mongoAssets struct {
+		mongoRepository
+	}
+
+	mongoDatabase struct {
+		db       *mgo.Session
+		database string
+	}
+)
+
+func NewMongo(db *mgo.Session, database string) Repositories {
+	return Repositories{
+		Database:      &mongoDatabase{db, database},
+		Users:         &mongoUsers{mongoRepository{db, database, UserCollection}},
+		Stories:       &mongoPosts{mongoRepository{db, database, StoryCollection}},
+		Board:         &mongoPosts{mongoRepository{db, database, BoardCollection}},
+		Notices:       &mongoNotices{mongoPosts{mongoRepository{db, database, NoticeCollection}}},
+		Notifications: &mongoNotifications{mongoRepository{db, database, NotificationCollection}},
+		Subscribers:   &mongoSubscribers{mongoRepository{db, database, SubscriberCollection}},
+		Newsletters:   &mongoNewsletters{mongoRepository{db, database, NewsletterCollection}},
+		Broadcasts:    &mongoBroadcasts{mongoRepository{db, database, BroadcastCollection}},
+		Anthologies:   &mongoAnthologies{mongoRepository{db, database, AnthologyCollection}},
+		Media:         &mongoMedia{mongoRepository{db, database, MediaCollection}},
+		Outbox:        &mongoOutbox{mongoRepository{db, database, OutboxCollection}},
+		Assets:        &mongoAssets{mongoRepository{db, database, AssetCollection}},
+	}
+}
+
+// 컬렉션별 인덱스
+var mongoIndexes = []struct {
+	collection string
+	index      mgo.Index
+}{
+	// 인덱스 값으로 email 을 사용하며, 그 값은 고유하다
+	{UserCollection, mgo.Index{Key: []string{"email", "nickname"}, Unique: true}},
+	// 알림은 보관 기간이 지나면 자동으로 삭제된다
+	{NotificationCollection, mgo.Index{Key: []string{"date_created"}, ExpireAfter: NotificationRetention}},
+	{NotificationCollection, mgo.Index{Key: []string{"user_id", "-date_created"}}},
+	// 회원별 미디어 목록과 사용량 계산
+	{MediaCollection, mgo.Index{Key: []string{"owner_id", "-date_created"}}},
+	// outbox 워커가 보낼 차례가 된 메일을 찾는다
+	{OutboxCollection, mgo.Index{Key: []string{"status", "next_attempt"}}},
+	// 소식지 구독자의 이메일은 고유하다
+	{SubscriberCollection, mgo.Index{Key: []string{"email"}, Unique: true}},
+	// 저장소 파일 기록: 파일 경로는 고유하고, 정리 작업은 참조가 없는 파일을 찾는다
+	{AssetCollection, mgo.Index{Key: []string{"key"}, Unique: true}},
+	{AssetCollection, mgo.Index{Key: []string{"refs"}}},
+	{AssetCollection, mgo.Index{Key: []string{"date_orphaned"}}},
+}
+
+func (d *mongoDatabase) Ping(ctx context.Context) error {
+	db := d.db.Clone()
+	defer db.Close()
+	return db.Ping()
+}
+
+func (d *mongoDatabase) EnsureIndexes(ctx context.Context) error {
+	// 세션 하나로 모든 인덱스를 만든다: 이미 있으면 그대로 둔다
+	db := mongo.Clone(d.db, ctx, d.database)
+	defer db.Close()
+	for _, i := range mongoIndexes {
+		if err := db.C(i.collection).EnsureIndex(i.index); err != nil {
+			return fmt.Errorf("%s 인덱스 %v: %w", i.collection, i.index.Key, err)
+		}
+	}
+	return nil
+}
+
+func (r *mongoRepository) session(ctx context.Context) (*mongo.Session, *mongo.Collection) {
+	db := mongo.Clone(r.db, ctx, r.database)
+	return db, db.C(r.name)
+}
+
+func mongoError(err error) error {
+	// mgo 에러를 저장소 에러로 바꾼다
+	switch {
+	case err == mgo.ErrNotFound:
+		return ErrNotFound
+	case mgo.IsDup(err):
+		return ErrDuplicate
+	}
+	return err
+}
+
+func (r *mongoRepository) update(ctx context.Context, id bson.ObjectId, doc interface{}, fields []string) error {
+	set, unset, err := changes(doc, fields)
+	if err != nil {
+		return err
+	}
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if len(update) == 0 {
+		return nil
+	}
+
+	db, c := r.session(ctx)
+	defer db.Close()
+	return mongoError(c.UpdateId(id, update))
+}
+
+func (r *mongoRepository) insert(ctx context.Context, docs ...interface{}) error {
+	db, c := r.session(ctx)
+	defer db.Close()
+	return mongoError(c.Insert(docs...))
+}
+
+func (r *mongoRepository) delete(ctx context.Context, id bson.ObjectId) error {
+	db, c := r.session(ctx)
+	defer db.Close()
+	return mongoError(c.RemoveId(id))
+}
+
+//------
+// Users
+//------
+
+// 조회 결과에서 빼는 필드
+var userSelector = bson.M{"password": 0}
+
+func (r *mongoUsers) Create(ctx context.Context, u *model.User) error {
+	db, c := r.session(ctx)
+	defer db.Close()
+	return mongoError(c.Insert(u))
+}
+
+func (r *mongoUsers) find(ctx context.Context, query bson.M) (*model.User, error) {
+	u := new(model.User)
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err := c.Find(query).Select(userSelector).One(u); err != nil {
+		return nil, mongoError(err)
+	}
+	return u, nil
+}
+
+func (r *mongoUsers) Get(ctx context.Context, id bson.ObjectId) (*model.User, error) {
+	return r.find(ctx, bson.M{"_id": id})
+}
+
+func (r *mongoUsers) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.find(ctx, bson.M{"email": email})
+}
+
+func (r *mongoUsers) Authenticate(ctx context.Context, email string, passwordHash string) (*model.User, error) {
+	return r.find(ctx, bson.M{"email": email, "password": passwordHash})
+}
+
+func (r *mongoUsers) List(ctx context.Context, q UserQuery) (users []*model.User, err error) {
+	query := bson.M{}
+	if q.IDs != nil {
+		query["_id"] = bson.M{"$in": q.IDs}
+	}
+	if q.Active {
+		query["is_active"] = true
+	}
+	if q.Staff {
+		query["is_staff"] = true
+	}
+	if q.Admin {
+		query["is_admin"] = true
+	}
+	if q.Unmuted != "" {
+		query["muted_notifications"] = bson.M{"$ne": q.Unmuted}
+	}
+
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err = c.
+		Find(query).
+		Select(userSelector).
+		Sort("-is_admin", "-is_staff", "nickname").
+		All(&users); err != nil {
+		return nil, mongoError(err)
+	}
+	return
+}
+
+func (r *mongoUsers) Update(ctx context.Context, u *model.User, fields ...string) error {
+	return r.update(ctx, u.ID, u, fields)
+}
+
+func (r *mongoUsers) Delete(ctx context.Context, id bson.ObjectId) error {
+	return r.delete(ctx, id)
+}
+
+//------
+// Posts
+//------
+
+func (r *mongoPosts) Create(ctx context.Context, p *model.Post) error {
+	db, c := r.session(ctx)
+	defer db.Close()
+	return mongoError(c.Insert(p))
+}
+
+func (r *mongoPosts) Get(ctx context.Context, id bson.ObjectId) (*model.Post, error) {
+	p := new(model.Post)
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err := c.FindId(id).One(p); err != nil {
+		return nil, mongoError(err)
+	}
+	return p, nil
+}
+
+func postQuery(q PostQuery) bson.M {
+	query := bson.M{}
+	if q.IDs != nil {
+		query["_id"] = bson.M{"$in": q.IDs}
+	}
+	if q.AuthorID != "" {
+		query["author_id"] = q.AuthorID
+	}
+	if q.Published {
+		query["is_published"] = true
+	}
+	if q.Series != "" {
+		query["series"] = q.Series
+	}
+	if q.Title != "" {
+		query["title"] = q.Title
+	}
+	if q.DateCreated != "" {
+		query["date_created"] = q.DateCreated
+	}
+	return query
+}
+
+func (r *mongoPosts) list(ctx context.Context, query bson.M, summary bool, skip int, limit int, sort ...string) (posts []*model.Post, err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	find := c.Find(query).Sort(sort...).Skip(skip).Limit(limit)
+	if summary {
+		find = find.Select(bson.M{"content": 0})
+	}
+	if err = find.All(&posts); err != nil {
+		return nil, mongoError(err)
+	}
+	return
+}
+
+func (r *mongoPosts) List(ctx context.Context, q PostQuery) ([]*model.Post, error) {
+	sort := "-date_created"
+	if q.Oldest {
+		sort = "date_created"
+	}
+	return r.list(ctx, postQuery(q), q.Summary, q.Skip, q.Limit, sort)
+}
+
+func (r *mongoPosts) Count(ctx context.Context, q PostQuery) (int, error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	count, err := c.Find(postQuery(q)).Count()
+	return count, mongoError(err)
+}
+
+func (r *mongoPosts) Each(ctx context.Context, fn func(p *model.Post) error) (err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	p := new(model.Post)
+	iter := c.Find(nil).Iter()
+	for iter.Next(p) {
+		if err = fn(p); err != nil {
+			iter.Close()
+			return
+		}
+		p = new(model.Post)
+	}
+	return iter.Close()
+}
+
+func (r *mongoPosts) Update(ctx context.Context, p *model.Post, fields ...string) error {
+	return r.update(ctx, p.ID, p, fields)
+}
+
+func (r *mongoPosts) Delete(ctx context.Context, id bson.ObjectId) error {
+	return r.delete(ctx, id)
+}
+
+//--------
+// Notices
+//--------
+
+func noticeQuery(active bool, pinned bool) bson.M {
+	query := bson.M{"is_pinned": true}
+	if !pinned {
+		query["is_pinned"] = bson.M{"$ne": true}
+	}
+	if active {
+		// 게시 종료 일시가 없거나 아직 지나지 않은 공지
+		query["$or"] = []bson.M{
+			{"expires_at": nil},
+			{"expires_at": bson.M{"$gt": time.Now()}}}
+	}
+	return query
+}
+
+func (r *mongoNotices) ListPinned(ctx context.Context, active bool) ([]*model.Post, error) {
+	return r.list(ctx, noticeQuery(active, true), true, 0, 0, "pin_order", "-date_created")
+}
+
+func (r *mongoNotices) ListUnpinned(ctx context.Context, active bool, skip int, limit int) ([]*model.Post, error) {
+	return r.list(ctx, noticeQuery(active, false), true, skip, limit, "-date_created")
+}
+
+func (r *mongoNotices) CountUnpinned(ctx context.Context, active bool) (int, error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	count, err := c.Find(noticeQuery(active, false)).Count()
+	return count, mongoError(err)
+}
+
+//--------------
+// Notifications
+//--------------
+
+func (r *mongoNotifications) Create(ctx context.Context, n *model.Notification) error {
+	return r.insert(ctx, n)
+}
+
+func (r *mongoNotifications) CreateMany(ctx context.Context, notifications []*model.Notification) error {
+	if len(notifications) == 0 {
+		return nil
+	}
+	db, c := r.session(ctx)
+	defer db.Close()
+	bulk := c.Bulk()
+	bulk.Unordered()
+	for _, n := range notifications {
+		bulk.Insert(n)
+	}
+	_, err := bulk.Run()
+	return mongoError(err)
+}
+
+func (r *mongoNotifications) List(ctx context.Context, userID bson.ObjectId, skip int, limit int) (notifications []*model.Notification, err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err = c.
+		Find(bson.M{"user_id": userID}).
+		Sort("-date_created").
+		Skip(skip).
+		Limit(limit).
+		All(&notifications); err != nil {
+		return nil, mongoError(err)
+	}
+	return
+}
+
+func (r *mongoNotifications) CountUnread(ctx context.Context, userID bson.ObjectId) (int, error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	count, err := c.Find(bson.M{"user_id": userID, "is_read": false}).Count()
+	return count, mongoError(err)
+}
+
+func (r *mongoNotifications) MarkRead(ctx context.Context, userID bson.ObjectId, id bson.ObjectId) error {
+	db, c := r.session(ctx)
+	defer db.Close()
+	return mongoError(c.Update(
+		bson.M{"_id": id, "user_id": userID},
+		bson.M{"$set": bson.M{"is_read": true}}))
+}
+
+func (r *mongoNotifications) MarkAllRead(ctx context.Context, userID bson.ObjectId) error {
+	db, c := r.session(ctx)
+	defer db.Close()
+	_, err := c.UpdateAll(
+		bson.M{"user_id": userID, "is_read": false},
+		bson.M{"$set": bson.M{"is_read": true}})
+	return mongoError(err)
+}
+
+//------------
+// Subscribers
+//------------
+
+func (r *mongoSubscribers) GetOrCreate(ctx context.Context, sub *model.Subscriber) (*model.Subscriber, error) {
+	// 동시에 신청해도 구독자는 하나만 만들어진다
+	result := new(model.Subscriber)
+	db, c := r.session(ctx)
+	defer db.Close()
+	if _, err := c.
+		Find(bson.M{"email": sub.Email}).
+		Apply(mgo.Change{
+		Update: bson.M{"$setOnInsert": bson.M{
+			"email":        sub.Email,
+			"locale":       sub.Locale,
+			"is_confirmed": sub.IsConfirmed,
+			"date_created": sub.DateCreated}},
+		Upsert:    true,
+		ReturnNew: true}, result); err != nil {
+		return nil, mongoError(err)
+	}
+	return result, nil
+}
+
+func (r *mongoSubscribers) MarkConfirmSent(ctx context.Context, id bson.ObjectId, now time.Time, cooldown time.Duration) error {
+	db, c := r.session(ctx)
+	defer db.Close()
+	return mongoError(c.Update(
+		bson.M{
+			"_id":          id,
+			"is_confirmed": false,
+			"$or": []bson.M{
+				{"date_confirm_sent": nil},
+				{"date_confirm_sent": bson.M{"$lt": now.Add(-cooldown)}}}},
+		bson.M{"$set": bson.M{"date_confirm_sent": now}}))
+}
+
+func (r *mongoSubscribers) Confirm(ctx context.Context, id bson.ObjectId, now time.Time) error {
+	db, c := r.session(ctx)
+	defer db.Close()
+	return mongoError(c.Update(
+		bson.M{"_id": id, "is_confirmed": false},
+		bson.M{"$set": bson.M{
+			"is_confirmed":   true,
+			"date_confirmed": now}}))
+}
+
+func (r *mongoSubscribers) ListConfirmed(ctx context.Context) (subscribers []*model.Subscriber, err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err = c.Find(bson.M{"is_confirmed": true}).All(&subscribers); err != nil {
+		return nil, mongoError(err)
+	}
+	return
+}
+
+func (r *mongoSubscribers) Delete(ctx context.Context, id bson.ObjectId) error {
+	return r.delete(ctx, id)
+}
+
+//------------
+// Newsletters
+//------------
+
+func (r *mongoNewsletters) Create(ctx context.Context, issue *model.NewsletterIssue) error {
+	return r.insert(ctx, issue)
+}
+
+func (r *mongoNewsletters) List(ctx context.Context) (issues []*model.NewsletterIssue, err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err = c.Find(nil).Sort("-date_created").All(&issues); err != nil {
+		return nil, mongoError(err)
+	}
+	return
+}
+
+func (r *mongoNewsletters) Update(ctx context.Context, issue *model.NewsletterIssue, fields ...string) error {
+	return r.update(ctx, issue.ID, issue, fields)
+}
+
+//-----------
+// Broadcasts
+//-----------
+
+func (r *mongoBroadcasts) Create(ctx context.Context, b *model.Broadcast) error {
+	return r.insert(ctx, b)
+}
+
+func (r *mongoBroadcasts) List(ctx context.Context, noticeID bson.ObjectId) (broadcasts []*model.Broadcast, err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err = c.Find(bson.M{"notice_id": noticeID}).Sort("-date_created").All(&broadcasts); err != nil {
+		return nil, mongoError(err)
+	}
+	return
+}
+
+func (r *mongoBroadcasts) Update(ctx context.Context, b *model.Broadcast, fields ...string) error {
+	return r.update(ctx, b.ID, b, fields)
+}
+
+//------------
+// Anthologies
+//------------
+
+func (r *mongoAnthologies) Create(ctx context.Context, a *model.Anthology) error {
+	return r.insert(ctx, a)
+}
+
+func (r *mongoAnthologies) Get(ctx context.Context, id bson.ObjectId) (*model.Anthology, error) {
+	a := new(model.Anthology)
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err := c.FindId(id).One(a); err != nil {
+		return nil, mongoError(err)
+	}
+	return a, nil
+}
+
+func (r *mongoAnthologies) List(ctx context.Context) (anthologies []*model.Anthology, err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err = c.
+		Find(nil).
+		Select(bson.M{"preface": 0, "colophon": 0}).
+		Sort("-year", "-date_created").
+		All(&anthologies); err != nil {
+		return nil, mongoError(err)
+	}
+	return
+}
+
+func (r *mongoAnthologies) Update(ctx context.Context, a *model.Anthology, fields ...string) error {
+	return r.update(ctx, a.ID, a, fields)
+}
+
+func (r *mongoAnthologies) Delete(ctx context.Context, id bson.ObjectId) error {
+	return r.delete(ctx, id)
+}
+
+//------
+// Media
+//------
+
+func (r *mongoMedia) Create(ctx context.Context, m *model.Media) error {
+	return r.insert(ctx, m)
+}
+
+func (r *mongoMedia) List(ctx context.Context, ownerID bson.ObjectId, skip int, limit int) (media []*model.Media, err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err = c.
+		Find(bson.M{"owner_id": ownerID}).
+		Sort("-date_created").
+		Skip(skip).
+		Limit(limit).
+		All(&media); err != nil {
+		return nil, mongoError(err)
+	}
+	return
+}
+
+func (r *mongoMedia) Each(ctx context.Context, fn func(m *model.Media) error) (err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	m := new(model.Media)
+	iter := c.Find(nil).Iter()
+	for iter.Next(m) {
+		if err = fn(m); err != nil {
+			iter.Close()
+			return
+		}
+		m = new(model.Media)
+	}
+	return iter.Close()
+}
+
+func (r *mongoMedia) UpdateOwned(ctx context.Context, m *model.Media, fields ...string) (*model.Media, error) {
+	set, unset, err := changes(m, fields)
+	if err != nil {
+		return nil, err
+	}
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	result := new(model.Media)
+	db, c := r.session(ctx)
+	defer db.Close()
+	query := c.Find(bson.M{"_id": m.ID, "owner_id": m.OwnerID})
+	if len(update) == 0 {
+		// 바꿀 값이 없으면 문서 전체를 덮어쓰지 않도록 조회만 한다
+		if err = query.One(result); err != nil {
+			return nil, mongoError(err)
+		}
+		return result, nil
+	}
+	if _, err = query.
+		Apply(mgo.Change{Update: update, ReturnNew: true}, result); err != nil {
+		return nil, mongoError(err)
+	}
+	return result, nil
+}
+
+func (r *mongoMedia) DeleteOwned(ctx context.Context, ownerID bson.ObjectId, id bson.ObjectId) (*model.Media, error) {
+	result := new(model.Media)
+	db, c := r.session(ctx)
+	defer db.Close()
+	if _, err := c.
+		Find(bson.M{"_id": id, "owner_id": ownerID}).
+		Apply(mgo.Change{Remove: true}, result); err != nil {
+		return nil, mongoError(err)
+	}
+	return result, nil
+}
+
+func (r *mongoMedia) Delete(ctx context.Context, id bson.ObjectId) error {
+	return r.delete(ctx, id)
+}
+
+func (r *mongoMedia) Usage(ctx context.Context, ownerID bson.ObjectId) (int64, error) {
+	var result struct {
+		Size int64 `bson:"size"`
+	}
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err := c.
+		Pipe([]bson.M{
+		{"$match": bson.M{"owner_id": ownerID}},
+		{"$group": bson.M{"_id": nil, "size": bson.M{"$sum": "$size"}}}}).
+		One(&result); err != nil {
+		if err == mgo.ErrNotFound {
+			return 0, nil
+		}
+		return 0, mongoError(err)
+	}
+	return result.Size, nil
+}
+
+func (r *mongoMedia) ListUsage(ctx context.Context) (usages []*model.MediaUsage, err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err = c.
+		Pipe([]bson.M{
+		{"$group": bson.M{
+			"_id":   "$owner_id",
+			"count": bson.M{"$sum": 1},
+			"size":  bson.M{"$sum": "$size"}}},
+		{"$sort": bson.M{"size": -1}}}).
+		All(&usages); err != nil {
+		return nil, mongoError(err)
+	}
+	return
+}
+
+//-------
+// Outbox
+//-------
+
+func (r *mongoOutbox) Create(ctx context.Context, m *model.OutboxMessage) error {
+	return r.insert(ctx, m)
+}
+
+func (r *mongoOutbox) List(ctx context.Context, status string, skip int, limit int) (messages []*model.OutboxMessage, err error) {
+	query := bson.M{"$or": []bson.M{
+		{"status": model.OutboxDead},
+		{"status": model.OutboxPending, "attempts": bson.M{"$gt": 0}},
+	}}
+	if status != "" {
+		query = bson.M{"status": status}
+	}
+
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err = c.
+		Find(query).
+		Sort("-date_created").
+		Skip(skip).
+		Limit(limit).
+		All(&messages); err != nil {
+		return nil, mongoError(err)
+	}
+	return
+}
+
+func (r *mongoOutbox) Retry(ctx context.Context, id bson.ObjectId, now time.Time) error {
+	db, c := r.session(ctx)
+	defer db.Close()
+	return mongoError(c.Update(
+		bson.M{
+			"_id":    id,
+			"status": bson.M{"$in": []string{model.OutboxPending, model.OutboxDead}}},
+		bson.M{"$set": bson.M{
+			"status":       model.OutboxPending,
+			"attempts":     0,
+			"next_attempt": now}}))
+}
+
+func (r *mongoOutbox) CountUnsent(ctx context.Context) (int, error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	count, err := c.
+		Find(bson.M{"status": bson.M{"$in": []string{model.OutboxPending, model.OutboxSending}}}).
+		Count()
+	return count, mongoError(err)
+}
+
+func (r *mongoOutbox) Claim(ctx context.Context, now time.Time, lease time.Duration) (*model.OutboxMessage, error) {
+	// 여러 워커나 서버가 같은 메일을 선점하지 않도록 찾기와 수정을 한 번에 한다
+	m := new(model.OutboxMessage)
+	db, c := r.session(ctx)
+	defer db.Close()
+	if _, err := c.
+		Find(bson.M{"$or": []bson.M{
+		{"status": model.OutboxPending, "next_attempt": bson.M{"$lte": now}},
+		{"status": model.OutboxSending, "locked_until": bson.M{"$lt": now}},
+	}}).
+		Sort("next_attempt").
+		Apply(mgo.Change{
+		Update: bson.M{
+			"$set": bson.M{
+				"status":       model.OutboxSending,
+				"locked_until": now.Add(lease)},
+			"$inc": bson.M{"attempts": 1}},
+		ReturnNew: true}, m); err != nil {
+		return nil, mongoError(err)
+	}
+	return m, nil
+}
+
+func (r *mongoOutbox) Update(ctx context.Context, m *model.OutboxMessage, fields ...string) error {
+	return r.update(ctx, m.ID, m, fields)
+}
+
+//-------
+// Assets
+//-------
+
+func (r *mongoAssets) Register(ctx context.Context, a *model.Asset) (bool, error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	info, err := c.Upsert(
+		bson.M{"key": a.Key},
+		bson.M{"$setOnInsert": bson.M{
+			"owner_id":      a.OwnerID,
+			"size":          a.Size,
+			"refs":          []bson.ObjectId{},
+			"date_created":  a.DateCreated,
+			"date_orphaned": a.DateOrphaned}})
+	if err != nil {
+		return false, mongoError(err)
+	}
+	if info.UpsertedId != nil {
+		return true, nil
+	}
+
+	// 아직 참조가 없다면 정리 작업이 지우지 않도록 유예 기간을 다시 센다
+	if err = c.Update(
+		bson.M{"key": a.Key, "refs": bson.M{"$size": 0}},
+		bson.M{"$set": bson.M{"date_orphaned": a.DateOrphaned}}); err != nil && err != mgo.ErrNotFound {
+		return false, mongoError(err)
+	}
+	return false, nil
+}
+
+func (r *mongoAssets) Discover(ctx context.Context, key string, size int64, now time.Time) error {
+	db, c := r.session(ctx)
+	defer db.Close()
+	_, err := c.Upsert(
+		bson.M{"key": key},
+		bson.M{
+			"$set":         bson.M{"size": size},
+			"$setOnInsert": bson.M{"refs": []bson.ObjectId{}, "date_created": now, "date_orphaned": now}})
+	return mongoError(err)
+}
+
+func (r *mongoAssets) Track(ctx context.Context, refID bson.ObjectId, keys []string, now time.Time) (err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+
+	// 더 이상 쓰지 않는 파일의 참조 해제
+	var released []*model.Asset
+	if err = c.
+		Find(bson.M{"refs": refID, "key": bson.M{"$nin": keys}}).
+		Select(bson.M{"_id": 1}).
+		All(&released); err != nil {
+		return mongoError(err)
+	}
+	releasedIDs := make([]bson.ObjectId, len(released))
+	for i, a := range released {
+		releasedIDs[i] = a.ID
+	}
+	if len(releasedIDs) > 0 {
+		if _, err = c.UpdateAll(
+			bson.M{"_id": bson.M{"$in": releasedIDs}},
+			bson.M{"$pull": bson.M{"refs": refID}}); err != nil {
+			return mongoError(err)
+		}
+	}
+
+	// 새로 쓰는 파일 참조: 기록되지 않은 파일이라도 지워지지 않도록 기록을 만든다
+	for _, key := range keys {
+		if _, err = c.Upsert(
+			bson.M{"key": key},
+			bson.M{
+				"$addToSet":    bson.M{"refs": refID},
+				"$unset":       bson.M{"date_orphaned": 1},
+				"$setOnInsert": bson.M{"date_created": now}}); err != nil {
+			return mongoError(err)
+		}
+	}
+
+	// 참조가 모두 사라진 파일은 지금부터 유예 기간을 센다
+	if len(releasedIDs) > 0 {
+		_, err = c.UpdateAll(
+			bson.M{
+				"_id":           bson.M{"$in": releasedIDs},
+				"refs":          bson.M{"$size": 0},
+				"date_orphaned": nil},
+			bson.M{"$set": bson.M{"date_orphaned": now}})
+	}
+	return mongoError(err)
+}
+
+func (r *mongoAssets) ListOrphaned(ctx context.Context, before time.Time) (assets []*model.Asset, err error) {
+	db, c := r.session(ctx)
+	defer db.Close()
+	if err = c.
+		Find(bson.M{
+		"refs":          bson.M{"$size": 0},
+		"date_orphaned": bson.M{"$lt": before}}).
+		Sort("date_orphaned").
+		All(&assets); err != nil {
+		return nil, mongoError(err)
+	}
+	return
+}
+
+func (r *mongoAssets) DeleteOrphaned(ctx context.Context, id bson.ObjectId, before time.Time) error {
+	db, c := r.session(ctx)
+	defer db.Close()
+	return mongoError(c.Remove(bson.M{
+		"_id":           id,
+		"refs":          bson.M{"$size": 0},
+		"date_orphaned": bson.M{"$lt": before}}))
+}
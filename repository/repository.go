@@ -0,0 +1,245 @@
+package repository
+
+import (
+	// Default package
+	"time"
+	"errors"
+	"context"
+	// Third Party package
+	"github.com/globalsign/mgo/bson"
+	// User package
+	"github.com/backend/model"
+)
+
+// 컬렉션 이름
+const (
+	UserCollection   = "users"
+	StoryCollection  = "story"
+	BoardCollection  = "board"
+	NoticeCollection = "notice"
+
+	AnthologyCollection    = "anthology"
+	BroadcastCollection    = "broadcast"
+	SubscriberCollection   = "subscriber"
+	NewsletterCollection   = "newsletter"
+	NotificationCollection = "notification"
+	OutboxCollection       = "outbox"
+	MediaCollection        = "media"
+	AssetCollection        = "asset"
+)
+
+// 알림 보관 기간: 지난 알림은 MongoDB TTL 인덱스가 지운다
+const NotificationRetention = 90 * 24 * time.Hour
+
+var (
+	ErrNotFound  = errors.New("repository: 찾을 수 없습니다")
+	ErrDuplicate = errors.New("repository: 이미 존재합니다")
+)
+
+// 회원 저장소: 조회 결과에는 패스워드를 담지 않는다
+type Users interface {
+	// 이메일과 닉네임이 모두 같은 회원이 있으면 ErrDuplicate
+	Create(ctx context.Context, u *model.User) error
+	Get(ctx context.Context, id bson.ObjectId) (*model.User, error)
+	GetByEmail(ctx context.Context, email string) (*model.User, error)
+	// 이메일과 해쉬한 패스워드가 모두 맞는 회원: 없으면 ErrNotFound
+	Authenticate(ctx context.Context, email string, passwordHash string) (*model.User, error)
+	// 관리자, 필진, 닉네임 순
+	List(ctx context.Context, q UserQuery) ([]*model.User, error)
+	// u.ID 회원의 fields(bson 필드 이름)만 u 의 값으로 바꾼다: 비어 있는 값은 지운다
+	Update(ctx context.Context, u *model.User, fields ...string) error
+	Delete(ctx context.Context, id bson.ObjectId) error
+}
+
+type UserQuery struct {
+	IDs     []bson.ObjectId // nil 이면 모든 회원
+	Active  bool            // 활성화된 회원만
+	Staff   bool            // 필진만
+	Admin   bool            // 관리자만
+	Unmuted string          // 이 종류의 알림을 끄지 않은 회원만
+}
+
+// 스토리와 자유게시판 글 저장소
+type Posts interface {
+	Create(ctx context.Context, p *model.Post) error
+	Get(ctx context.Context, id bson.ObjectId) (*model.Post, error)
+	List(ctx context.Context, q PostQuery) ([]*model.Post, error)
+	Count(ctx context.Context, q PostQuery) (int, error)
+	// 모든 글을 하나씩 읽는다: fn 이 에러를 돌려주면 멈춘다
+	Each(ctx context.Context, fn func(p *model.Post) error) error
+	// p.ID 글의 fields(bson 필드 이름)만 p 의 값으로 바꾼다: 비어 있는 값은 지운다
+	Update(ctx context.Context, p *model.Post, fields ...string) error
+	Delete(ctx context.Context, id bson.ObjectId) error
+}
+
+type PostQuery struct {
+	IDs         []bson.ObjectId // nil 이면 모든 글
+	AuthorID    bson.ObjectId   // 비어 있으면 모든 저자
+	Published   bool            // 발행 승인된 글만
+	Series      string
+	Title       string
+	DateCreated string
+	Summary     bool // 내용은 받아오지 않음으로써 응답시간 단축
+	Oldest      bool // 생성일자 순: 기본은 생성일자 역순
+	Skip        int
+	Limit       int // 0 이면 모두
+}
+
+// 공지사항 저장소: 고정 공지와 일반 공지를 나누어 조회한다
+// active 이면 게시 종료 일시가 없거나 아직 지나지 않은 공지만
+type Notices interface {
+	Posts
+	// 고정 공지: 페이지와 상관없이 고정 순서대로 모두, 내용은 빼고
+	ListPinned(ctx context.Context, active bool) ([]*model.Post, error)
+	// 일반 공지: 생성일자 역순, 내용은 빼고
+	ListUnpinned(ctx context.Context, active bool, skip int, limit int) ([]*model.Post, error)
+	CountUnpinned(ctx context.Context, active bool) (int, error)
+}
+
+// 알림 저장소: 회원별로 조회한다
+type Notifications interface {
+	Create(ctx context.Context, n *model.Notification) error
+	// 여러 회원의 알림을 한 번에 만든다
+	CreateMany(ctx context.Context, notifications []*model.Notification) error
+	// 생성일자 역순
+	List(ctx context.Context, userID bson.ObjectId, skip int, limit int) ([]*model.Notification, error)
+	CountUnread(ctx context.Context, userID bson.ObjectId) (int, error)
+	// userID 회원의 알림이 아니면 ErrNotFound
+	MarkRead(ctx context.Context, userID bson.ObjectId, id bson.ObjectId) error
+	MarkAllRead(ctx context.Context, userID bson.ObjectId) error
+}
+
+// 소식지 구독자 저장소: 이메일은 고유하다
+type Subscribers interface {
+	// sub.Email 구독자를 돌려준다: 없으면 sub 로 만든다
+	GetOrCreate(ctx context.Context, sub *model.Subscriber) (*model.Subscriber, error)
+	// 확인하지 않은 구독자의 확인 메일 발송 시각을 now 로 바꾼다
+	// 이미 확인했거나 cooldown 안에 보냈다면 ErrNotFound
+	MarkConfirmSent(ctx context.Context, id bson.ObjectId, now time.Time, cooldown time.Duration) error
+	// 확인하지 않은 구독자만: 없으면 ErrNotFound
+	Confirm(ctx context.Context, id bson.ObjectId, now time.Time) error
+	ListConfirmed(ctx context.Context) ([]*model.Subscriber, error)
+	Delete(ctx context.Context, id bson.ObjectId) error
+}
+
+// 소식지 발송 기록 저장소
+type Newsletters interface {
+	Create(ctx context.Context, issue *model.NewsletterIssue) error
+	// 생성일자 역순
+	List(ctx context.Context) ([]*model.NewsletterIssue, error)
+	// issue.ID 소식지의 fields(bson 필드 이름)만 issue 의 값으로 바꾼다: 비어 있는 값은 지운다
+	Update(ctx context.Context, issue *model.NewsletterIssue, fields ...string) error
+}
+
+// 공지사항 메일 발송 기록 저장소
+type Broadcasts interface {
+	Create(ctx context.Context, b *model.Broadcast) error
+	// 공지사항별 발송 기록: 생성일자 역순
+	List(ctx context.Context, noticeID bson.ObjectId) ([]*model.Broadcast, error)
+	// b.ID 발송 기록의 fields(bson 필드 이름)만 b 의 값으로 바꾼다: 비어 있는 값은 지운다
+	Update(ctx context.Context, b *model.Broadcast, fields ...string) error
+}
+
+// 문집 저장소
+type Anthologies interface {
+	Create(ctx context.Context, a *model.Anthology) error
+	Get(ctx context.Context, id bson.ObjectId) (*model.Anthology, error)
+	// 발행 연도, 생성일자 역순: 서문과 판권면은 빼고
+	List(ctx context.Context) ([]*model.Anthology, error)
+	// a.ID 문집의 fields(bson 필드 이름)만 a 의 값으로 바꾼다: 비어 있는 값은 지운다
+	Update(ctx context.Context, a *model.Anthology, fields ...string) error
+	Delete(ctx context.Context, id bson.ObjectId) error
+}
+
+// 미디어 라이브러리 저장소: 수정과 삭제는 올린 회원의 미디어로 한정한다
+type Media interface {
+	Create(ctx context.Context, m *model.Media) error
+	// 회원의 미디어: 생성일자 역순, limit 이 0 이면 모두
+	List(ctx context.Context, ownerID bson.ObjectId, skip int, limit int) ([]*model.Media, error)
+	// 모든 미디어를 하나씩 읽는다: fn 이 에러를 돌려주면 멈춘다
+	Each(ctx context.Context, fn func(m *model.Media) error) error
+	// m.OwnerID 회원의 m.ID 미디어만 fields 를 바꾸고 바뀐 미디어를 돌려준다: 없으면 ErrNotFound
+	UpdateOwned(ctx context.Context, m *model.Media, fields ...string) (*model.Media, error)
+	// ownerID 회원의 id 미디어를 지우고 지운 미디어를 돌려준다: 없으면 ErrNotFound
+	DeleteOwned(ctx context.Context, ownerID bson.ObjectId, id bson.ObjectId) (*model.Media, error)
+	Delete(ctx context.Context, id bson.ObjectId) error
+	// 회원이 사용 중인 용량
+	Usage(ctx context.Context, ownerID bson.ObjectId) (int64, error)
+	// 회원별 미디어 수와 용량: 용량 역순, 회원 정보와 용량 제한은 채우지 않는다
+	ListUsage(ctx context.Context) ([]*model.MediaUsage, error)
+}
+
+// 메일 발송 대기열 저장소
+type Outbox interface {
+	Create(ctx context.Context, m *model.OutboxMessage) error
+	// status 가 비어 있으면 실패한 메일(포기한 메일과 재시도를 기다리는 메일): 생성일자 역순
+	List(ctx context.Context, status string, skip int, limit int) ([]*model.OutboxMessage, error)
+	// 아직 보내지 못한 메일(대기, 포기)을 now 부터 처음부터 다시 시도: 없으면 ErrNotFound
+	Retry(ctx context.Context, id bson.ObjectId, now time.Time) error
+	// 대기 중이거나 발송 중인 메일 수
+	CountUnsent(ctx context.Context) (int, error)
+	// 보낼 차례가 된 메일(또는 lease 가 지난 발송 중 메일) 하나를 선점해 돌려준다: 없으면 ErrNotFound
+	// 선점한 메일은 발송 중 상태가 되고 시도 횟수가 하나 늘어난다
+	Claim(ctx context.Context, now time.Time, lease time.Duration) (*model.OutboxMessage, error)
+	// m.ID 메일의 fields(bson 필드 이름)만 m 의 값으로 바꾼다: 비어 있는 값은 지운다
+	Update(ctx context.Context, m *model.OutboxMessage, fields ...string) error
+}
+
+// 저장소 파일 기록: 파일 경로(key)는 고유하다
+type Assets interface {
+	// 새 파일을 참조 없는 파일로 기록한다: 새로 기록했으면 true
+	// 이미 기록된 파일이 참조가 없다면 유예 기간을 a.DateOrphaned 부터 다시 센다
+	Register(ctx context.Context, a *model.Asset) (bool, error)
+	// 기록되지 않은 파일이면 now 부터 참조 없는 파일로, 기록된 파일이면 크기만 바꾼다
+	Discover(ctx context.Context, key string, size int64, now time.Time) error
+	// refID 가 참조하는 파일을 keys 로 바꾼다: 기록되지 않은 파일도 기록하고, 참조가 모두 사라진 파일은 now 부터 유예 기간을 센다
+	Track(ctx context.Context, refID bson.ObjectId, keys []string, now time.Time) error
+	// before 이전에 참조가 모두 사라진 파일: 참조가 사라진 순
+	ListOrphaned(ctx context.Context, before time.Time) ([]*model.Asset, error)
+	// 아직 참조가 없고 before 이전에 참조가 사라진 파일 기록만 지운다: 아니면 ErrNotFound
+	DeleteOrphaned(ctx context.Context, id bson.ObjectId, before time.Time) error
+}
+
+// 저장소 연결 확인과 인덱스 준비: 준비 상태 확인과 서버 시작에 사용
+type Database interface {
+	Ping(ctx context.Context) error
+	EnsureIndexes(ctx context.Context) error
+}
+
+// 핸들러가 쓰는 저장소 묶음
+type Repositories struct {
+	Database      Database
+	Users         Users
+	Stories       Posts
+	Board         Posts
+	Notices       Notices
+	Notifications Notifications
+	Subscribers   Subscribers
+	Newsletters   Newsletters
+	Broadcasts    Broadcasts
+	Anthologies   Anthologies
+	Media         Media
+	Outbox        Outbox
+	Assets        Assets
+}
+
+func changes(doc interface{}, fields []string) (set bson.M, unset bson.M, err error) {
+	// doc 을 bson 으로 바꿔 fields 의 값을 꺼낸다: omitempty 로 빠진 필드는 지운다
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return
+	}
+	values := bson.M{}
+	if err = bson.Unmarshal(data, values); err != nil {
+		return
+	}
+	set, unset = bson.M{}, bson.M{}
+	for _, field := range fields {
+		if value, ok := values[field]; ok {
+			set[field] = value
+		} else {
+			unset[field] = 1
+		}
+	}
+	return
+}
@@ -2,43 +2,79 @@ package main
 
 import (
 	// Default package
+	"io"
 	"os"
-	"time"
+	"flag"
+	"context"
+	"syscall"
 	"net/http"
-	"io/ioutil"
-	"path/filepath"
-	"encoding/json"
+	"log/slog"
+	"os/signal"
 	// Third Party package
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
-	"github.com/labstack/gommon/log"
 	"github.com/globalsign/mgo"
 	// User package
+	"github.com/backend/auth"
+	"github.com/backend/config"
 	"github.com/backend/handler"
+	"github.com/backend/logging"
+	"github.com/backend/metrics"
+	"github.com/backend/openapi"
+	"github.com/backend/storage"
+	"github.com/backend/repository"
+	"github.com/backend/tracing"
+	"github.com/backend/utility"
 )
 
-// DBInfo 구조체 선언
-type DBInfo struct {
-	DBName   string `json:"db_name"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-}
-
 func main() {
+	// 설정 파일 경로: -config 옵션이나 STMORE_CONFIG 환경 변수
+	configPath := flag.String("config", os.Getenv(config.EnvPrefix+"CONFIG"), "설정 파일 경로 (기본값: ./config.json)")
+	flag.Parse()
+	if *configPath == "" {
+		*configPath = "./config.json"
+	}
+
 	// Echo instance
 	e := echo.New()
 
+	//-----------
+	// Config
+	//-----------
+
+	// 잘못된 설정이면 서버를 시작하지 않는다
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+	handler.DBName = cfg.Mongo.Database
+	handler.Key = cfg.JWT.Key
+	handler.SiteURL = cfg.HTTP.SiteURL
+	utility.TokenExpiry = cfg.JWT.Expiry.Duration
+
+	// JSON 로그: 요청 ID 와 회원 ID 를 붙이고 패스워드, 토큰 등은 가린다
+	level, _ := logging.ParseLevel(cfg.Log.Level)
+	slog.SetDefault(logging.New(os.Stdout, cfg.Log.Format, level))
+	e.HideBanner = true
+	e.HidePort = true
+
+	// OpenTelemetry 추적: 꺼져 있으면 스팬을 기록하지 않는다
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, cfg.Env)
+	if err != nil {
+		fatal("추적을 준비하지 못했습니다", err)
+	}
+
 	//-----------
 	// Middleware
 	//-----------
 
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
+	e.Use(logging.RequestIDMiddleware())
+	e.Use(tracing.Middleware())                       // 요청마다 스팬 기록
+	e.Use(metrics.Middleware())                       // 라우트별 요청 수와 처리 시간
+	e.Use(logging.RequestLogger(auth.UserID))         // 요청 로그와 패닉 복구
 	//CORS WhiteList
 	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
-		AllowOrigins: []string{
-			"https://www.somethingmore.co.kr", // master 에서는 변경할 것
-		},
+		AllowOrigins: cfg.CORS.AllowOrigins,
 		AllowHeaders: []string{
 			echo.HeaderOrigin,
 			echo.HeaderAccept,
@@ -54,73 +90,26 @@ func main() {
 	//	CookieSecure:   false, // master 에서는 변경할 것
 	//	CookieHTTPOnly: true, // master 에서는 변경할 것
 	//}))
-	// JWT
-	e.Use(middleware.JWTWithConfig(middleware.JWTConfig{
-		SigningKey: []byte(handler.Key), // "secret"
-		Skipper: func(c echo.Context) bool {
-			// 인증 메서드의 경우 authentication 을 건너뛴다
-			if c.Path() == "/" ||
-				c.Path() == "/assets/*" ||
-				c.Path() == "/admin/" ||
-				c.Path() == "/sign-up/" ||
-				c.Path() == "/sign-in/" ||
-				c.Path() == "/activate/:user_email" ||
-				c.Path() == "/reset/:user_email" ||
-				c.Path() == "/authors/" ||
-				c.Path() == "/authors/:author_id" ||
-				c.Path() == "/authors/count/:author_id" ||
-				c.Path() == "/story/client/" ||
-				c.Path() == "/story/view/:story_id" ||
-				c.Path() == "/board/list/" ||
-				c.Path() == "/board/count/" ||
-				c.Path() == "/board/view/:board_id" ||
-				c.Path() == "/notice/list/" ||
-				c.Path() == "/notice/count/" ||
-				c.Path() == "/notice/view/:notice_id" {
-				return true
-			}
-			return false
-		},
-	}))
 
 	//-----------
 	// Databases
 	//-----------
 
-	// Read Secreat JSON File
-	absPath, _ := filepath.Abs("./secrets/.secrets_db.json")
-	jsonFile, err := os.Open(absPath)
-	defer jsonFile.Close()
-	if err != nil {
-		e.Logger.Fatal(err)
-	}
-	byteValue, _ := ioutil.ReadAll(jsonFile)
-
-	// Bind to DBInfo struct
-	var data DBInfo
-	json.Unmarshal(byteValue, &data)
-
 	// Database connection
-	info := &mgo.DialInfo{
-		Addrs:    []string{"localhost"},
-		Timeout:  60 * time.Second,
-		Database: data.DBName,
-		Username: data.Username,
-		Password: data.Password,
+	info, err := cfg.Mongo.DialInfo()
+	if err != nil {
+		fatal("데이터베이스에 연결하지 못했습니다", err)
 	}
 
 	db, err := mgo.DialWithInfo(info)
 	if err != nil {
-		e.Logger.Fatal(err)
+		fatal("데이터베이스에 연결하지 못했습니다", err)
 	}
 
 	// Create indices
-	// 인덱스 값으로 email 을 사용하며, 그 값은 고유하다
-	if err = db.Copy().DB(handler.DBName).C(handler.USER).EnsureIndex(mgo.Index{
-		Key:    []string{"email", "nickname"},
-		Unique: true,
-	}); err != nil {
-		log.Fatal(err)
+	repositories := repository.NewMongo(db, handler.DBName)
+	if err = repositories.Database.EnsureIndexes(context.Background()); err != nil {
+		fatal("인덱스를 만들지 못했습니다", err)
 	}
 
 	//---------------
@@ -128,62 +117,101 @@ func main() {
 	//---------------
 
 	// Initialize handler
-	h := &handler.Handler{DB: db}
-
-	// Route: Static
-	e.Static("/assets", "assets") // 정적 파일
-
-	// Route: Index
-	e.GET("/", func(c echo.Context) error {
-		return c.String(http.StatusOK, "섬띵모어 API 서버\n")
+	mailer, err := utility.NewMailer(cfg.Mail)
+	if err != nil {
+		fatal("메일 발송을 준비하지 못했습니다", err)
+	}
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		fatal("파일 저장소를 준비하지 못했습니다", err)
+	}
+	h := &handler.Handler{
+		Repositories: repositories,
+		Events:       handler.NewBroker(),
+		Mailer:       mailer,
+		Storage:      store,
+	}
+	h.StartOutbox(handler.OutboxWorkers) // 메일 발송 워커
+	h.StartAssetSweeper()                // 참조가 사라진 파일 정리
+
+	// 라우트마다 인증 방식을 정해 등록한다: 인증한 회원의 현재 상태를 읽고, 로그에 회원 ID 를 붙인다
+	h.Principals = auth.NewCache(h.LoadPrincipal, auth.CacheTTL)
+	r := auth.New(e, []byte(handler.Key), h.Principals, logging.UserMiddleware(auth.UserID))
+
+	// Route
+	spec := openapi.Spec()
+	h.Routes(r, handler.RouteOptions{
+		Spec:         spec,
+		Metrics:      cfg.Metrics.Enabled && cfg.Metrics.Addr == "",
+		MetricsToken: cfg.Metrics.Token,
 	})
 
-	// Route: User
-	e.POST("/sign-up/", h.SignUpNormal)        // 회원 가입
-	e.POST("/admin/", h.SignUpAdmin)           // 관리자 회원 가입
-	e.GET("/activate/:user_email", h.Activate) // 이메일 회원 활성화
-	e.POST("/sign-in/", h.SignIn)              // 로그인
-	e.PATCH("/patch/", h.PatchPassword)        // 비밀번호 수정
-	e.PATCH("/nickname/", h.PatchNickname)     // 닉네임 수정
-	e.DELETE("/destroy/", h.DestroyUser)       // 회원 탈퇴
-	e.GET("/reset/:user_email", h.ResetPassword) // 비밀번호 초기화
-
-	// Route: Admin
-	e.GET("/users/", h.ListUsers)                      // 전체 유저 리스트
-	e.PATCH("/users/:user_email", h.UpdateUserAuth)    // 유저
-	e.DELETE("/users/:user_email", h.ForceDestroyUser) // 유저 강제 탈퇴
-
-	// Route: Author
-	e.GET("/authors/", h.ListAuthors)                      // 필진 리스트
-	e.GET("/authors/:author_id", h.ListStoryAuthor)        // 필진 스토리 리스트
-	e.GET("/authors/count/:author_id", h.CountStoryAuthor) // 필진 스토리 갯수
-
-	// Route: Story
-	e.POST("/story/", h.CreateStory)                          // 스토리 생성
-	e.GET("/story/", h.ListStory)                             // 스토리 리스트
-	e.GET("/story/client/", h.ClientListStory)                // 클라이언트 스토리 리스트
-	e.GET("/story/count/", h.CountStory)                      // 스토리 총 갯수
-	e.GET("/story/view/:story_id", h.RetrieveStory)           // 스토리 디테일
-	e.PATCH("/story/:story_id", h.PatchStory)                 // 스토리 수정
-	e.PATCH("/story/publish/:story_id", h.ChangePublishStory) // 스토리 발행 상태 변경
-	e.DELETE("/story/:story_id", h.DestroyStory)              // 스토리 삭제
-
-	// Route: Board
-	e.POST("/board/", h.CreateBoard)                // 자유게시판 글 생성
-	e.GET("/board/list/", h.ListBoard)              // 자유게시판 글 목록
-	e.GET("/board/count/", h.CountBoard)            // 자유게시판 글 갯수
-	e.GET("/board/view/:board_id", h.RetrieveBoard) // 자유게시판 글 보기
-	e.PATCH("/board/:board_id", h.PatchBoard)       // 자유게시판 글 수정
-	e.DELETE("/board/:board_id", h.DestroyBoard)    // 자유게시판 글 삭제
-
-	// Route: Notice
-	e.POST("/notice/", h.CreateNotice)                 // 공지사항 글 생성
-	e.GET("/notice/list/", h.ListNotice)               // 공지사항 글 목록
-	e.GET("/notice/count/", h.CountNotice)             // 공지사항 글 갯수
-	e.GET("/notice/view/:notice_id", h.RetrieveNotice) // 공지사항 글 보기
-	e.PATCH("/notice/:notice_id", h.PatchNotice)       // 공지사항 글 수정
-	e.DELETE("/notice/:notice_id", h.DestroyNotice)    // 공지사항 글 삭제
+	// 관리용 포트를 정했다면 지표는 따로 제공한다
+	var metricsServer *http.Server
+	if cfg.Metrics.Enabled && cfg.Metrics.Addr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", metrics.Handler())
+		metricsServer = &http.Server{Addr: cfg.Metrics.Addr, Handler: mux}
+	}
+
+	// 인증 방식이나 문서가 없는 라우트가 있으면 시작하지 않는다
+	if err := r.Verify(e.Routes()); err != nil {
+		fatal("라우트의 인증 방식을 확인하지 못했습니다", err)
+	}
+	spec.Secure(r.Routes())
+	if err := openapi.Verify(spec, e.Routes()); err != nil {
+		fatal("API 문서가 라우트와 맞지 않습니다", err)
+	}
+	r.Log()
 
 	// Start server
-	e.Logger.Fatal(e.Start(":1323"))
+	slog.Info("서버를 시작합니다", "addr", cfg.HTTP.Addr, "env", cfg.Env)
+	go func() {
+		if err := e.Start(cfg.HTTP.Addr); err != nil && err != http.ErrServerClosed {
+			fatal("서버를 시작하지 못했습니다", err)
+		}
+	}()
+	if metricsServer != nil {
+		slog.Info("지표 서버를 시작합니다", "addr", cfg.Metrics.Addr)
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fatal("지표 서버를 시작하지 못했습니다", err)
+			}
+		}()
+	}
+
+	// 종료 신호를 받으면 진행 중인 요청과 백그라운드 작업을 기다린 뒤 종료한다
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
+	<-quit
+	slog.Info("서버를 종료합니다")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout.Duration)
+	defer cancel()
+
+	// 실시간 이벤트 연결은 끝나지 않으므로 HTTP 서버보다 먼저 끊는다
+	h.Stop()
+	if err := e.Shutdown(ctx); err != nil {
+		slog.Error("요청을 모두 마치지 못했습니다", "error", err)
+	}
+	if err := h.Wait(ctx); err != nil {
+		slog.Error("백그라운드 작업을 모두 마치지 못했습니다", "error", err)
+	}
+	// 종료하는 동안의 지표도 수집할 수 있도록 지표 서버는 마지막에 닫는다
+	if metricsServer != nil {
+		metricsServer.Shutdown(ctx)
+	}
+	// 아직 보내지 못한 스팬을 수집기로 보낸다
+	if err := shutdownTracing(ctx); err != nil {
+		slog.Error("추적 데이터를 모두 보내지 못했습니다", "error", err)
+	}
+	if closer, ok := mailer.(io.Closer); ok {
+		closer.Close()
+	}
+	db.Close()
+}
+
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
 }
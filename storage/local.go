@@ -0,0 +1,179 @@
+package storage
+
+import (
+	// Default package
+	"io"
+	"os"
+	"time"
+	"path"
+	"errors"
+	"context"
+	"strconv"
+	"strings"
+	"net/url"
+	"crypto/hmac"
+	"crypto/sha256"
+	"path/filepath"
+	"encoding/base64"
+)
+
+type Local struct {
+	Dir        string   // 공개 파일 경로: 정적 파일로 서비스된다
+	PrivateDir string   // 비공개 파일 경로: 정적 파일 경로 바깥에 둔다
+	PublicURL  string   // 공개 파일 주소의 앞부분
+	PrivateURL string   // 비공개 파일을 내려주는 API 주소의 앞부분
+	SigningKey []byte   // 서명 키
+	LegacyURLs []string // 예전 공개 파일 주소의 앞부분
+}
+
+func NewLocal(cfg Config) *Local {
+	l := &Local{
+		Dir:        cfg.Dir,
+		PrivateDir: cfg.PrivateDir,
+		PublicURL:  strings.TrimSuffix(cfg.PublicURL, "/"),
+		PrivateURL: strings.TrimSuffix(cfg.PrivateURL, "/"),
+		SigningKey: []byte(cfg.SigningKey),
+		LegacyURLs: trimURLs(cfg.LegacyURLs),
+	}
+	// 기본값
+	if l.Dir == "" {
+		l.Dir = "./assets"
+	}
+	if l.PrivateDir == "" {
+		l.PrivateDir = "./private"
+	}
+	if l.PublicURL == "" {
+		l.PublicURL = "http://localhost:1323/assets"
+	}
+	if l.PrivateURL == "" {
+		l.PrivateURL = "http://localhost:1323/private"
+	}
+	return l
+}
+
+func (l *Local) Path(key string) (string, error) {
+	// 키를 실제 파일 경로로 바꾼다: 저장소 바깥을 가리키는 키는 거부
+	clean := path.Clean("/" + key)[1:]
+	if clean == "" || clean != key {
+		return "", errors.New("storage: 올바르지 않은 키입니다")
+	}
+	if strings.HasPrefix(key, PrivatePrefix) {
+		return filepath.Join(l.PrivateDir, filepath.FromSlash(strings.TrimPrefix(key, PrivatePrefix))), nil
+	}
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) (err error) {
+	p, err := l.Path(key)
+	if err != nil {
+		return
+	}
+	if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return
+	}
+
+	// 다 쓴 뒤에 이름을 바꿔서 읽는 쪽이 쓰다 만 파일을 보지 않도록 한다
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+	if _, err = io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return
+	}
+	if err = tmp.Close(); err != nil {
+		return
+	}
+	if err = os.Chmod(tmp.Name(), 0644); err != nil {
+		return
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+func (l *Local) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := l.Path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (l *Local) Delete(ctx context.Context, key string) error {
+	p, err := l.Path(key)
+	if err != nil {
+		return err
+	}
+	if err = os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) Ping(ctx context.Context) error {
+	// 공개, 비공개 경로 모두 쓸 수 있어야 한다
+	for _, dir := range []string{l.Dir, l.PrivateDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Local) Walk(ctx context.Context, fn func(key string, size int64) error) error {
+	return filepath.Walk(l.Dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		// 업로드 중인 임시 파일은 건너뛴다
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(l.Dir, p)
+		if err != nil {
+			return err
+		}
+		return fn(filepath.ToSlash(rel), info.Size())
+	})
+}
+
+func (l *Local) URL(key string) string {
+	return l.PublicURL + "/" + key
+}
+
+func (l *Local) Key(rawURL string) (string, bool) {
+	return trimKey(rawURL, append([]string{l.PublicURL}, l.LegacyURLs...)...)
+}
+
+func (l *Local) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	if !strings.HasPrefix(key, PrivatePrefix) {
+		return l.URL(key), nil
+	}
+	expires := strconv.FormatInt(time.Now().Add(expiry).Unix(), 10)
+	query := url.Values{}
+	query.Set("expires", expires)
+	query.Set("signature", l.sign(key, expires))
+	return l.PrivateURL + "/" + strings.TrimPrefix(key, PrivatePrefix) + "?" + query.Encode(), nil
+}
+
+func (l *Local) Verify(key string, expires string, signature string) bool {
+	// 서명된 주소 확인: 기한이 지났거나 서명이 다르면 false
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(l.sign(key, expires)))
+}
+
+func (l *Local) sign(key string, expires string) string {
+	mac := hmac.New(sha256.New, l.SigningKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,111 @@
+package storage
+
+import (
+	// Default package
+	"io"
+	"fmt"
+	"time"
+	"context"
+	"strings"
+	// Third Party package
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3 호환 저장소: AWS S3, MinIO 등
+// 비공개 파일을 보호하려면 버킷 정책에서 private/ 접두어의 공개 읽기를 막아야 한다
+type S3 struct {
+	Client     *minio.Client
+	Bucket     string
+	PublicURL  string   // 공개 파일 주소의 앞부분: CDN 을 쓰는 경우 CDN 주소
+	LegacyURLs []string // 예전 공개 파일 주소의 앞부분
+}
+
+func NewS3(cfg Config) (*S3, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 기본값: path-style 버킷 주소
+	publicURL := strings.TrimSuffix(cfg.PublicURL, "/")
+	if publicURL == "" {
+		publicURL = client.EndpointURL().String() + "/" + cfg.Bucket
+	}
+	return &S3{Client: client, Bucket: cfg.Bucket, PublicURL: publicURL, LegacyURLs: trimURLs(cfg.LegacyURLs)}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	opts := minio.PutObjectOptions{ContentType: contentType}
+	if !strings.HasPrefix(key, PrivatePrefix) {
+		opts.CacheControl = CacheControl
+	}
+	_, err := s.Client.PutObject(ctx, s.Bucket, key, r, size, opts)
+	return err
+}
+
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	obj, err := s.Client.GetObject(ctx, s.Bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	// GetObject 는 실제로 읽기 전까지 오류를 알려주지 않는다
+	if _, err = obj.Stat(); err != nil {
+		obj.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return obj, nil
+}
+
+func (s *S3) Delete(ctx context.Context, key string) error {
+	return s.Client.RemoveObject(ctx, s.Bucket, key, minio.RemoveObjectOptions{})
+}
+
+func (s *S3) Ping(ctx context.Context) error {
+	ok, err := s.Client.BucketExists(ctx, s.Bucket)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("버킷이 없습니다: %s", s.Bucket)
+	}
+	return nil
+}
+
+func (s *S3) Walk(ctx context.Context, fn func(key string, size int64) error) error {
+	for obj := range s.Client.ListObjects(ctx, s.Bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if obj.Err != nil {
+			return obj.Err
+		}
+		if strings.HasPrefix(obj.Key, PrivatePrefix) {
+			continue
+		}
+		if err := fn(obj.Key, obj.Size); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *S3) URL(key string) string {
+	return s.PublicURL + "/" + key
+}
+
+func (s *S3) Key(rawURL string) (string, bool) {
+	return trimKey(rawURL, append([]string{s.PublicURL}, s.LegacyURLs...)...)
+}
+
+func (s *S3) SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error) {
+	u, err := s.Client.PresignedGetObject(ctx, s.Bucket, key, expiry, nil)
+	if err != nil {
+		return "", err
+	}
+	return u.String(), nil
+}
@@ -0,0 +1,87 @@
+package storage
+
+import (
+	// Default package
+	"io"
+	"fmt"
+	"time"
+	"errors"
+	"context"
+	"strings"
+)
+
+// 이 접두어로 시작하는 키는 공개 주소로 읽을 수 없고 서명된 주소로만 내려받을 수 있다
+const PrivatePrefix = "private/"
+
+// 공개 파일은 내용의 해쉬값으로 이름을 지어 바뀌지 않으므로 오래 캐시한다
+const CacheControl = "public, max-age=31536000, immutable"
+
+var ErrNotFound = errors.New("storage: 파일이 없습니다")
+
+// 업로드한 파일을 저장하는 곳: 로컬 디스크 또는 S3 호환 저장소
+type Storage interface {
+	// size 를 모르면 -1
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	// 공개 파일의 주소
+	URL(key string) string
+	// URL 로 만든 주소에서 키를 되찾는다: 이 저장소의 주소가 아니면 false
+	Key(url string) (string, bool)
+	// 비공개 파일을 expiry 동안 내려받을 수 있는 주소
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+	// 공개 파일을 모두 순회한다
+	Walk(ctx context.Context, fn func(key string, size int64) error) error
+	// 저장소에 접근할 수 있는지 확인: 헬스 체크에서 사용
+	Ping(ctx context.Context) error
+}
+
+type Config struct {
+	Backend    string   `json:"backend"`     // local(기본값) 또는 s3
+	PublicURL  string   `json:"public_url"`  // 공개 파일 주소의 앞부분: 프록시 뒤에서도 바깥에서 보이는 주소를 적는다
+	Dir        string   `json:"dir"`         // local: 공개 파일 경로
+	PrivateDir string   `json:"private_dir"` // local: 비공개 파일 경로
+	PrivateURL string   `json:"private_url"` // local: 서명된 주소로 비공개 파일을 내려주는 API 주소
+	SigningKey string   `json:"signing_key"` // local: 서명 키
+	LegacyURLs []string `json:"legacy_urls"` // 예전 공개 파일 주소의 앞부분: 주소를 바꾸기 전에 저장한 글의 파일도 키로 되찾는다
+	Endpoint   string   `json:"endpoint"`    // s3: 엔드포인트 (예: s3.ap-northeast-2.amazonaws.com, localhost:9000)
+	Region     string   `json:"region"`      // s3: 리전
+	Bucket     string   `json:"bucket"`      // s3: 버킷
+	AccessKey  string   `json:"access_key"`  // s3: 액세스 키
+	SecretKey  string   `json:"secret_key"`  // s3: 시크릿 키
+	UseSSL     bool     `json:"use_ssl"`     // s3: HTTPS 사용 여부
+}
+
+func New(cfg Config) (Storage, error) {
+	switch cfg.Backend {
+	case "", "local":
+		return NewLocal(cfg), nil
+	case "s3":
+		return NewS3(cfg)
+	}
+	return nil, fmt.Errorf("알 수 없는 저장소입니다: %s", cfg.Backend)
+}
+
+func trimKey(rawURL string, bases ...string) (string, bool) {
+	// 처음 맞는 base 뒤의 경로를 키로 사용: 쿼리는 버린다
+	for _, base := range bases {
+		prefix := strings.TrimSuffix(base, "/") + "/"
+		if base == "" || !strings.HasPrefix(rawURL, prefix) {
+			continue
+		}
+		key := strings.TrimPrefix(rawURL, prefix)
+		if i := strings.IndexAny(key, "?#"); i >= 0 {
+			key = key[:i]
+		}
+		return key, key != ""
+	}
+	return "", false
+}
+
+func trimURLs(urls []string) []string {
+	trimmed := make([]string, 0, len(urls))
+	for _, u := range urls {
+		trimmed = append(trimmed, strings.TrimSuffix(u, "/"))
+	}
+	return trimmed
+}
@@ -0,0 +1,129 @@
+package tracing
+
+import (
+	// Default package
+	"sync"
+	"context"
+	"net/http"
+	// Third Party package
+	"github.com/labstack/echo"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+)
+
+// 스팬을 만드는 계측 라이브러리 이름
+const InstrumentationName = "github.com/backend"
+
+type Config struct {
+	Enabled     bool    `json:"enabled"`      // OTLP 로 스팬을 보낼지 여부 (기본값: false)
+	Endpoint    string  `json:"endpoint"`     // OTLP/HTTP 수집기 주소 (예: http://localhost:4318/v1/traces)
+	SampleRatio float64 `json:"sample_ratio"` // 새로 시작하는 요청 중 기록할 비율: 0 ~ 1, 상위 서비스가 정한 결정은 그대로 따른다
+	ServiceName string  `json:"service_name"` // 수집기에 표시할 서비스 이름
+}
+
+func Setup(ctx context.Context, cfg Config, env string) (shutdown func(ctx context.Context) error, err error) {
+	// 꺼져 있으면 아무것도 기록하지 않는 기본 TracerProvider 를 그대로 쓴다
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if !cfg.Enabled {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+		semconv.DeploymentEnvironmentNameKey.String(env)))
+	if err != nil {
+		return
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))))
+	otel.SetTracerProvider(provider)
+
+	// 종료할 때 남은 스팬을 보낸다
+	return provider.Shutdown, nil
+}
+
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+func End(span trace.Span, err error) {
+	// err 가 있으면 스팬을 실패로 표시하고 끝낸다
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func Middleware() echo.MiddlewareFunc {
+	// 요청마다 서버 스팬을 만든다: traceparent 헤더가 있으면 그 추적에 이어서 기록한다
+	var (
+		once   sync.Once
+		routes map[string]bool
+	)
+	propagator := otel.GetTextMapPropagator()
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			once.Do(func() {
+				routes = map[string]bool{}
+				for _, r := range c.Echo().Routes() {
+					routes[r.Path] = true
+				}
+			})
+
+			req := c.Request()
+			ctx := propagator.Extract(req.Context(), propagation.HeaderCarrier(req.Header))
+
+			// 라우트가 없는 요청은 요청 주소 대신 메소드만 이름으로 쓴다
+			// 요청 경로에는 토큰(/unsubscribe/:token 등)이 들어 있을 수 있으므로 라우트만 기록한다
+			name := req.Method
+			attrs := []attribute.KeyValue{
+				semconv.HTTPRequestMethodKey.String(req.Method),
+				semconv.ClientAddress(c.RealIP()),
+				semconv.UserAgentOriginal(req.UserAgent()),
+			}
+			if route := c.Path(); routes[route] {
+				name += " " + route
+				attrs = append(attrs, semconv.HTTPRoute(route))
+			}
+
+			ctx, span := Tracer().Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+			defer span.End()
+			c.SetRequest(req.WithContext(ctx))
+
+			err = next(c)
+
+			// 에러를 처리하기 전이라면 상태 코드를 에러에서 구한다
+			status := c.Response().Status
+			if err != nil {
+				status = http.StatusInternalServerError
+				if he, ok := err.(*echo.HTTPError); ok {
+					status = he.Code
+				}
+			}
+			span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			return
+		}
+	}
+}
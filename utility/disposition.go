@@ -0,0 +1,51 @@
+package utility
+
+import (
+	// Default package
+	"strings"
+)
+
+// 내려받을 파일 이름으로 Content-Disposition 헤더 값을 만든다
+// 한글 파일명은 RFC 5987 형식(filename*)으로, 이를 모르는 클라이언트를 위해 ASCII 파일명(filename)도 함께 넣는다
+func AttachmentDisposition(fileName string) string {
+	return `attachment; filename="` + asciiFileName(fileName) + `"; filename*=UTF-8''` + encodeRFC5987(fileName)
+}
+
+func encodeRFC5987(s string) string {
+	// attr-char 가 아닌 바이트는 모두 퍼센트 인코딩한다
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isAttrChar(c) {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+func isAttrChar(c byte) bool {
+	// RFC 5987: ALPHA / DIGIT / "!" / "#" / "$" / "&" / "+" / "-" / "." / "^" / "_" / "`" / "|" / "~"
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+	return strings.IndexByte("!#$&+-.^_`|~", c) >= 0
+}
+
+func asciiFileName(s string) string {
+	// 따옴표 안에 넣을 수 있는 ASCII 문자만 남기고, 나머지는 _ 로 바꾼다
+	var b strings.Builder
+	for _, r := range s {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			b.WriteByte('_')
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,19 @@
+package utility
+
+import (
+	// Default package
+	"testing"
+)
+
+func TestAttachmentDisposition(t *testing.T) {
+	cases := map[string]string{
+		"단편집.epub":       `attachment; filename="___.epub"; filename*=UTF-8''%EB%8B%A8%ED%8E%B8%EC%A7%91.epub`,
+		"a;b,c (1)=*.pdf": `attachment; filename="a;b,c (1)=*.pdf"; filename*=UTF-8''a%3Bb%2Cc%20%281%29%3D%2A.pdf`,
+		`say "hi".pdf`:    `attachment; filename="say _hi_.pdf"; filename*=UTF-8''say%20%22hi%22.pdf`,
+	}
+	for name, want := range cases {
+		if got := AttachmentDisposition(name); got != want {
+			t.Errorf("AttachmentDisposition(%q) = %s, want %s", name, got, want)
+		}
+	}
+}
@@ -2,14 +2,12 @@ package utility
 
 import (
 	// Default package
-	"os"
 	"fmt"
 	"bytes"
-	"net/smtp"
-	"io/ioutil"
-	"encoding/json"
-	"path/filepath"
+	"embed"
+	"strings"
 	"html/template"
+	texttemplate "text/template"
 	// Third Party package
 	"github.com/labstack/echo"
 	// User package
@@ -17,142 +15,219 @@ import (
 )
 
 type Account struct {
-	Email    string // 발신자
-	Password string // 패스워드
-	Host     string // SMTP 서버
+	Email    string `json:"email"`    // 발신자
+	Password string `json:"password"` // 패스워드
+	Host     string `json:"host"`     // SMTP 서버
+	Port     int    `json:"port"`     // SMTP 포트: 비어 있으면 보안 방식에 맞는 기본 포트
+	Security string `json:"security"` // 연결 보안 방식: starttls, tls, none
+	Backend  string `json:"backend"`  // 발송 방식: smtp(기본값), file, memory
+	Dir      string `json:"dir"`      // file 방식일 때 메일을 저장할 경로
 }
 
 type Request struct {
-	From    string   // 발신자
-	To      []string // 수신자
-	Subject string   // 제목
-	Body    string   // 내용
+	From        string   // 발신자: 비어 있으면 Mailer 의 발신자
+	To          []string // 수신자
+	Subject     string   // 제목
+	Body        string   // HTML 내용
+	Text        string   // 텍스트 내용: HTML 을 보지 못하는 메일 프로그램용
+	Unsubscribe string   // 원클릭 수신 거부 주소(RFC 8058): 이 주소로 POST 하면 수신 거부된다
 }
 
-type TemplateData struct {
-	Title    string // 이메일 주소
-	Nickname string // 유저 닉네임
-	URL      string // Activate 주소
-	Password string // 초기화된 패스워드
-}
+// 메일 템플릿: 바이너리에 포함되어 서버 실행 경로와 관계없이 읽을 수 있다
+//go:embed templates
+var templateFS embed.FS
 
-func ReadSecretJson() Account {
-	// Read Secret JSON File
-	absPath, _ := filepath.Abs("./secrets/.secrets_email.json")
-	jsonFile, err := os.Open(absPath)
-	if err != nil {
-		fmt.Println(err)
-	}
-	defer jsonFile.Close()
+const DefaultLocale = "ko"
 
-	// Account 구조체 변수 선언
-	var account Account
+// 메일 템플릿을 지원하는 언어
+var MailLocales = []string{"ko", "en"}
 
-	// JSON 파일을 읽어 byte 값을 account 변수의 주소에 넣는다
-	byteValue, _ := ioutil.ReadAll(jsonFile)
-	json.Unmarshal(byteValue, &account)
+// 메일 종류마다 <이름>.html 과 제목(subject)을 정의한 <이름>.txt 가 있다
+var mailTemplateNames = []string{
+	"activate_account",
+	"reset_password",
+	"notice_broadcast",
+	"newsletter_confirm",
+	"newsletter_digest",
+}
+
+var mailTemplates = loadMailTemplates()
 
-	return account
+type mailTemplate struct {
+	html *template.Template
+	text *texttemplate.Template
 }
 
-func (r *Request) ParseTemplate(name string, data interface{}) (err error) {
-	t, err := template.ParseFiles(name)
-	if err != nil {
-		return err
+type TemplateData struct {
+	Title          string        // 이메일 주소
+	Nickname       string        // 유저 닉네임
+	URL            string        // Activate 주소
+	Password       string        // 초기화된 패스워드
+	Content        template.HTML // 공지사항 본문
+	UnsubscribeURL string        // 수신 거부 주소
+	Stories        []*model.Post // 소식지에 실을 스토리
+}
+
+func (r *Request) Render(locale string, name string, d *TemplateData) (err error) {
+	// 제목, HTML 본문, 텍스트 본문을 템플릿으로 만든다
+	t, ok := mailTemplates[MailLocale(locale)+"/"+name]
+	if !ok {
+		return fmt.Errorf("메일 템플릿이 없습니다: %s", name)
 	}
+
 	buf := new(bytes.Buffer)
-	if err = t.Execute(buf, data); err != nil {
-		return err
+	if err = t.text.ExecuteTemplate(buf, "subject", d); err != nil {
+		return
+	}
+	r.Subject = strings.TrimSpace(buf.String())
+
+	// 따로 정하지 않았다면 HTML 문서 제목은 메일 제목과 같다
+	if d.Title == "" {
+		d.Title = r.Subject
+	}
+
+	buf.Reset()
+	if err = t.html.Execute(buf, d); err != nil {
+		return
 	}
 	r.Body = buf.String()
+
+	buf.Reset()
+	if err = t.text.Execute(buf, d); err != nil {
+		return
+	}
+	r.Text = buf.String()
 	return
 }
 
-func (r *Request) SendEmail(host string, auth smtp.Auth) (bool, error) {
-	// MIME Type 정의
-	MIME := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\r\n"
-	// To 헤더
-	ToHeader := "To: " + r.To[0] + "\r\n"
-	// From 헤더
-	FromHeader := "From: " + r.From + "\r\n"
-	// 제목
-	SubjectHeader := "Subject: " + r.Subject + "\r\n"
-	// 전체 메시지
-	msg := []byte(ToHeader + FromHeader + SubjectHeader + MIME + "\r\n" + r.Body)
-	// 발신자 SMTP 서버
-	addr := host + ":587"
-
-	// 이메일 전송
-	if err := smtp.SendMail(addr, auth, r.From, r.To, msg); err != nil {
-		return false, err
-	}
-	return true, nil
+func MailLocale(locale string) string {
+	// 지원하지 않는 언어는 한국어로 보낸다
+	for _, l := range MailLocales {
+		if l == locale {
+			return l
+		}
+	}
+	return DefaultLocale
 }
 
-func SendActivationEmail(c echo.Context, u *model.User) (err error) {
-	// Secret json 읽기
-	s := ReadSecretJson()
+func loadMailTemplates() map[string]*mailTemplate {
+	// 서버 시작 시 한 번만 읽는다: 템플릿에 문제가 있으면 서버가 뜨지 않는다
+	templates := make(map[string]*mailTemplate)
+	for _, locale := range MailLocales {
+		for _, name := range mailTemplateNames {
+			base := "templates/" + locale + "/" + name
+			templates[locale+"/"+name] = &mailTemplate{
+				html: template.Must(template.ParseFS(templateFS, base+".html")),
+				text: texttemplate.Must(texttemplate.New(name + ".txt").
+					Funcs(texttemplate.FuncMap{"plain": plainText}).
+					ParseFS(templateFS, base+".txt")),
+			}
+		}
+	}
+	return templates
+}
 
-	// 발신자의 SMTP 서버 Authentication
-	auth := smtp.PlainAuth("", s.Email, s.Password, s.Host)
+func plainText(content template.HTML) string {
+	// 텍스트 본문에 넣을 HTML: 문단 사이에 빈 줄을 둔다
+	return strings.Join(HTMLToText(string(content)), "\n\n")
+}
 
-	// Request 객체 생성
-	r := &Request{
-		From:    s.Email,            // 발신자 주소: 썸띵모어 관리자
-		To:      []string{u.Email},  // 수신자 주소: 가입자
-		Subject: "썸띵모어 회원 가입 인증 메일", // 메일 제목
+func NewActivationEmail(c echo.Context, u *model.User) (r *Request, err error) {
+	// Request 객체 생성: 발송은 outbox 가 맡는다
+	r = &Request{
+		To: []string{u.Email}, // 수신자 주소: 가입자
 	}
 
 	// TemplateData 객체 생성
 	d := &TemplateData{
-		Title:    r.Subject,
 		Nickname: u.Nickname,
 		URL:      c.Scheme() + "://" + c.Request().Host + "/activate/" + u.Email,
 	}
 
-	// Template File 경로 생성
-	templatePath, _ := filepath.Abs("./templates/activate_account.html")
+	err = r.Render(u.Locale, "activate_account", d)
+	return
+}
 
-	if err = r.ParseTemplate(templatePath, d); err != nil {
-		return err
+func NewResetPasswordEmail(u *model.User, siteURL string) (r *Request, err error) {
+	// Request 객체 생성: 발송은 outbox 가 맡는다
+	r = &Request{
+		To: []string{u.Email}, // 수신자 주소: 가입자
 	}
-	if _, err := r.SendEmail(s.Host, auth); err != nil {
-		return err
+
+	// TemplateData 객체 생성
+	d := &TemplateData{
+		Nickname: u.Nickname,
+		URL:      siteURL,
+		Password: u.Password,
 	}
 
+	err = r.Render(u.Locale, "reset_password", d)
 	return
 }
 
-func SendResetPasswordEmail(u *model.User) (err error) {
-	// Secret json 읽기
-	s := ReadSecretJson()
+func NewNoticeEmail(u *model.User, n *model.Post, noticeURL string, unsubscribeURL string) (r *Request, err error) {
+	// Request 객체 생성
+	r = &Request{
+		To:          []string{u.Email}, // 수신자 주소: 회원
+		Unsubscribe: unsubscribeURL,
+	}
 
-	// 발신자의 SMTP 서버 Authentication
-	auth := smtp.PlainAuth("", s.Email, s.Password, s.Host)
+	// 공지사항 본문은 허용된 태그만 남긴 뒤 HTML 그대로 넣는다
+	content, err := SanitizeXHTML(n.Content)
+	if err != nil {
+		return
+	}
 
-	// Request 객체 생성
-	r := &Request{
-		From:    s.Email,            // 발신자 주소: 썸띵모어 관리자
-		To:      []string{u.Email},  // 수신자 주소: 가입자
-		Subject: "썸띵모어 패스워드 초기화 안내 메일", // 메일 제목
+	// TemplateData 객체 생성
+	d := &TemplateData{
+		Title:          n.Title,
+		Nickname:       u.Nickname,
+		URL:            noticeURL,
+		Content:        template.HTML(content),
+		UnsubscribeURL: unsubscribeURL,
+	}
+
+	err = r.Render(u.Locale, "notice_broadcast", d)
+	return
+}
+
+func NewNewsletterConfirmEmail(email string, locale string, confirmURL string) (r *Request, err error) {
+	// Request 객체 생성: 발송은 outbox 가 맡는다
+	r = &Request{
+		To: []string{email}, // 수신자 주소: 구독 신청자
 	}
 
 	// TemplateData 객체 생성
 	d := &TemplateData{
-		Title:    r.Subject,
-		Nickname: u.Nickname,
-		Password: u.Password,
+		URL: confirmURL,
 	}
 
-	// Template File 경로 생성
-	templatePath, _ := filepath.Abs("../src/github.com/backend/templates/reset_password.html")
+	err = r.Render(locale, "newsletter_confirm", d)
+	return
+}
 
-	if err = r.ParseTemplate(templatePath, d); err != nil {
-		return err
+func NewNewsletterDigest(email string, locale string, subject string, intro string, stories []*model.Post, siteURL string, unsubscribeURL string) (r *Request, err error) {
+	// Request 객체 생성: 제목은 관리자가 입력한 소식지 제목
+	r = &Request{
+		To:          []string{email}, // 수신자 주소: 구독자
+		Unsubscribe: unsubscribeURL,
 	}
-	if _, err := r.SendEmail(s.Host, auth); err != nil {
-		return err
+
+	// 소개글은 허용된 태그만 남긴 뒤 HTML 그대로 넣는다
+	content, err := SanitizeXHTML(intro)
+	if err != nil {
+		return
+	}
+
+	// TemplateData 객체 생성
+	d := &TemplateData{
+		Title:          subject,
+		URL:            siteURL,
+		Content:        template.HTML(content),
+		UnsubscribeURL: unsubscribeURL,
+		Stories:        stories,
 	}
 
+	err = r.Render(locale, "newsletter_digest", d)
 	return
-}
\ No newline at end of file
+}
@@ -0,0 +1,305 @@
+package utility
+
+import (
+	// Default package
+	"io"
+	"fmt"
+	"time"
+	"bytes"
+	"strings"
+	"archive/zip"
+	"encoding/xml"
+	"text/template"
+)
+
+type EpubBook struct {
+	Identifier string        // 고유 식별자 (urn)
+	Title      string        // 책 제목
+	Creator    string        // 저자 닉네임
+	Language   string        // 언어 코드, 기본값 ko
+	Modified   time.Time     // 최종 수정 시각
+	Cover      []byte        // 표지 이미지
+	CoverType  string        // 표지 이미지의 MIME 타입
+	Chapters   []EpubChapter // 목차 순서대로 정렬된 챕터
+}
+
+type EpubChapter struct {
+	Title    string        // 챕터 제목
+	Author   string        // 저자 닉네임: 여러 저자의 글을 묶을 때 사용
+	Body     string        // SanitizeXHTML 을 거친 본문
+	Children []EpubChapter // 하위 챕터: 섹션처럼 묶을 때 사용
+}
+
+// 템플릿에서 사용하는 평탄화된 챕터 정보
+type epubItem struct {
+	ID       string
+	Href     string
+	Title    string
+	Author   string
+	Body     string
+	Remote   bool // 본문에 외부 이미지가 있는지 여부
+	Children []*epubItem
+}
+
+var epubFuncs = template.FuncMap{
+	"xml": escapeXML,
+	"inc": func(i int) int { return i + 1 }, // ncx 의 playOrder 는 1부터 시작한다
+}
+
+var containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+var epubStyle = `body { font-family: serif; line-height: 1.8; margin: 0 5%; }
+h1 { font-size: 1.4em; margin: 2em 0 1.5em; text-align: center; }
+p { text-indent: 1em; margin: 0 0 0.6em; }
+.author { text-align: right; text-indent: 0; margin-bottom: 2em; }
+.cover { text-align: center; }
+.cover img { max-width: 100%; max-height: 100%; }
+.section { margin-top: 40%; text-align: center; }
+`
+
+var packageTemplate = template.Must(template.New("content.opf").Funcs(epubFuncs).Parse(
+	`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="{{xml .Language}}">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">{{xml .Identifier}}</dc:identifier>
+    <dc:title>{{xml .Title}}</dc:title>
+    <dc:creator>{{xml .Creator}}</dc:creator>
+    <dc:language>{{xml .Language}}</dc:language>
+    <meta property="dcterms:modified">{{.Modified}}</meta>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="ncx" href="toc.ncx" media-type="application/x-dtbncx+xml"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+{{- if .CoverType}}
+    <item id="cover-image" href="{{.CoverHref}}" media-type="{{.CoverType}}" properties="cover-image"/>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+{{- end}}
+{{- range .Items}}
+    <item id="{{.ID}}" href="{{.Href}}" media-type="application/xhtml+xml"{{if .Remote}} properties="remote-resources"{{end}}/>
+{{- end}}
+  </manifest>
+  <spine toc="ncx">
+{{- if .CoverType}}
+    <itemref idref="cover" linear="no"/>
+{{- end}}
+    <itemref idref="nav"/>
+{{- range .Items}}
+    <itemref idref="{{.ID}}"/>
+{{- end}}
+  </spine>
+</package>
+`))
+
+var navTemplate = template.Must(template.New("nav.xhtml").Funcs(epubFuncs).Parse(
+	`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" xml:lang="{{xml .Language}}" lang="{{xml .Language}}">
+<head>
+  <title>{{xml .Title}}</title>
+  <link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+  <nav epub:type="toc" id="toc">
+    <h1>차례</h1>
+    {{template "list" .Tree}}
+  </nav>
+</body>
+</html>
+{{define "list"}}<ol>
+{{- range .}}
+      <li><a href="{{.Href}}">{{xml .Title}}</a>{{if .Children}}{{template "list" .Children}}{{end}}</li>
+{{- end}}
+    </ol>{{end}}
+`))
+
+var ncxTemplate = template.Must(template.New("toc.ncx").Funcs(epubFuncs).Parse(
+	`<?xml version="1.0" encoding="UTF-8"?>
+<ncx xmlns="http://www.daisy.org/z3986/2005/ncx/" version="2005-1">
+  <head>
+    <meta name="dtb:uid" content="{{xml .Identifier}}"/>
+  </head>
+  <docTitle><text>{{xml .Title}}</text></docTitle>
+  <navMap>
+{{- range $i, $item := .Items}}
+    <navPoint id="nav-{{$item.ID}}" playOrder="{{inc $i}}">
+      <navLabel><text>{{xml $item.Title}}</text></navLabel>
+      <content src="{{$item.Href}}"/>
+    </navPoint>
+{{- end}}
+  </navMap>
+</ncx>
+`))
+
+var chapterTemplate = template.Must(template.New("chapter.xhtml").Funcs(epubFuncs).Parse(
+	`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="{{xml .Language}}" lang="{{xml .Language}}">
+<head>
+  <title>{{xml .Item.Title}}</title>
+  <link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+{{- if .Item.Body}}
+  <h1>{{xml .Item.Title}}</h1>
+{{- if .Item.Author}}
+  <p class="author">{{xml .Item.Author}}</p>
+{{- end}}
+  {{.Item.Body}}
+{{- else}}
+  <h1 class="section">{{xml .Item.Title}}</h1>
+{{- end}}
+</body>
+</html>
+`))
+
+var coverTemplate = template.Must(template.New("cover.xhtml").Funcs(epubFuncs).Parse(
+	`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xml:lang="{{xml .Language}}" lang="{{xml .Language}}">
+<head>
+  <title>{{xml .Title}}</title>
+  <link rel="stylesheet" type="text/css" href="style.css"/>
+</head>
+<body>
+  <div class="cover"><img src="{{.CoverHref}}" alt="{{xml .Title}}"/></div>
+</body>
+</html>
+`))
+
+func (b *EpubBook) Write(w io.Writer) (err error) {
+	// 언어와 수정 시각의 기본값 지정
+	if b.Language == "" {
+		b.Language = "ko"
+	}
+	if b.Modified.IsZero() {
+		b.Modified = time.Now()
+	}
+
+	// 챕터 트리를 파일 단위로 평탄화
+	var items []*epubItem
+	tree := flattenChapters(b.Chapters, &items)
+
+	// 표지 이미지 확장자 결정
+	coverHref := ""
+	if len(b.Cover) > 0 {
+		switch b.CoverType {
+		case "image/png":
+			coverHref = "cover.png"
+		case "image/gif":
+			coverHref = "cover.gif"
+		default:
+			b.CoverType = "image/jpeg"
+			coverHref = "cover.jpg"
+		}
+	} else {
+		b.CoverType = ""
+	}
+
+	data := map[string]interface{}{
+		"Identifier": b.Identifier,
+		"Title":      b.Title,
+		"Creator":    b.Creator,
+		"Language":   b.Language,
+		"Modified":   b.Modified.UTC().Format("2006-01-02T15:04:05Z"),
+		"CoverType":  b.CoverType,
+		"CoverHref":  coverHref,
+		"Items":      items,
+		"Tree":       tree,
+	}
+
+	z := zip.NewWriter(w)
+
+	// mimetype 은 압축하지 않고 반드시 첫 번째 파일로 넣는다
+	mimetype, err := z.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	if err != nil {
+		return
+	}
+	if _, err = io.WriteString(mimetype, "application/epub+zip"); err != nil {
+		return
+	}
+
+	if err = writeZipFile(z, "META-INF/container.xml", []byte(containerXML)); err != nil {
+		return
+	}
+	if err = writeZipFile(z, "OEBPS/style.css", []byte(epubStyle)); err != nil {
+		return
+	}
+	if err = writeZipTemplate(z, "OEBPS/content.opf", packageTemplate, data); err != nil {
+		return
+	}
+	if err = writeZipTemplate(z, "OEBPS/nav.xhtml", navTemplate, data); err != nil {
+		return
+	}
+	if err = writeZipTemplate(z, "OEBPS/toc.ncx", ncxTemplate, data); err != nil {
+		return
+	}
+
+	// 표지
+	if coverHref != "" {
+		if err = writeZipFile(z, "OEBPS/"+coverHref, b.Cover); err != nil {
+			return
+		}
+		if err = writeZipTemplate(z, "OEBPS/cover.xhtml", coverTemplate, data); err != nil {
+			return
+		}
+	}
+
+	// 본문
+	for _, item := range items {
+		chapter := map[string]interface{}{"Language": b.Language, "Item": item}
+		if err = writeZipTemplate(z, "OEBPS/"+item.Href, chapterTemplate, chapter); err != nil {
+			return
+		}
+	}
+
+	return z.Close()
+}
+
+func flattenChapters(chapters []EpubChapter, items *[]*epubItem) (tree []*epubItem) {
+	// 챕터 트리를 순회하며 파일 이름을 부여하고, 목차용 트리를 함께 만든다
+	for _, chapter := range chapters {
+		n := len(*items) + 1
+		item := &epubItem{
+			ID:    fmt.Sprintf("chapter-%03d", n),
+			Href:  fmt.Sprintf("chapter_%03d.xhtml", n),
+			Title:  chapter.Title,
+			Author: chapter.Author,
+			Body:   chapter.Body,
+			Remote: strings.Contains(chapter.Body, "<img "),
+		}
+		*items = append(*items, item)
+		item.Children = flattenChapters(chapter.Children, items)
+		tree = append(tree, item)
+	}
+	return
+}
+
+func writeZipFile(z *zip.Writer, name string, content []byte) (err error) {
+	f, err := z.Create(name)
+	if err != nil {
+		return
+	}
+	_, err = f.Write(content)
+	return
+}
+
+func writeZipTemplate(z *zip.Writer, name string, t *template.Template, data interface{}) (err error) {
+	buf := new(bytes.Buffer)
+	if err = t.Execute(buf, data); err != nil {
+		return
+	}
+	return writeZipFile(z, name, buf.Bytes())
+}
+
+func escapeXML(s string) string {
+	buf := new(bytes.Buffer)
+	xml.EscapeText(buf, []byte(strings.TrimSpace(s)))
+	return buf.String()
+}
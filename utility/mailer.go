@@ -0,0 +1,339 @@
+package utility
+
+import (
+	// Default package
+	"os"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+	"bytes"
+	"errors"
+	"context"
+	"strconv"
+	"net/smtp"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"io/ioutil"
+	"crypto/tls"
+	"path/filepath"
+	// Third Party package
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	// User package
+	"github.com/backend/tracing"
+)
+
+const (
+	MailTimeout     = time.Minute      // 메일 한 통을 보내는 데 허용하는 시간
+	MailIdleTimeout = 30 * time.Second // 이 시간 동안 쓰지 않은 SMTP 연결은 새로 맺는다
+)
+
+// SMTP 연결 보안 방식
+const (
+	SecurityStartTLS = "starttls" // 평문으로 연결한 뒤 STARTTLS: 보통 587 포트
+	SecurityTLS      = "tls"      // 처음부터 TLS 로 연결: 보통 465 포트
+	SecurityNone     = "none"     // 암호화하지 않음: 로컬 테스트용 SMTP 서버
+)
+
+// 메일 발송 방식: 운영 서버는 SMTP, 로컬 개발은 파일, 핸들러 테스트는 메모리
+type Mailer interface {
+	Send(ctx context.Context, r *Request) error
+	// 메일을 보낼 수 있는 상태인지 확인: 헬스 체크에서 사용
+	Ping(ctx context.Context) error
+}
+
+func NewMailer(a Account) (Mailer, error) {
+	switch a.Backend {
+	case "", "smtp":
+		return &SMTPMailer{
+			Host:     a.Host,
+			Port:     a.Port,
+			Username: a.Email,
+			Password: a.Password,
+			From:     a.Email,
+			Security: a.Security,
+		}, nil
+	case "file":
+		return &FileMailer{Dir: a.Dir, From: a.Email}, nil
+	case "memory":
+		return &MemoryMailer{From: a.Email}, nil
+	}
+	return nil, fmt.Errorf("알 수 없는 메일 발송 방식입니다: %s", a.Backend)
+}
+
+func (r *Request) Message() []byte {
+	// 헤더와 본문을 합친 전체 메시지: 텍스트와 HTML 을 함께 보낸다
+	buf := new(bytes.Buffer)
+	parts := multipart.NewWriter(buf)
+
+	fmt.Fprintf(buf, "From: %s\r\n", r.From)
+	fmt.Fprintf(buf, "To: %s\r\n", r.To[0])
+	fmt.Fprintf(buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", r.Subject)) // 한글 제목은 RFC 2047 로 인코딩
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	if r.Unsubscribe != "" {
+		// 메일 프로그램의 수신 거부 버튼: 사람이 누르지 않은 GET 요청으로는 수신 거부되지 않도록 POST 로 보내게 한다
+		fmt.Fprintf(buf, "List-Unsubscribe: <%s>\r\n", r.Unsubscribe)
+		buf.WriteString("List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n")
+	}
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buf, "Content-Type: multipart/alternative; boundary=%q\r\n", parts.Boundary())
+	buf.WriteString("\r\n")
+
+	// 메일 프로그램은 마지막 파트를 우선 보여준다
+	writePart(parts, "text/plain", r.Text)
+	writePart(parts, "text/html", r.Body)
+	parts.Close()
+	return buf.Bytes()
+}
+
+func writePart(parts *multipart.Writer, contentType string, body string) {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentType+"; charset=\"UTF-8\"")
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+	w, _ := parts.CreatePart(header)
+
+	qp := quotedprintable.NewWriter(w)
+	qp.Write([]byte(body))
+	qp.Close()
+}
+
+type SMTPMailer struct {
+	Host     string // SMTP 서버
+	Port     int    // 기본값: 587, SecurityTLS 이면 465
+	Username string // 인증 계정: 비어 있으면 인증하지 않는다
+	Password string // 패스워드
+	From     string // 발신자
+	Security string // 연결 보안 방식: 기본값은 STARTTLS
+
+	mu       sync.Mutex
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, r *Request) (err error) {
+	if r.From == "" {
+		r.From = m.From
+	}
+	msg := r.Message()
+
+	// 받는 사람 주소는 스팬에 남기지 않는다
+	ctx, span := tracing.Start(ctx, "smtp send",
+		semconv.ServerAddress(m.Host),
+		attribute.Int("smtp.recipients", len(r.To)))
+	defer func() { tracing.End(span, err) }()
+
+	// 연결을 재사용하므로 한 번에 한 통씩 보낸다
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	reused := m.client != nil
+	if err = m.send(ctx, r, msg); err != nil && reused {
+		// 서버가 끊은 연결을 재사용했을 수 있으므로 새 연결로 한 번 더 시도한다
+		span.AddEvent("smtp retry", trace.WithAttributes(attribute.String("error", err.Error())))
+		err = m.send(ctx, r, msg)
+	}
+	return
+}
+
+func (m *SMTPMailer) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.client == nil {
+		return nil
+	}
+	err := m.client.Quit()
+	m.client, m.conn = nil, nil
+	return err
+}
+
+func (m *SMTPMailer) Ping(ctx context.Context) (err error) {
+	// 열려 있는 연결이 있으면 NOOP, 없으면 새로 연결해 둔다: 다음 발송 때 재사용
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client != nil {
+		if err = m.client.Noop(); err == nil {
+			return
+		}
+		m.drop()
+	}
+	return m.dial(ctx)
+}
+
+func (m *SMTPMailer) send(ctx context.Context, r *Request, msg []byte) (err error) {
+	// 오래 쉬었거나 상태가 이상한 연결은 버린다
+	if m.client != nil && (time.Since(m.lastUsed) > MailIdleTimeout || m.client.Reset() != nil) {
+		m.drop()
+	}
+	if m.client == nil {
+		if err = m.dial(ctx); err != nil {
+			return
+		}
+	}
+
+	// 메일 한 통에 허용하는 시간
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(MailTimeout)
+	}
+	m.conn.SetDeadline(deadline)
+
+	defer func() {
+		if err != nil {
+			m.drop()
+			return
+		}
+		m.lastUsed = time.Now()
+	}()
+
+	if err = m.client.Mail(r.From); err != nil {
+		return
+	}
+	for _, to := range r.To {
+		if err = m.client.Rcpt(to); err != nil {
+			return
+		}
+	}
+	w, err := m.client.Data()
+	if err != nil {
+		return
+	}
+	if _, err = w.Write(msg); err != nil {
+		return
+	}
+	return w.Close()
+}
+
+func (m *SMTPMailer) dial(ctx context.Context) (err error) {
+	security := m.Security
+	if security == "" {
+		security = SecurityStartTLS
+	}
+	port := m.Port
+	if port == 0 {
+		port = 587
+		if security == SecurityTLS {
+			port = 465
+		}
+	}
+	addr := net.JoinHostPort(m.Host, strconv.Itoa(port))
+	tlsConfig := &tls.Config{ServerName: m.Host}
+
+	// 연결
+	dialer := &net.Dialer{Timeout: MailTimeout}
+	var conn net.Conn
+	if security == SecurityTLS {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return
+	}
+	conn.SetDeadline(time.Now().Add(MailTimeout))
+
+	client, err := smtp.NewClient(conn, m.Host)
+	if err != nil {
+		conn.Close()
+		return
+	}
+
+	// STARTTLS
+	if security == SecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return errors.New("SMTP 서버가 STARTTLS 를 지원하지 않습니다")
+		}
+		if err = client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return
+		}
+	}
+
+	// 발신자의 SMTP 서버 Authentication
+	if m.Username != "" {
+		if err = client.Auth(smtp.PlainAuth("", m.Username, m.Password, m.Host)); err != nil {
+			client.Close()
+			return
+		}
+	}
+
+	m.conn, m.client = conn, client
+	return
+}
+
+func (m *SMTPMailer) drop() {
+	if m.client != nil {
+		m.client.Close()
+	}
+	m.client, m.conn = nil, nil
+}
+
+type FileMailer struct {
+	Dir  string // .eml 파일을 저장할 경로: 기본값은 ./mail
+	From string // 발신자
+}
+
+func (m *FileMailer) Send(ctx context.Context, r *Request) (err error) {
+	if r.From == "" {
+		r.From = m.From
+	}
+	dir := m.Dir
+	if dir == "" {
+		dir = "./mail"
+	}
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+
+	// 메일 프로그램으로 바로 열어볼 수 있도록 .eml 파일로 저장
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102_150405.000000000"), r.To[0])
+	return ioutil.WriteFile(filepath.Join(dir, filepath.Base(name)), r.Message(), 0644)
+}
+
+func (m *FileMailer) Ping(ctx context.Context) error {
+	dir := m.Dir
+	if dir == "" {
+		dir = "./mail"
+	}
+	return os.MkdirAll(dir, 0755)
+}
+
+type MemoryMailer struct {
+	From string // 발신자
+
+	mu   sync.Mutex
+	sent []*Request
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, r *Request) error {
+	if r.From == "" {
+		r.From = m.From
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, r)
+	return nil
+}
+
+func (m *MemoryMailer) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (m *MemoryMailer) Sent() []*Request {
+	// 지금까지 보낸 메일의 복사본
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Request(nil), m.sent...)
+}
+
+func (m *MemoryMailer) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = nil
+}
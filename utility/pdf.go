@@ -0,0 +1,152 @@
+package utility
+
+import (
+	// Default package
+	"io"
+	"strconv"
+	"path/filepath"
+	// Third Party package
+	"github.com/jung-kurt/gofpdf"
+)
+
+// 인쇄용 PDF 에 임베드할 한글 폰트 경로
+const FontPath = "./fonts/NanumMyeongjo.ttf"
+
+type PdfBook struct {
+	Title    string       // 책 제목
+	Subtitle string       // 부제: 표지 하단에 출력
+	FontPath string       // 임베드할 TTF 폰트 경로
+	Chapters []PdfChapter // 목차 순서대로 정렬된 챕터
+}
+
+type PdfChapter struct {
+	Title      string   // 챕터 제목
+	Author     string   // 저자 닉네임
+	Paragraphs []string // 문단 단위 본문
+	Divider    bool     // 섹션 구분 페이지 여부
+}
+
+const (
+	fontFamily = "nanum"
+	lineHeight = 7.0
+	tocIndent  = 6.0  // 섹션 아래 챕터의 들여쓰기
+	tocPageNo  = 12.0 // 목차의 쪽수 칸 너비
+)
+
+func (b *PdfBook) Write(w io.Writer) (err error) {
+	// 목차에 쪽수를 넣으려면 본문이 몇 쪽에서 시작하는지 알아야 한다
+	// 목차의 길이는 쪽수와 상관없이 같으므로, 한 번 그려서 쪽수를 구한 뒤 다시 그린다
+	_, pages, err := b.render(make([]int, len(b.Chapters)))
+	if err != nil {
+		return
+	}
+	pdf, _, err := b.render(pages)
+	if err != nil {
+		return
+	}
+	return pdf.Output(w)
+}
+
+func (b *PdfBook) render(pages []int) (pdf *gofpdf.Fpdf, starts []int, err error) {
+	pdf = gofpdf.New("P", "mm", "A5", filepath.Dir(b.FontPath))
+	pdf.SetTitle(b.Title, true)
+	pdf.SetCreator("Something More", true)
+	pdf.AddUTF8Font(fontFamily, "", filepath.Base(b.FontPath))
+	pdf.SetMargins(18, 20, 18)
+	pdf.SetAutoPageBreak(true, 20)
+
+	// 표지를 제외한 모든 쪽 하단에 쪽수 출력
+	pdf.SetFooterFunc(func() {
+		if pdf.PageNo() == 1 {
+			return
+		}
+		pdf.SetY(-15)
+		pdf.SetFont(fontFamily, "", 9)
+		pdf.CellFormat(0, 10, strconv.Itoa(pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+
+	// 표지
+	pdf.AddPage()
+	pdf.SetY(80)
+	pdf.SetFont(fontFamily, "", 24)
+	pdf.MultiCell(0, 12, b.Title, "", "C", false)
+	if b.Subtitle != "" {
+		pdf.Ln(6)
+		pdf.SetFont(fontFamily, "", 12)
+		pdf.MultiCell(0, lineHeight, b.Subtitle, "", "C", false)
+	}
+
+	// 목차
+	pdf.AddPage()
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+	pdf.SetFont(fontFamily, "", 16)
+	pdf.CellFormat(0, 16, "차례", "", 1, "C", false, 0, "")
+	pdf.Ln(4)
+	indent := false
+	for i, chapter := range b.Chapters {
+		title := chapter.Title
+		if chapter.Author != "" {
+			title += " · " + chapter.Author
+		}
+		if chapter.Divider {
+			indent = true
+			pdf.Ln(2)
+			pdf.SetFont(fontFamily, "", 12)
+			pdf.CellFormat(0, lineHeight+1, title, "", 1, "L", false, 0, "")
+			continue
+		}
+		// 제목이 쪽수 칸을 넘지 않도록 한 줄로 줄인다: 목차의 길이가 쪽수와 상관없이 같아야 한다
+		pdf.SetFont(fontFamily, "", 10)
+		width := pageWidth - left - right - tocPageNo
+		if indent {
+			pdf.CellFormat(tocIndent, lineHeight, "", "", 0, "L", false, 0, "")
+			width -= tocIndent
+		}
+		pdf.CellFormat(width, lineHeight, fitText(pdf, title, width), "", 0, "L", false, 0, "")
+		pdf.CellFormat(tocPageNo, lineHeight, strconv.Itoa(pages[i]), "", 1, "R", false, 0, "")
+	}
+
+	// 본문
+	for _, chapter := range b.Chapters {
+		pdf.AddPage()
+		starts = append(starts, pdf.PageNo())
+
+		// 섹션 구분 페이지는 제목만 가운데 출력
+		if chapter.Divider {
+			pdf.SetY(80)
+			pdf.SetFont(fontFamily, "", 18)
+			pdf.MultiCell(0, 10, chapter.Title, "", "C", false)
+			continue
+		}
+
+		pdf.SetFont(fontFamily, "", 15)
+		pdf.MultiCell(0, 9, chapter.Title, "", "C", false)
+		if chapter.Author != "" {
+			pdf.SetFont(fontFamily, "", 10)
+			pdf.MultiCell(0, lineHeight, chapter.Author, "", "R", false)
+		}
+		pdf.Ln(6)
+
+		pdf.SetFont(fontFamily, "", 10)
+		for _, paragraph := range chapter.Paragraphs {
+			pdf.MultiCell(0, lineHeight, paragraph, "", "J", false)
+			pdf.Ln(2)
+		}
+	}
+
+	return pdf, starts, pdf.Error()
+}
+
+func fitText(pdf *gofpdf.Fpdf, text string, width float64) string {
+	// 너비를 넘는 글은 첫 줄만 남기고 말줄임표를 붙인다
+	lines := pdf.SplitText(text, width)
+	if len(lines) <= 1 {
+		return text
+	}
+	line := []rune(lines[0])
+	for len(line) > 0 && pdf.GetStringWidth(string(line)+"…") > width-2*pdf.GetCellMargin() {
+		line = line[:len(line)-1]
+	}
+	return string(line) + "…"
+}
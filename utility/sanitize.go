@@ -0,0 +1,273 @@
+package utility
+
+import (
+	// Default package
+	"bytes"
+	"strings"
+	"encoding/xml"
+	// Third Party package
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// 허용하는 태그 목록: 목록에 없는 태그는 제거하고 내부 텍스트만 남긴다
+var allowedTags = map[atom.Atom]bool{
+	atom.P:          true,
+	atom.Br:         true,
+	atom.Hr:         true,
+	atom.H1:         true,
+	atom.H2:         true,
+	atom.H3:         true,
+	atom.H4:         true,
+	atom.H5:         true,
+	atom.H6:         true,
+	atom.Strong:     true,
+	atom.B:          true,
+	atom.Em:         true,
+	atom.I:          true,
+	atom.U:          true,
+	atom.S:          true,
+	atom.Blockquote: true,
+	atom.Ul:         true,
+	atom.Ol:         true,
+	atom.Li:         true,
+	atom.Pre:        true,
+	atom.Code:       true,
+	atom.Span:       true,
+	atom.Div:        true,
+	atom.A:          true,
+	atom.Img:        true,
+	atom.Figure:     true,
+	atom.Figcaption: true,
+}
+
+// 내용까지 통째로 버리는 태그 목록
+var droppedTags = map[atom.Atom]bool{
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Embed:    true,
+	atom.Form:     true,
+	atom.Noscript: true,
+	atom.Template: true,
+}
+
+// 닫는 태그 없이 스스로 닫아야 하는 태그 목록
+var voidTags = map[atom.Atom]bool{
+	atom.Br:  true,
+	atom.Hr:  true,
+	atom.Img: true,
+}
+
+func SanitizeXHTML(content string) (string, error) {
+	// 스토리 본문을 EPUB 에 넣을 수 있는 XHTML 조각으로 변환
+	// 허용 목록에 없는 태그와 속성은 모두 제거한다
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(content), body)
+	if err != nil {
+		return "", err
+	}
+
+	// 태그가 하나도 없는 일반 텍스트라면 줄 단위로 문단을 만든다
+	if !hasElement(nodes) {
+		return plainTextToXHTML(content), nil
+	}
+
+	buf := new(bytes.Buffer)
+	for _, n := range nodes {
+		writeXHTML(buf, n)
+	}
+	return buf.String(), nil
+}
+
+func HTMLToText(content string) []string {
+	// 본문 HTML 에서 태그를 걷어내고 문단 단위의 텍스트 슬라이스를 만든다
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(content), body)
+	if err != nil || !hasElement(nodes) {
+		return splitParagraphs(content)
+	}
+
+	var paragraphs []string
+	buf := new(bytes.Buffer)
+	flush := func() {
+		if text := strings.TrimSpace(buf.String()); text != "" {
+			paragraphs = append(paragraphs, text)
+		}
+		buf.Reset()
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			buf.WriteString(n.Data)
+		case html.ElementNode:
+			if droppedTags[n.DataAtom] {
+				return
+			}
+			if n.DataAtom == atom.Br {
+				flush()
+				return
+			}
+			for child := n.FirstChild; child != nil; child = child.NextSibling {
+				walk(child)
+			}
+			if isBlock(n.DataAtom) {
+				flush()
+			}
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	flush()
+
+	return paragraphs
+}
+
+func ImageSources(content string) (sources []string) {
+	// 본문에 들어 있는 이미지 주소 목록
+	body := &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := html.ParseFragment(strings.NewReader(content), body)
+	if err != nil {
+		return
+	}
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Img {
+			for _, attr := range n.Attr {
+				if attr.Key == "src" {
+					sources = append(sources, attr.Val)
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return
+}
+
+func hasElement(nodes []*html.Node) bool {
+	for _, n := range nodes {
+		if n.Type == html.ElementNode {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlock(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.Li, atom.Blockquote, atom.Pre,
+		atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		return true
+	}
+	return false
+}
+
+func splitParagraphs(text string) (paragraphs []string) {
+	for _, line := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			paragraphs = append(paragraphs, line)
+		}
+	}
+	return
+}
+
+func plainTextToXHTML(text string) string {
+	buf := new(bytes.Buffer)
+	for _, paragraph := range splitParagraphs(text) {
+		buf.WriteString("<p>")
+		xml.EscapeText(buf, []byte(paragraph))
+		buf.WriteString("</p>\n")
+	}
+	return buf.String()
+}
+
+func writeXHTML(buf *bytes.Buffer, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		xml.EscapeText(buf, []byte(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		// 주석, doctype 등은 버린다
+		return
+	}
+
+	if droppedTags[n.DataAtom] {
+		return
+	}
+
+	// 허용되지 않은 태그는 껍데기만 벗기고 자식 노드를 그대로 출력
+	if !allowedTags[n.DataAtom] {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			writeXHTML(buf, child)
+		}
+		return
+	}
+
+	// 주소가 안전하지 않은 이미지는 통째로 버린다
+	if n.DataAtom == atom.Img && !isSafeImage(n) {
+		return
+	}
+
+	tag := n.DataAtom.String()
+	buf.WriteString("<" + tag)
+	for _, attr := range n.Attr {
+		if allowedAttr(n.DataAtom, attr) {
+			buf.WriteString(" " + attr.Key + `="`)
+			xml.EscapeText(buf, []byte(attr.Val))
+			buf.WriteString(`"`)
+		}
+	}
+
+	if voidTags[n.DataAtom] {
+		buf.WriteString("/>")
+		return
+	}
+	buf.WriteString(">")
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		writeXHTML(buf, child)
+	}
+	buf.WriteString("</" + tag + ">")
+}
+
+func allowedAttr(a atom.Atom, attr html.Attribute) bool {
+	// 태그별로 남겨 두는 속성
+	switch a {
+	case atom.A:
+		return attr.Key == "href" && isSafeURL(attr.Val)
+	case atom.Img:
+		switch attr.Key {
+		case "src":
+			return isSafeURL(attr.Val)
+		case "alt", "width", "height":
+			return true
+		}
+	}
+	return false
+}
+
+func isSafeImage(n *html.Node) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == "src" {
+			u := strings.ToLower(strings.TrimSpace(attr.Val))
+			return strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://")
+		}
+	}
+	return false
+}
+
+func isSafeURL(u string) bool {
+	u = strings.ToLower(strings.TrimSpace(u))
+	return strings.HasPrefix(u, "http://") ||
+		strings.HasPrefix(u, "https://") ||
+		strings.HasPrefix(u, "mailto:")
+}
@@ -0,0 +1,268 @@
+package utility
+
+import (
+	// Default package
+	"io"
+	"bytes"
+	"errors"
+	"image"
+	_ "image/gif" // GIF 는 첫 번째 프레임만 사용한다
+	"image/png"
+	"image/jpeg"
+	"net/http"
+	"io/ioutil"
+	"encoding/binary"
+	// Third Party package
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp" // WebP 썸네일 읽기
+)
+
+const (
+	MaxThumbnailSize   = 10 << 20 // 업로드할 수 있는 썸네일 최대 크기: 10MB
+	MaxThumbnailPixels = 40000000 // 디코딩할 수 있는 최대 픽셀 수: 압축 폭탄 방지
+	ThumbnailQuality   = 85       // JPEG, WebP 품질
+)
+
+var (
+	ErrUnsupportedImage = errors.New("JPEG, PNG, GIF, WebP 이미지만 올릴 수 있습니다")
+	ErrImageTooLarge    = errors.New("이미지가 너무 큽니다")
+)
+
+// 썸네일 크기: Height 가 0 이면 비율을 유지하고, 아니면 가운데를 잘라낸다
+type ThumbnailSpec struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+var ThumbnailSpecs = []ThumbnailSpec{
+	{Name: "card", Width: 480},              // 목록 카드
+	{Name: "hero", Width: 1600},             // 상세 페이지 상단
+	{Name: "og", Width: 1200, Height: 630}, // Open Graph 공유 이미지
+}
+
+// 본문 이미지 크기
+var MediaSpecs = []ThumbnailSpec{
+	{Name: "content", Width: 1600}, // 본문
+	{Name: "card", Width: 480},     // 미디어 라이브러리 목록
+}
+
+// 만들어진 썸네일 파일 하나
+type ThumbnailImage struct {
+	Name        string // ThumbnailSpec 의 이름
+	Extension   string // .jpg, .png, .webp
+	ContentType string
+	Data        []byte
+	Width       int
+	Height      int
+}
+
+func ProcessThumbnail(r io.Reader) ([]ThumbnailImage, error) {
+	// 업로드한 썸네일을 확인하고 크기별 썸네일을 만든다
+	return ProcessImage(r, ThumbnailSpecs)
+}
+
+func ProcessImage(r io.Reader, specs []ThumbnailSpec) (images []ThumbnailImage, err error) {
+	// 업로드한 이미지를 확인하고 specs 의 크기별로 다시 인코딩한다
+	// 다시 인코딩하므로 EXIF(GPS 등) 메타데이터는 남지 않는다
+	data, err := ioutil.ReadAll(io.LimitReader(r, MaxThumbnailSize+1))
+	if err != nil {
+		return
+	}
+	if len(data) > MaxThumbnailSize {
+		return nil, ErrImageTooLarge
+	}
+
+	// 확장자가 아니라 실제 내용으로 형식을 확인
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png", "image/gif", "image/webp":
+	default:
+		return nil, ErrUnsupportedImage
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+	if config.Width*config.Height > MaxThumbnailPixels {
+		return nil, ErrImageTooLarge
+	}
+
+	src, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedImage
+	}
+
+	// 휴대폰 사진은 EXIF 의 방향 정보대로 회전시켜야 올바르게 보인다
+	if format == "jpeg" {
+		src = orient(src, jpegOrientation(data))
+	}
+
+	// 투명한 부분이 있는 이미지는 PNG, 나머지는 JPEG
+	transparent := format != "jpeg" && !isOpaque(src)
+
+	for _, spec := range specs {
+		resized := resize(src, spec)
+		bounds := resized.Bounds()
+
+		buf := new(bytes.Buffer)
+		img := ThumbnailImage{Name: spec.Name, Width: bounds.Dx(), Height: bounds.Dy()}
+		if transparent {
+			err = png.Encode(buf, resized)
+			img.Extension, img.ContentType = ".png", "image/png"
+		} else {
+			err = jpeg.Encode(buf, resized, &jpeg.Options{Quality: ThumbnailQuality})
+			img.Extension, img.ContentType = ".jpg", "image/jpeg"
+		}
+		if err != nil {
+			return
+		}
+		img.Data = buf.Bytes()
+		images = append(images, img)
+
+		// 같은 크기의 WebP: 인코더를 쓸 수 없는 빌드에서는 건너뛴다
+		if data, ok := encodeWebP(resized); ok {
+			webpImage := img
+			webpImage.Extension, webpImage.ContentType, webpImage.Data = ".webp", "image/webp", data
+			images = append(images, webpImage)
+		}
+	}
+	return
+}
+
+func resize(src image.Image, spec ThumbnailSpec) image.Image {
+	bounds := src.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	// 잘라낼 영역: 목표 비율에 맞춰 가운데를 남긴다
+	crop := bounds
+	width, height := spec.Width, spec.Height
+	if height == 0 {
+		// 원본보다 크게 늘리지 않는다
+		if w < width {
+			width = w
+		}
+		height = h * width / w
+	} else if w*height > h*width {
+		cw := h * width / height
+		crop = image.Rect(bounds.Min.X+(w-cw)/2, bounds.Min.Y, bounds.Min.X+(w-cw)/2+cw, bounds.Max.Y)
+	} else {
+		ch := w * height / width
+		crop = image.Rect(bounds.Min.X, bounds.Min.Y+(h-ch)/2, bounds.Max.X, bounds.Min.Y+(h-ch)/2+ch)
+	}
+
+	// 잘라낸 영역보다 크게 늘리지 않는다
+	if crop.Dx() < width {
+		height = height * crop.Dx() / width
+		width = crop.Dx()
+	}
+	if width < 1 {
+		width = 1
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, crop, draw.Src, nil)
+	return dst
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return false
+}
+
+func orient(img image.Image, orientation int) image.Image {
+	// EXIF 방향 값(1~8)에 맞춰 픽셀을 옮긴다
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if orientation >= 5 {
+		w, h = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < b.Dy(); y++ {
+		for x := 0; x < b.Dx(); x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = w-1-y, x
+			case 7:
+				dx, dy = w-1-y, h-1-x
+			case 8:
+				dx, dy = y, h-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+func jpegOrientation(data []byte) int {
+	// JPEG 의 APP1(Exif) 세그먼트에서 방향(0x0112) 태그를 읽는다: 없으면 1
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || length < 2 || i+2+length > len(data) {
+			// 이미지 데이터가 시작되면 더 이상 메타데이터가 없다
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	// 첫 번째 IFD 의 항목을 순회
+	offset := int(order.Uint32(tiff[4:]))
+	if offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+	return 1
+}
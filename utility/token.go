@@ -3,58 +3,58 @@ package utility
 import (
 	// Default package
 	"time"
+	"strings"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
 	// Third-party package
-	"github.com/labstack/echo"
 	"github.com/dgrijalva/jwt-go"
 	// User package
+	"github.com/backend/auth"
 	"github.com/backend/model"
 
 )
 
+// 토큰 유효시간: 서버 시작 시 설정 값으로 바꾼다
+var TokenExpiry = 72 * time.Hour
+
 func CreateJWT(u *model.User) *jwt.Token {
 	// Create token
 	// HS256 알고리즘으로 인코딩
 	// 단방향 암호화 알고리즘인 RS256과 달리 양방향 암호화 알고리즘이므로 디코딩이 가능함
-	token := jwt.New(jwt.SigningMethodHS256)
-
-	// Set claims
-	// 유저 정보를 담는다
-	claims := token.Claims.(jwt.MapClaims)
-	claims["id"] = u.ID
-	claims["email"] = u.Email
-	claims["nickname"] = u.Nickname
-	claims["isActive"] = u.IsActive
-	claims["isStaff"] = u.IsStaff
-	claims["isAdmin"] = u.IsAdmin
-	claims["exp"] = time.Now().Add(time.Hour * 72).Unix() // 토큰 유효시간: 72시간
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &auth.Claims{
+		// Set claims
+		// 유저 정보를 담는다: 권한은 요청마다 데이터베이스에서 다시 확인하므로 표시용이다
+		ID:       u.ID.Hex(),
+		Email:    u.Email,
+		Nickname: u.Nickname,
+		IsActive: u.IsActive,
+		IsStaff:  u.IsStaff,
+		IsAdmin:  u.IsAdmin,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(TokenExpiry).Unix(), // 토큰 유효시간
+		},
+	})
 
 	return token
 }
 
-func UserIDFromToken(c echo.Context) string {
-	// 다른 메서드 안에서 JWT 를 통해 DB 상의 ID 를 꺼내오는 헬퍼 함수
-	user := c.Get("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	return claims["id"].(string)
-}
-
-func UserEmailFromToken(c echo.Context) string {
-	// JWT 를 통해 이메일을 체크하는 헬퍼 함수
-	user := c.Get("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	return claims["email"].(string)
-}
-
-func UserNicknameFromToken(c echo.Context) string {
-	// JWT 를 통해 닉네임을 체크하는 헬퍼 함수
-	user := c.Get("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	return claims["nickname"].(string)
+func SignValue(key string, value string) string {
+	// 메일 수신 거부 링크처럼 로그인 없이 쓰는 값에 서명을 붙인다
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(value))
+	return value + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
 }
 
-func IsAdminFromToken(c echo.Context) bool {
-	// JWT 를 통해 관리자 여부를 체크하는 헬퍼 함수
-	user := c.Get("user").(*jwt.Token)
-	claims := user.Claims.(jwt.MapClaims)
-	return claims["isAdmin"].(bool)
+func VerifySignedValue(key string, token string) (string, bool) {
+	// SignValue 로 만든 토큰의 서명을 검증하고 원래 값을 꺼낸다
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return "", false
+	}
+	value := token[:i]
+	if !hmac.Equal([]byte(SignValue(key, value)), []byte(token)) {
+		return "", false
+	}
+	return value, true
 }
@@ -18,11 +18,3 @@ func EmptyValueValidation(c echo.Context) (err error) {
 	}
 	return
 }
-
-func AdminValidation(c echo.Context) (err error) {
-
-	if IsAdminFromToken(c) == false {
-		return echo.ErrUnauthorized
-	}
-	return
-}
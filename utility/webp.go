@@ -0,0 +1,20 @@
+//go:build cgo
+// +build cgo
+
+package utility
+
+import (
+	// Default package
+	"image"
+	// Third Party package
+	"github.com/chai2010/webp"
+)
+
+func encodeWebP(img image.Image) ([]byte, bool) {
+	// libwebp 로 손실 압축: cgo 가 필요하다
+	data, err := webp.EncodeRGBA(img, ThumbnailQuality)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
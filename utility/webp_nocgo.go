@@ -0,0 +1,11 @@
+//go:build !cgo
+// +build !cgo
+
+package utility
+
+import "image"
+
+func encodeWebP(img image.Image) ([]byte, bool) {
+	// cgo 없이 빌드하면 WebP 썸네일은 만들지 않는다
+	return nil, false
+}